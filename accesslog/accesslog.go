@@ -0,0 +1,245 @@
+// Package accesslog is an asynchronous HTTP access logger built on
+// ringbuffer.
+//
+// The middleware only copies a Record into a ring; a background consumer
+// writes records in Combined Log Format, so slow log output doesn't add
+// request latency until the ring fills up.
+package accesslog
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"ringbuffer"
+)
+
+// Record is one logged request.
+type Record struct {
+	Time       time.Time // request start
+	RemoteAddr string
+	Method     string
+	URI        string
+	Proto      string
+	Status     int
+	Size       int64 // response body bytes
+	Duration   time.Duration
+	Referer    string
+	UserAgent  string
+}
+
+// Logger writes Records to an io.Writer from a background goroutine.
+type Logger struct {
+	rb      *ringbuffer.RingBuffer
+	entries []entry
+	w       *bufio.Writer
+	done    chan struct{}
+	err     error // first write error, read after done
+
+	mu     sync.RWMutex // orders Log against Close
+	closed bool
+}
+
+type entry struct {
+	rec  Record
+	stop bool
+}
+
+// New starts a Logger buffering up to size records before Log waits.
+// It fails where ringbuffer.NewRingBuffer does.
+func New(w io.Writer, size int) (*Logger, error) {
+	rb, err := ringbuffer.NewRingBuffer(size, ringbuffer.WithName("accesslog"))
+	if err != nil {
+		return nil, err
+	}
+	l := &Logger{
+		rb:   rb,
+		w:    bufio.NewWriter(w),
+		done: make(chan struct{}),
+	}
+	l.entries = make([]entry, l.rb.Slots())
+	l.rb.Go("consumer", 0, l.consume)
+	return l, nil
+}
+
+// MustNew is New panicking on error.
+func MustNew(w io.Writer, size int) *Logger {
+	l, err := New(w, size)
+	if err != nil {
+		panic(err)
+	}
+	return l
+}
+
+// Middleware logs every request served by next.
+func (l *Logger) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rw, r)
+		l.Log(Record{
+			Time:       start,
+			RemoteAddr: r.RemoteAddr,
+			Method:     r.Method,
+			URI:        r.RequestURI,
+			Proto:      r.Proto,
+			Status:     rw.status,
+			Size:       rw.size,
+			Duration:   time.Since(start),
+			Referer:    r.Referer(),
+			UserAgent:  r.UserAgent(),
+		})
+	})
+}
+
+// Log queues rec for writing. Records logged after Close are dropped.
+// It is goroutine-safe.
+func (l *Logger) Log(rec Record) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if l.closed {
+		return
+	}
+	l.push(entry{rec: rec})
+}
+
+func (l *Logger) push(e entry) {
+	id, _ := l.rb.ReserveWrite(-1) //the ring of a Logger is never closed
+	l.entries[l.rb.BufferIndex(id)] = e
+	l.rb.CommitWrite(-1, id)
+}
+
+// Close writes every queued record and stops the Logger. It returns the
+// first error writing or flushing records, after which the records that
+// followed were dropped.
+// It is goroutine-safe and may be called more than once.
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	if !l.closed {
+		l.closed = true
+		l.push(entry{stop: true})
+	}
+	l.mu.Unlock()
+	<-l.done
+	return l.err
+}
+
+func (l *Logger) consume() {
+	defer close(l.done)
+	buf := make([]byte, 0, 256)
+	for {
+		id, ok := l.rb.TryReserveRead(0)
+		if !ok {
+			l.flush() //caught up, don't hold lines in the buffer
+			id, _ = l.rb.ReserveRead(0)
+		}
+		idx := l.rb.BufferIndex(id)
+		e := l.entries[idx]
+		l.entries[idx] = entry{}
+		l.rb.CommitRead(0, id)
+		if e.stop {
+			l.flush()
+			return
+		}
+		buf = AppendCombined(buf[:0], &e.rec)
+		if _, err := l.w.Write(buf); err != nil && l.err == nil {
+			l.err = err
+		}
+	}
+}
+
+// flush flushes the buffered lines, keeping the first error.
+func (l *Logger) flush() {
+	if err := l.w.Flush(); err != nil && l.err == nil {
+		l.err = err
+	}
+}
+
+// AppendCommon appends rec in Common Log Format, followed by the request
+// duration and a newline, to dst.
+func AppendCommon(dst []byte, rec *Record) []byte {
+	dst = appendCommon(dst, rec)
+	return appendDuration(dst, rec)
+}
+
+// AppendCombined appends rec in Combined Log Format, Common Log Format
+// with the quoted referer and user agent, followed by the request duration
+// and a newline, to dst.
+func AppendCombined(dst []byte, rec *Record) []byte {
+	dst = appendCommon(dst, rec)
+	dst = append(dst, " \""...)
+	dst = appendOrDash(dst, rec.Referer)
+	dst = append(dst, "\" \""...)
+	dst = appendOrDash(dst, rec.UserAgent)
+	dst = append(dst, '"')
+	return appendDuration(dst, rec)
+}
+
+func appendCommon(dst []byte, rec *Record) []byte {
+	host := rec.RemoteAddr
+	for i := len(host) - 1; i >= 0; i-- {
+		if host[i] == ':' {
+			host = host[:i]
+			break
+		}
+	}
+	dst = appendOrDash(dst, host)
+	dst = append(dst, " - - ["...)
+	dst = rec.Time.AppendFormat(dst, "02/Jan/2006:15:04:05 -0700")
+	dst = append(dst, "] \""...)
+	dst = append(dst, rec.Method...)
+	dst = append(dst, ' ')
+	dst = append(dst, rec.URI...)
+	dst = append(dst, ' ')
+	dst = append(dst, rec.Proto...)
+	dst = append(dst, "\" "...)
+	dst = strconv.AppendInt(dst, int64(rec.Status), 10)
+	dst = append(dst, ' ')
+	return strconv.AppendInt(dst, rec.Size, 10)
+}
+
+func appendDuration(dst []byte, rec *Record) []byte {
+	dst = append(dst, ' ')
+	dst = append(dst, rec.Duration.String()...)
+	return append(dst, '\n')
+}
+
+func appendOrDash(dst []byte, s string) []byte {
+	if s == "" {
+		return append(dst, '-')
+	}
+	return append(dst, s...)
+}
+
+// responseWriter records status and size of a response.
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	size        int64
+	wroteHeader bool
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher when the wrapped writer does.
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,132 @@
+package accesslog
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// parallel sets GOMAXPROCS high enough for a RingBuffer and returns a func
+// restoring it.
+func parallel() func() {
+	old := runtime.GOMAXPROCS(4)
+	return func() { runtime.GOMAXPROCS(old) }
+}
+
+// syncBuffer is a bytes.Buffer safe to read while the Logger writes it.
+type syncBuffer struct {
+	mu sync.Mutex
+	b  bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.b.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.b.String()
+}
+
+func TestMiddleware(t *testing.T) {
+	defer parallel()()
+	var out syncBuffer
+	l := MustNew(&out, 4)
+	h := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte("short and stout"))
+	}))
+	r := httptest.NewRequest("GET", "/pot?q=1", nil)
+	r.RemoteAddr = "192.0.2.1:1234"
+	r.Header.Set("User-Agent", "curl/8.0")
+	r.Header.Set("Referer", "http://example.com/")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	//the last line is flushed once the consumer caught up, without Close
+	deadline := time.Now().Add(time.Second)
+	for out.String() == "" && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	line := out.String()
+	for _, want := range []string{`192.0.2.1 - - [`, `] "GET /pot?q=1 HTTP/1.1" 418 15 "http://example.com/" "curl/8.0" `} {
+		if !strings.Contains(line, want) {
+			t.Fatalf("line %q lacks %q", line, want)
+		}
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCloseWritesEverything(t *testing.T) {
+	defer parallel()()
+	const loggers, n = 4, 500
+	var out syncBuffer
+	l := MustNew(&out, 8)
+	var wg sync.WaitGroup
+	wg.Add(loggers)
+	for i := 0; i < loggers; i++ {
+		go func() {
+			defer wg.Done()
+			for j := 0; j < n; j++ {
+				l.Log(Record{Method: "GET", URI: "/", Status: 200})
+			}
+		}()
+	}
+	wg.Wait()
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(out.String(), "\n"); got != loggers*n {
+		t.Fatalf("%d lines written, want %d", got, loggers*n)
+	}
+	l.Log(Record{}) //dropped after Close
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type failWriter struct{}
+
+var errDisk = errors.New("disk full")
+
+func (failWriter) Write(p []byte) (int, error) { return 0, errDisk }
+
+func TestCloseReportsWriteError(t *testing.T) {
+	defer parallel()()
+	l := MustNew(failWriter{}, 4)
+	l.Log(Record{Method: "GET"})
+	if err := l.Close(); !errors.Is(err, errDisk) {
+		t.Fatalf("Close() = %v, want the write error", err)
+	}
+}
+
+func TestAppendCommon(t *testing.T) {
+	rec := Record{
+		Time:       time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		RemoteAddr: "[2001:db8::1]:80",
+		Method:     "POST",
+		URI:        "/a",
+		Proto:      "HTTP/2.0",
+		Status:     201,
+		Size:       3,
+		Duration:   time.Millisecond,
+	}
+	want := `[2001:db8::1] - - [01/May/2024:10:00:00 +0000] "POST /a HTTP/2.0" 201 3 1ms` + "\n"
+	if got := string(AppendCommon(nil, &rec)); got != want {
+		t.Fatalf("AppendCommon() = %q, want %q", got, want)
+	}
+	want = `[2001:db8::1] - - [01/May/2024:10:00:00 +0000] "POST /a HTTP/2.0" 201 3 "-" "-" 1ms` + "\n"
+	if got := string(AppendCombined(nil, &rec)); got != want {
+		t.Fatalf("AppendCombined() = %q, want %q", got, want)
+	}
+}
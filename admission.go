@@ -0,0 +1,68 @@
+package ringbuffer
+
+// AdmissionPolicy decides whether a publish of some priority goes ahead
+// on a loaded ring, so overload degrades gracefully, e.g. by shedding low
+// priority events first, instead of blocking every producer alike.
+type AdmissionPolicy interface {
+	// Admit reports whether to publish an item of priority, given the ring
+	// occupancy in [0, 1].
+	Admit(priority int, occupancy float64) bool
+}
+
+// ShedLevel drops items below MinPriority from Occupancy up.
+type ShedLevel struct {
+	Occupancy   float64
+	MinPriority int
+}
+
+// PriorityShedding is an AdmissionPolicy of shed levels; the highest level
+// reached by the occupancy applies.
+type PriorityShedding []ShedLevel
+
+// Admit implements AdmissionPolicy.
+func (p PriorityShedding) Admit(priority int, occupancy float64) bool {
+	min, top := 0, -1.0
+	for _, l := range p {
+		if occupancy >= l.Occupancy && l.Occupancy > top {
+			min, top = l.MinPriority, l.Occupancy
+		}
+	}
+	return priority >= min
+}
+
+// WithAdmissionPolicy consults p on Admit once the ring occupancy reaches
+// threshold; below it every publish is admitted without calling p.
+func WithAdmissionPolicy(threshold float64, p AdmissionPolicy) Option {
+	return func(rb *RingBuffer) {
+		rb.admission, rb.admitAbove = p, threshold
+	}
+}
+
+// Admit reports whether a publish of priority should go ahead, as decided
+// by the WithAdmissionPolicy policy. Refusals are counted in Stats.Shed.
+// It is goroutine-safe.
+func (rb *RingBuffer) Admit(priority int) bool {
+	if rb.admission == nil {
+		return true
+	}
+	occupancy := float64(rb.ApproxLen()) / float64(rb.size)
+	if occupancy < rb.admitAbove || rb.admission.Admit(priority, occupancy) {
+		return true
+	}
+	rb.shed.Add(1)
+	return false
+}
+
+// PutPriority publishes v like Put if the ring admits priority, see
+// WithAdmissionPolicy, and reports whether it did.
+// It returns ErrClosed once the ring is closed.
+// It is goroutine-safe.
+func (t *TypedRingBuffer[T]) PutPriority(v T, priority int) (bool, error) {
+	if !t.rb.Admit(priority) {
+		return false, nil
+	}
+	if err := t.Put(v); err != nil {
+		return false, err
+	}
+	return true, nil
+}
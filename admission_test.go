@@ -0,0 +1,49 @@
+package ringbuffer
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPriorityShedding(t *testing.T) {
+	p := PriorityShedding{{Occupancy: 0.5, MinPriority: 1}, {Occupancy: 0.9, MinPriority: 2}}
+	for _, c := range []struct {
+		priority  int
+		occupancy float64
+		want      bool
+	}{
+		{0, 0.4, true},
+		{0, 0.5, false},
+		{1, 0.5, true},
+		{1, 0.9, false},
+		{2, 1, true},
+	} {
+		if got := p.Admit(c.priority, c.occupancy); got != c.want {
+			t.Fatalf("Admit(%d, %v) = %v, want %v", c.priority, c.occupancy, got, c.want)
+		}
+	}
+}
+
+func TestWithAdmissionPolicy(t *testing.T) {
+	defer parallel()()
+	ring := MustNewTypedRingBuffer[int](4, WithAdmissionPolicy(0.5,
+		PriorityShedding{{Occupancy: 0.5, MinPriority: 1}}))
+	for i := 0; i < 2; i++ {
+		if ok, err := ring.PutPriority(i, 0); !ok || err != nil {
+			t.Fatalf("low priority put %d below the threshold: %v, %v", i, ok, err)
+		}
+	}
+	if ok, _ := ring.PutPriority(2, 0); ok {
+		t.Fatal("low priority put admitted at half occupancy")
+	}
+	if ok, err := ring.PutPriority(3, 1); !ok || err != nil {
+		t.Fatalf("high priority put: %v, %v", ok, err)
+	}
+	if got := ring.RingBuffer().Stats().Shed; got != 1 {
+		t.Fatalf("%d shed, want 1", got)
+	}
+	ring.RingBuffer().Close()
+	if _, err := ring.PutPriority(4, 1); !errors.Is(err, ErrClosed) {
+		t.Fatalf("PutPriority after Close: %v, want ErrClosed", err)
+	}
+}
@@ -0,0 +1,120 @@
+package ringbuffer
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Aggregator collects the rings of a process to report the worst of them
+// on demand: deepest, most contended and most lagged, so the problem ring
+// is found quickly among many. It is an http.Handler serving the report as
+// text, e.g. under /debug/rings?n=5.
+// It is goroutine-safe.
+type Aggregator struct {
+	mu    sync.Mutex
+	rings []*RingBuffer
+}
+
+// Add adds rings to the report. Name them, see WithName, to tell them
+// apart.
+func (a *Aggregator) Add(rings ...*RingBuffer) {
+	a.mu.Lock()
+	a.rings = append(a.rings, rings...)
+	a.mu.Unlock()
+}
+
+// Remove removes rb from the report.
+func (a *Aggregator) Remove(rb *RingBuffer) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for i, r := range a.rings {
+		if r == rb {
+			a.rings = append(a.rings[:i], a.rings[i+1:]...)
+			return
+		}
+	}
+}
+
+// RingReport is the Stats of a ring with the figures it is ranked by.
+type RingReport struct {
+	Stats
+	Depth    float64       // Occupancy over Size
+	WaitTime time.Duration // waited in every phase so far
+	Lag      uint64        // write reservations ahead of the read commit
+}
+
+// TopReport lists the worst rings of an Aggregator, worst first.
+type TopReport struct {
+	Deepest       []RingReport // by Depth
+	MostContended []RingReport // by WaitTime
+	MostLagged    []RingReport // by Lag
+}
+
+// Report snapshots the rings and returns the n worst of each kind.
+func (a *Aggregator) Report(n int) TopReport {
+	a.mu.Lock()
+	rings := append([]*RingBuffer(nil), a.rings...)
+	a.mu.Unlock()
+	all := make([]RingReport, len(rings))
+	for i, rb := range rings {
+		s := rb.Stats()
+		all[i] = RingReport{
+			Stats:    s,
+			Depth:    float64(s.Occupancy) / float64(s.Size),
+			WaitTime: s.ReserveWriteWaits.Time + s.ReserveReadWaits.Time + s.CommitReadWaits.Time,
+			Lag:      s.OutstandingWrites,
+		}
+	}
+	top := func(less func(x, y RingReport) bool) []RingReport {
+		sorted := append([]RingReport(nil), all...)
+		sort.SliceStable(sorted, func(i, j int) bool { return less(sorted[j], sorted[i]) })
+		if len(sorted) > n {
+			sorted = sorted[:n]
+		}
+		return sorted
+	}
+	return TopReport{
+		Deepest:       top(func(x, y RingReport) bool { return x.Depth < y.Depth }),
+		MostContended: top(func(x, y RingReport) bool { return x.WaitTime < y.WaitTime }),
+		MostLagged:    top(func(x, y RingReport) bool { return x.Lag < y.Lag }),
+	}
+}
+
+// String formats the report as text, a table per kind.
+func (r TopReport) String() string {
+	var b strings.Builder
+	section := func(title string, rings []RingReport) {
+		fmt.Fprintf(&b, "%s:\n", title)
+		for _, rr := range rings {
+			fmt.Fprintf(&b, "  %-24s size=%d depth=%.0f%% waits=%s lag=%d\n",
+				ringName(rr.Stats), rr.Size, rr.Depth*100, rr.WaitTime, rr.Lag)
+		}
+	}
+	section("deepest", r.Deepest)
+	section("most contended", r.MostContended)
+	section("most lagged", r.MostLagged)
+	return b.String()
+}
+
+func ringName(s Stats) string {
+	if s.Name == "" {
+		return "(unnamed)"
+	}
+	return s.Name
+}
+
+// ServeHTTP writes the report of the n worst rings, n from the query
+// parameter n, 5 by default.
+func (a *Aggregator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n := 5
+	if v, err := strconv.Atoi(r.URL.Query().Get("n")); err == nil && v > 0 {
+		n = v
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprint(w, a.Report(n).String())
+}
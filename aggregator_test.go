@@ -0,0 +1,48 @@
+package ringbuffer
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAggregator(t *testing.T) {
+	defer parallel()()
+	var a Aggregator
+	rings := map[string]*RingBuffer{}
+	for name, n := range map[string]int{"idle": 0, "half": 4, "full": 8} {
+		rb := MustNewRingBuffer(8, WithName(name))
+		for i := 0; i < n; i++ {
+			id, _ := rb.ReserveWrite(0)
+			rb.CommitWrite(0, id)
+		}
+		rings[name] = rb
+		a.Add(rb)
+	}
+	r := a.Report(2)
+	if len(r.Deepest) != 2 || r.Deepest[0].Name != "full" || r.Deepest[1].Name != "half" {
+		t.Fatalf("deepest %v", names(r.Deepest))
+	}
+	if r.Deepest[0].Depth != 1 || r.Deepest[1].Depth != 0.5 {
+		t.Fatalf("depths %v, %v", r.Deepest[0].Depth, r.Deepest[1].Depth)
+	}
+	if len(r.MostLagged) != 2 || r.MostLagged[0].Name != "full" || r.MostLagged[0].Lag != 8 {
+		t.Fatalf("most lagged %v", names(r.MostLagged))
+	}
+
+	a.Remove(rings["full"])
+	rec := httptest.NewRecorder()
+	a.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/rings?n=1", nil))
+	body := rec.Body.String()
+	if !strings.Contains(body, "deepest:\n  half ") || strings.Contains(body, "full") {
+		t.Fatalf("report of the worst ring after Remove:\n%s", body)
+	}
+}
+
+func names(rs []RingReport) []string {
+	var out []string
+	for _, r := range rs {
+		out = append(out, r.Name)
+	}
+	return out
+}
@@ -0,0 +1,90 @@
+package ringbuffer
+
+import (
+	"fmt"
+	"unsafe"
+
+	"ringbuffer/internal/prefetch"
+)
+
+// WithSlotAlignment makes a TypedRingBuffer start every group of group
+// adjacent slots on a cache line of its own, so producers and consumers on
+// neighboring slots of different groups don't false-share. group 1 gives
+// each slot its own lines; larger groups trade some sharing for less
+// padding. Padding is made of whole items, so items whose size doesn't
+// divide a cache line pad the most; it is reported by MemoryFootprint.
+// It has no effect on the id based RingBuffer, whose slots the caller owns.
+func WithSlotAlignment(group int) Option {
+	return func(rb *RingBuffer) {
+		rb.slotGroup = group
+	}
+}
+
+// slotLayout maps the slot indexes of a TypedRingBuffer to its backing
+// array under WithSlotAlignment. The zero slotLayout is the dense one.
+type slotLayout struct {
+	group  int // slots per group, 0 for dense
+	stride int // elements from a group to the next, a whole number of cache lines
+	base   int // element of the first group, on a cache line
+}
+
+// newSlotLayout returns the layout of n slots of size elemSize in groups of
+// group, and the length of the backing array it needs.
+func newSlotLayout(group, n int, elemSize uintptr) (slotLayout, int) {
+	if group <= 0 || elemSize == 0 {
+		return slotLayout{}, n
+	}
+	if group > n {
+		group = n
+	}
+	unit := prefetch.CacheLine / gcd(int(elemSize%prefetch.CacheLine), prefetch.CacheLine) //fewest elements filling whole lines
+	stride := (group + unit - 1) / unit * unit
+	groups := (n + group - 1) / group
+	return slotLayout{group: group, stride: stride}, groups*stride + unit
+}
+
+// align sets the base of l for the backing array starting at p, the first
+// element on a cache line. If none is, as the allocation is less aligned
+// than elemSize allows, it leaves the base at 0.
+func (l *slotLayout) align(p unsafe.Pointer, elemSize uintptr) {
+	if l.group == 0 {
+		return
+	}
+	for i := 0; i < prefetch.CacheLine; i++ {
+		if (uintptr(p)+uintptr(i)*elemSize)%prefetch.CacheLine == 0 {
+			l.base = i
+			return
+		}
+	}
+}
+
+// index returns the backing array element of slot index i.
+func (l *slotLayout) index(i int) int {
+	if l.group == 0 {
+		return i
+	}
+	return l.base + i/l.group*l.stride + i%l.group
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// checkSlotAlignment validates WithSlotAlignment.
+func (rb *RingBuffer) checkSlotAlignment() error {
+	if rb.slotGroup < 0 {
+		return fmt.Errorf("RingBuffer: WithSlotAlignment group %d < 0", rb.slotGroup)
+	}
+	return nil
+}
+
+// MemoryFootprint returns the bytes of the slot array of t, and how many of
+// them are padding added by WithSlotAlignment.
+func (t *TypedRingBuffer[T]) MemoryFootprint() (bytes, padding int) {
+	size := int(unsafe.Sizeof(*new(T)))
+	bytes = len(t.slots) * size
+	return bytes, bytes - t.rb.Slots()*size
+}
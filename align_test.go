@@ -0,0 +1,44 @@
+package ringbuffer
+
+import (
+	"testing"
+	"unsafe"
+
+	"ringbuffer/internal/prefetch"
+)
+
+func TestSlotAlignment(t *testing.T) {
+	defer parallel()()
+	dense := MustNewTypedRingBuffer[int64](8)
+	if bytes, padding := dense.MemoryFootprint(); bytes != 8*8 || padding != 0 {
+		t.Fatalf("dense footprint %d bytes, %d padding", bytes, padding)
+	}
+
+	for _, group := range []int{1, 2} {
+		r := MustNewTypedRingBuffer[int64](8, WithSlotAlignment(group))
+		n := r.RingBuffer().Slots()
+		for i := 0; i < n; i++ {
+			p := uintptr(unsafe.Pointer(r.Slot(uint64(i))))
+			if i%group == 0 && p%prefetch.CacheLine != 0 {
+				t.Fatalf("group %d: slot %d starts a group off a cache line", group, i)
+			}
+			if i%group != 0 && p != uintptr(unsafe.Pointer(r.Slot(uint64(i-1))))+8 {
+				t.Fatalf("group %d: slot %d not next to slot %d", group, i, i-1)
+			}
+		}
+		bytes, padding := r.MemoryFootprint()
+		if padding <= 0 || bytes-padding != n*8 {
+			t.Fatalf("group %d: footprint %d bytes, %d padding", group, bytes, padding)
+		}
+		for i := int64(0); i < int64(2*n); i++ {
+			r.Put(i)
+			if v, _ := r.Get(); v != i {
+				t.Fatalf("group %d: got %d, want %d", group, v, i)
+			}
+		}
+	}
+
+	if _, err := NewTypedRingBuffer[int64](8, WithSlotAlignment(-1)); err == nil {
+		t.Fatal("negative group accepted")
+	}
+}
@@ -0,0 +1,99 @@
+package ringbuffer
+
+import (
+	"sync/atomic"
+)
+
+// AuditReport is the result of auditing one window of read ids.
+type AuditReport struct {
+	Start, End uint64   // window [Start, End)
+	Gaps       []uint64 // ids never handed to a reader
+	Duplicates []uint64 // ids handed to more than one reader
+	Unaudited  uint64   // reads too far ahead to be tracked, since last report
+}
+
+// WithAudit records which read ids are handed to readers in a bitmap per
+// window of window ids, and calls report when the read commit passes the
+// end of a window, with any gap or duplicate found.
+// It is a cheap correctness monitor that can stay on in production: one
+// atomic bit set per read.
+// report runs on the committing reader goroutine and must not block.
+func WithAudit(window uint64, report func(AuditReport)) Option {
+	return func(rb *RingBuffer) {
+		if window == 0 {
+			return
+		}
+		words := (window + 63) / 64
+		a := &audit{window: window, report: report}
+		for i := range a.bits {
+			a.bits[i] = make([]atomic.Uint64, words)
+			a.dups[i] = make([]atomic.Uint64, words)
+		}
+		rb.audit = a
+	}
+}
+
+// audit tracks the current window and the next one.
+type audit struct {
+	window    uint64
+	report    func(AuditReport)
+	base      atomic.Uint64 // start of the oldest open window
+	bits      [2][]atomic.Uint64
+	dups      [2][]atomic.Uint64
+	unaudited atomic.Uint64
+}
+
+func setBit(words []atomic.Uint64, i uint64) (was bool) {
+	w, m := &words[i/64], uint64(1)<<(i%64)
+	for {
+		old := w.Load()
+		if old&m != 0 {
+			return true
+		}
+		if w.CompareAndSwap(old, old|m) {
+			return false
+		}
+	}
+}
+
+// reserved marks id as handed to a reader.
+func (a *audit) reserved(id uint64) {
+	base := a.base.Load()
+	if id < base || id >= base+2*a.window {
+		a.unaudited.Add(1)
+		return
+	}
+	k, i := (id/a.window)%2, id%a.window
+	if setBit(a.bits[k], i) {
+		setBit(a.dups[k], i)
+	}
+}
+
+// committed closes the oldest window once the read commit reaches its end.
+// Read commits are sequential, so only one goroutine closes a window.
+func (a *audit) committed(newId uint64) {
+	base := a.base.Load()
+	if newId != base+a.window {
+		return
+	}
+	k := (base / a.window) % 2
+	r := AuditReport{Start: base, End: newId, Unaudited: a.unaudited.Swap(0)}
+	for i := uint64(0); i < a.window; i++ {
+		m := uint64(1) << (i % 64)
+		if a.bits[k][i/64].Load()&m == 0 {
+			r.Gaps = append(r.Gaps, base+i)
+		}
+		if a.dups[k][i/64].Load()&m != 0 {
+			r.Duplicates = append(r.Duplicates, base+i)
+		}
+	}
+	//clear before moving on, this bitmap becomes the next-next window
+	for i := range a.bits[k] {
+		a.bits[k][i].Store(0)
+		a.dups[k][i].Store(0)
+	}
+	a.base.Store(newId)
+	if a.report != nil {
+		a.report(r)
+	}
+}
@@ -0,0 +1,49 @@
+package ringbuffer
+
+import "testing"
+
+func TestAudit(t *testing.T) {
+	defer parallel()()
+	var reports []AuditReport
+	rb := MustNewRingBuffer(4, WithAudit(4, func(r AuditReport) {
+		reports = append(reports, r)
+	}))
+	for i := 0; i < 8; i++ {
+		id, _ := rb.ReserveWrite(0)
+		rb.CommitWrite(0, id)
+		r, err := rb.ReserveRead(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i == 5 {
+			rb.audit.reserved(r) //a reader handed id 5 again
+		}
+		rb.CommitRead(1, r)
+		if want := (i + 1) / 4; len(reports) != want {
+			t.Fatalf("%d reports after %d reads, want %d", len(reports), i+1, want)
+		}
+	}
+	if r := reports[0]; r.Start != 0 || r.End != 4 || len(r.Gaps) != 0 || len(r.Duplicates) != 0 {
+		t.Fatalf("first window %+v, want a clean [0, 4)", r)
+	}
+	if r := reports[1]; r.Start != 4 || r.End != 8 || len(r.Duplicates) != 1 || r.Duplicates[0] != 5 {
+		t.Fatalf("second window %+v, want duplicate 5", r)
+	}
+}
+
+// TestAuditGap checks an id no reader was handed is reported.
+func TestAuditGap(t *testing.T) {
+	defer parallel()()
+	var got AuditReport
+	rb := MustNewRingBuffer(4, WithAudit(2, func(r AuditReport) { got = r }))
+	rb.audit.reserved(0) //id 1 is committed below without a reservation
+	rb.audit.committed(2)
+	if got.End != 2 || len(got.Gaps) != 1 || got.Gaps[0] != 1 {
+		t.Fatalf("%+v, want gap 1", got)
+	}
+	rb.audit.reserved(100)
+	rb.audit.committed(4)
+	if got.Start != 2 || got.Unaudited != 1 || len(got.Gaps) != 2 {
+		t.Fatalf("%+v, want gaps 2, 3 and an unaudited read", got)
+	}
+}
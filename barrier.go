@@ -0,0 +1,67 @@
+package ringbuffer
+
+import "context"
+
+// WithBarrierHandler calls fn from the reader that crosses a barrier
+// published by Barrier, before the barrier is committed. Barriers carry no
+// payload and are never returned by ReserveRead.
+func WithBarrierHandler(fn func(wid int, id uint64)) Option {
+	return func(rb *RingBuffer) {
+		rb.onBarrier = fn
+	}
+}
+
+// Barrier publishes a checkpoint barrier and returns its id.
+// Readers skip it, calling the barrier handler on the way; WaitBarrier
+// tells when every reader has passed it. Use it for config reloads or
+// epoch based reclamation.
+// It will wait if ringbuffer is full, and returns ErrClosed once the ring
+// is closed.
+// It is goroutine-safe.
+func (rb *RingBuffer) Barrier(wid int) (uint64, error) {
+	id, err := rb.ReserveWrite(wid)
+	if err != nil {
+		return 0, err
+	}
+	rb.mark(id, markBarrier)
+	rb.CommitWrite(wid, id)
+	return id, nil
+}
+
+// PassedBarrier reports whether every reader is past barrier id, i.e. every
+// item published before it has been consumed and committed.
+// It is goroutine-safe.
+func (rb *RingBuffer) PassedBarrier(id uint64) bool {
+	return rb.readCommit() > id
+}
+
+// WaitBarrier waits until every reader is past barrier id. It returns
+// ErrClosed if readers are closed before they are, i.e. the ring is closed
+// without WithDrainOnClose; drain readers still reach the barrier.
+// It is goroutine-safe.
+func (rb *RingBuffer) WaitBarrier(id uint64) error {
+	return rb.WaitBarrierContext(context.Background(), id)
+}
+
+// WaitBarrierContext waits like WaitBarrier, but gives up and returns
+// ctx.Err() once ctx is done.
+// It is goroutine-safe.
+func (rb *RingBuffer) WaitBarrierContext(ctx context.Context, id uint64) error {
+	if rb.PassedBarrier(id) {
+		return nil
+	}
+	defer rb.watch(ctx, rb.waitBarrier)()
+	ready := func() bool {
+		return rb.PassedBarrier(id) || rb.readersClosed() || ctx.Err() != nil
+	}
+	for !rb.PassedBarrier(id) {
+		if rb.readersClosed() {
+			return ErrClosed
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rb.waitBarrier.wait(id+1, ready)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package ringbuffer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWaitBarrier(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(4)
+	id, _ := rb.ReserveWrite(0)
+	rb.CommitWrite(0, id)
+	b, _ := rb.Barrier(0)
+	done := make(chan error)
+	go func() { done <- rb.WaitBarrier(b) }()
+	r, _ := rb.ReserveRead(1)
+	rb.CommitRead(1, r)
+	select {
+	case <-done:
+		t.Fatal("WaitBarrier returned before the barrier was passed")
+	case <-time.After(20 * time.Millisecond):
+	}
+	next, _ := rb.ReserveWrite(0) //readers skip the barrier to reach it
+	rb.CommitWrite(0, next)
+	r, _ = rb.ReserveRead(1)
+	rb.CommitRead(1, r)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWaitBarrierClosed(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(4)
+	b, _ := rb.Barrier(0)
+	done := make(chan error)
+	go func() { done <- rb.WaitBarrier(b) }()
+	time.Sleep(10 * time.Millisecond)
+	rb.Close()
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrClosed) {
+			t.Fatalf("WaitBarrier after Close: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("WaitBarrier hangs after Close")
+	}
+}
+
+// TestWaitBarrierDrain checks a drained ring lets WaitBarrier return nil
+// once readers pass the barrier after Close.
+func TestWaitBarrierDrain(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(4, WithDrainOnClose())
+	id, _ := rb.ReserveWrite(0)
+	rb.CommitWrite(0, id)
+	b, _ := rb.Barrier(0)
+	done := make(chan error)
+	go func() { done <- rb.WaitBarrier(b) }()
+	rb.Close()
+	select {
+	case err := <-done:
+		t.Fatalf("WaitBarrier returned before the ring drained: %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	r, err := rb.ReserveRead(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rb.CommitRead(1, r)
+	if _, err := rb.ReserveRead(1); !errors.Is(err, ErrClosed) {
+		t.Fatalf("ReserveRead past the barrier: %v, want ErrClosed", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("WaitBarrier after drain: %v", err)
+	}
+}
+
+func TestBarrierHandler(t *testing.T) {
+	defer parallel()()
+	type call struct {
+		wid int
+		id  uint64
+	}
+	var calls []call
+	rb := MustNewRingBuffer(4, WithBarrierHandler(func(wid int, id uint64) {
+		calls = append(calls, call{wid, id})
+	}))
+	b, _ := rb.Barrier(0)
+	id, _ := rb.ReserveWrite(0)
+	rb.CommitWrite(0, id)
+	r, err := rb.ReserveRead(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r != id {
+		t.Fatalf("ReserveRead returned %d, want %d past barrier %d", r, id, b)
+	}
+	if len(calls) != 1 || calls[0] != (call{2, b}) {
+		t.Fatalf("handler calls %v, want [{2 %d}]", calls, b)
+	}
+	rb.CommitRead(2, r)
+	if !rb.PassedBarrier(b) {
+		t.Fatal("barrier not passed")
+	}
+}
+
+func TestWaitBarrierContext(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(4)
+	b, _ := rb.Barrier(0)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := rb.WaitBarrierContext(ctx, b); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("WaitBarrierContext: %v", err)
+	}
+}
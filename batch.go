@@ -0,0 +1,328 @@
+package ringbuffer
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ReserveWriteN reserves n contiguous write ids [lo, hi], for producers
+// that amortize the atomic operations and wakeups over many items. It waits
+// until the slots of all of them are free. n out of [1, Size] is a misuse,
+// see WithMisusePolicy. It returns ErrClosed once the ring is closed.
+// It is goroutine-safe.
+func (rb *RingBuffer) ReserveWriteN(wid int, n int) (lo, hi uint64, err error) {
+	if rb.instr != nil {
+		defer rb.instrumented(OpReserveWriteN, wid, time.Now(), &lo, &err)
+	}
+	if n < 1 || n > rb.size {
+		return 0, 0, rb.misuse(fmt.Errorf("%w %d", ErrBatchSize, n))
+	}
+	lo, err = rb.reserveWriteN(wid, n)
+	if err != nil {
+		return 0, 0, err
+	}
+	return lo, lo + uint64(n-1), nil
+}
+
+// CommitWriteN commits write ids [lo, hi] at once like CommitWrite.
+// It is goroutine-safe.
+func (rb *RingBuffer) CommitWriteN(wid int, lo, hi uint64) (err error) {
+	if rb.instr != nil {
+		defer rb.instrumented(OpCommitWriteN, wid, time.Now(), &lo, &err)
+	}
+	if rb.observer != nil {
+		defer rb.observeCommit(OpCommitWriteN, wid, lo, hi, &err)
+	}
+	if lo > hi {
+		return rb.misuse(fmt.Errorf("%w [%d, %d]", ErrBatchSize, lo, hi))
+	}
+	if err := rb.checkCommit(lo, &rb.wReserve, &rb.wCommit); err != nil {
+		return err
+	}
+	if err := rb.checkCommit(hi, &rb.wReserve, &rb.wCommit); err != nil {
+		return err
+	}
+	if rb.debug.Load() {
+		t := rb.trace(OpCommitWriteN, wid)
+		defer t.done(lo, 1)
+	}
+	if rb.instr != nil {
+		rb.stampPublish(lo, hi)
+	}
+	return rb.commitWriteN(wid, lo, hi)
+}
+
+// ReserveReadN reserves up to max contiguous published read ids [lo, hi],
+// waiting for at least one. Aborted writes and barriers are skipped, never
+// returned in a range. max below one is a misuse, see WithMisusePolicy.
+// It returns ErrClosed once the ring is closed, see WithDrainOnClose.
+// It is goroutine-safe.
+func (rb *RingBuffer) ReserveReadN(wid int, max int) (lo, hi uint64, err error) {
+	if rb.instr != nil {
+		defer rb.instrumented(OpReserveReadN, wid, time.Now(), &lo, &err)
+	}
+	if max < 1 {
+		return 0, 0, rb.misuse(fmt.Errorf("%w %d", ErrBatchSize, max))
+	}
+	if rb.contractChecks && rb.singleReader {
+		checkOwner(&rb.readerOwner, "reader")
+	}
+
+	var waitStart time.Time
+	try := 0
+	var t trace
+	if rb.debug.Load() {
+		t = rb.trace(OpReserveReadN, wid)
+		defer func() { t.done(lo, try) }()
+	}
+
+	for try = 1; ; try++ {
+		if rb.readersClosed() {
+			return 0, 0, ErrClosed
+		}
+		r := rb.rReserve.Load()
+		if rb.debug.Load() {
+			t.try(r, try)
+		}
+		if avail := rb.readLimit(); r < avail {
+			n := avail - r
+			if n > uint64(max) {
+				n = uint64(max)
+			}
+			if rb.tombs.count.Load() > 0 {
+				n = rb.tombs.before(r, r+n) - r
+			}
+			if n == 0 { //r is a marker, skip it alone
+				if rb.claimRead(r, 1) {
+					if rb.audit != nil {
+						rb.audit.reserved(r)
+					}
+					rb.skipTombstone(wid, r)
+				}
+				continue
+			}
+			if !rb.claimRead(r, n) {
+				continue
+			}
+			if rb.audit != nil {
+				for id := r; id < r+n; id++ {
+					rb.audit.reserved(id)
+				}
+			}
+			rb.waitEnd(OpReserveReadN, wid, waitStart)
+			return r, r + n - 1, nil
+		}
+		if rb.readClosed(r) {
+			return 0, 0, ErrClosed
+		}
+		if waitStart.IsZero() {
+			rb.readWaited(r)
+			rb.observeEmpty(wid)
+		}
+		waitStart = rb.waitBegin(waitStart)
+		if rb.readStrategy().Spin(context.Background(), try, rb.yield) {
+			continue
+		}
+		rb.waitReadR.wait(r+1, func() bool {
+			r := rb.rReserve.Load()
+			return rb.canRead(r) || rb.readClosed(r)
+		})
+		rb.observeWakeup(OpReserveReadN, wid)
+	}
+}
+
+// CommitReadN commits read ids [lo, hi] at once, waiting for every earlier
+// id like CommitRead.
+// It is goroutine-safe.
+func (rb *RingBuffer) CommitReadN(wid int, lo, hi uint64) (err error) {
+	if rb.instr != nil {
+		defer rb.instrumented(OpCommitReadN, wid, time.Now(), &lo, &err)
+	}
+	if rb.observer != nil {
+		defer rb.observeCommit(OpCommitReadN, wid, lo, hi, &err)
+	}
+	if lo > hi {
+		return rb.misuse(fmt.Errorf("%w [%d, %d]", ErrBatchSize, lo, hi))
+	}
+	if err := rb.checkCommit(lo, &rb.rReserve, &rb.rCommit); err != nil {
+		return err
+	}
+	if err := rb.checkCommit(hi, &rb.rReserve, &rb.rCommit); err != nil {
+		return err
+	}
+	if rb.relaxedRead {
+		for id := lo; id <= hi; id++ {
+			if err := rb.commitReadRelaxed(id); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	try := 0
+	var t trace
+	if rb.debug.Load() {
+		t = rb.trace(OpCommitReadN, wid)
+		defer func() { t.done(lo, try) }()
+	}
+	var waitStart time.Time
+	ready := func() bool { return rb.rCommit.Load() == lo }
+	for try = 1; !rb.tryCommitReadN(lo, hi); try++ {
+		if rb.debug.Load() {
+			t.try(lo, try)
+		}
+		waitStart = rb.waitBegin(waitStart)
+		if rb.strategy().Spin(context.Background(), try, rb.yield) {
+			continue
+		}
+		rb.waitReadC.wait(lo, ready)
+		rb.observeWakeup(OpCommitReadN, wid)
+	}
+	rb.waitEnd(OpCommitReadN, wid, waitStart)
+	return nil
+}
+
+// reserveWriteN reserves n contiguous write ids and returns the first one.
+// It waits until the slots of all of them are free, so n must not exceed
+// Size. It returns ErrClosed once the ring is closed.
+func (rb *RingBuffer) reserveWriteN(wid int, n int) (lo uint64, err error) {
+	if rb.contractChecks && rb.singleWriter {
+		checkOwner(&rb.writerOwner, "writer")
+	}
+	if rb.gate != nil {
+		<-rb.gate
+	}
+
+	var waitStart time.Time
+	try := 0
+	var t trace
+	if rb.debug.Load() {
+		t = rb.trace(OpReserveWriteN, wid)
+		defer func() { t.done(lo, try) }()
+	}
+
+	closed := func() bool { return rb.closed.Load() != 0 }
+	if closed() {
+		return 0, ErrClosed
+	}
+	if rb.credits != nil {
+		if err := rb.credits.take(context.Background(), rb, int64(n)); err != nil {
+			return 0, err
+		}
+		defer func() {
+			if err != nil {
+				rb.credits.refund(int64(n))
+			}
+		}()
+	}
+	last := uint64(n - 1)
+	if rb.reserveCap || rb.onFull != nil {
+		for try = 1; ; try++ {
+			w, c := rb.wReserve.Load(), rb.wCommit.Load()
+			if rb.debug.Load() {
+				t.try(w, try)
+			}
+			if rb.canWrite(w+last) && rb.claimWrite(w, uint64(n)) {
+				if closed() {
+					return 0, rb.abortClosed(wid, w, w+last)
+				}
+				rb.waitEnd(OpReserveWriteN, wid, waitStart)
+				return w, nil
+			}
+			if closed() {
+				return 0, ErrClosed
+			}
+			if retry, err := rb.full(wid); err != nil {
+				return 0, err
+			} else if retry {
+				continue
+			}
+			if waitStart.IsZero() {
+				rb.writeWaited(w + last)
+				rb.observeFull(wid)
+			}
+			waitStart = rb.waitBegin(waitStart)
+			if rb.strategy().Spin(context.Background(), try, rb.yield) {
+				continue
+			}
+			rb.waitWriteR.wait(rb.writeNeed(w+last), func() bool {
+				return rb.canWrite(rb.wReserve.Load()+last) || closed() || rb.canOverwrite(c)
+			})
+			rb.observeWakeup(OpReserveWriteN, wid)
+		}
+	}
+
+	lo = rb.takeWrite(uint64(n))
+	hi := lo + last
+	for try = 1; !rb.canWrite(hi); try++ {
+		if closed() {
+			return 0, rb.abortClosed(wid, lo, hi)
+		}
+		if rb.debug.Load() {
+			t.try(lo, try)
+		}
+		if try == 1 {
+			rb.writeWaited(hi)
+			rb.observeFull(wid)
+		}
+		waitStart = rb.waitBegin(waitStart)
+		if rb.strategy().Spin(context.Background(), try, rb.yield) {
+			continue
+		}
+		rb.waitWriteR.wait(rb.writeNeed(hi), func() bool { return rb.canWrite(hi) || closed() })
+		rb.observeWakeup(OpReserveWriteN, wid)
+	}
+	rb.waitEnd(OpReserveWriteN, wid, waitStart)
+	if closed() {
+		return 0, rb.abortClosed(wid, lo, hi)
+	}
+	return lo, nil
+}
+
+// commitWriteN commits write ids [lo, hi] at once.
+func (rb *RingBuffer) commitWriteN(wid int, lo, hi uint64) error {
+	return rb.commitWriteDone(lo, hi)
+}
+
+// BatchPublisher publishes slices of any length into a ring.
+// Input is split into chunks of at most the ring size; each chunk is
+// reserved and committed as one contiguous range, so callers don't need to
+// know the ring size and pay one reservation per chunk, not per item.
+type BatchPublisher[T any] struct {
+	rb    *RingBuffer
+	store func(id uint64, v T)
+}
+
+// NewBatchPublisher returns a BatchPublisher on rb. store is called to put
+// item v into the slot of id, between reservation and commit.
+func NewBatchPublisher[T any](rb *RingBuffer, store func(id uint64, v T)) *BatchPublisher[T] {
+	return &BatchPublisher[T]{rb: rb, store: store}
+}
+
+// Publish publishes items in order, waiting for free slots chunk by chunk.
+// Items of concurrent calls are not interleaved within a chunk.
+// It returns ErrClosed once the ring is closed, and the error of a failed
+// commit; chunks published before stay published.
+// It is goroutine-safe.
+func (p *BatchPublisher[T]) Publish(wid int, items []T) error {
+	size := p.rb.Size()
+	for len(items) > 0 {
+		n := len(items)
+		if n > size {
+			n = size
+		}
+		lo, hi, err := p.rb.ReserveWriteN(wid, n)
+		if err != nil {
+			return err
+		}
+		for i, v := range items[:n] {
+			p.store(lo+uint64(i), v)
+		}
+		if err := p.rb.CommitWriteN(wid, lo, hi); err != nil {
+			return err
+		}
+		items = items[n:]
+	}
+	return nil
+}
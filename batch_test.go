@@ -0,0 +1,180 @@
+package ringbuffer
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// TestBatchPublisher publishes more items than the ring holds from two
+// publishers and checks each chunk lands in a contiguous id range.
+func TestBatchPublisher(t *testing.T) {
+	defer parallel()()
+	const size, n = 5, 23
+	rb := MustNewRingBuffer(size)
+	type item struct{ pub, seq int }
+	slots := make([]item, rb.Slots())
+	p := NewBatchPublisher(rb, func(id uint64, v item) { slots[rb.BufferIndex(id)] = v })
+	var wg sync.WaitGroup
+	wg.Add(2)
+	for pub := 0; pub < 2; pub++ {
+		go func(pub int) {
+			defer wg.Done()
+			items := make([]item, n)
+			for i := range items {
+				items[i] = item{pub, i}
+			}
+			if err := p.Publish(pub, items); err != nil {
+				t.Error(err)
+			}
+		}(pub)
+	}
+	read := make([]item, 2*n)
+	for i := range read {
+		id, _ := rb.ReserveRead(0)
+		read[i] = slots[rb.BufferIndex(id)]
+		rb.CommitRead(0, id)
+	}
+	wg.Wait()
+	next := [2]int{}
+	for i, v := range read {
+		if v.seq != next[v.pub] {
+			t.Fatalf("publisher %d: got item %d, want %d", v.pub, v.seq, next[v.pub])
+		}
+		next[v.pub]++
+		if i > 0 && v.seq%size != 0 && read[i-1].pub != v.pub {
+			t.Fatalf("chunk of publisher %d interleaved at item %d", v.pub, v.seq)
+		}
+	}
+}
+
+func TestBatchPublisherClosed(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(4)
+	p := NewBatchPublisher(rb, func(id uint64, v int) {})
+	rb.Close()
+	if err := p.Publish(0, []int{1, 2}); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Publish after Close: %v", err)
+	}
+}
+
+// TestBatchPublisherObserved pins that Publish commits through
+// CommitWriteN, so observers see every chunk.
+func TestBatchPublisherObserved(t *testing.T) {
+	defer parallel()()
+	obs := &eventRecorder{}
+	rb := MustNewRingBuffer(4, WithObserver(obs))
+	p := NewBatchPublisher(rb, func(id uint64, v int) {})
+	if err := p.Publish(1, []int{1, 2, 3, 4}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"commit CommitWriteN 1 [0, 3]"}
+	if got := obs.take(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("events %q, want %q", got, want)
+	}
+}
+
+// TestBatchWriteReadN moves items in batches of varied sizes between
+// several writers and readers and checks each is read exactly once.
+func TestBatchWriteReadN(t *testing.T) {
+	defer parallel()()
+	const writers, readers, n = 4, 2, 3000
+	rb := MustNewRingBuffer(8)
+	type item struct{ w, seq int }
+	slots := make([]item, rb.Slots())
+	var wg sync.WaitGroup
+	wg.Add(writers)
+	for w := 0; w < writers; w++ {
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < n; {
+				k := 1 + (i+w)%rb.Size()
+				if k > n-i {
+					k = n - i
+				}
+				lo, hi, err := rb.ReserveWriteN(w, k)
+				if err != nil || hi-lo+1 != uint64(k) {
+					t.Errorf("ReserveWriteN(%d) = [%d, %d], %v", k, lo, hi, err)
+					return
+				}
+				for id := lo; id <= hi; id++ {
+					slots[rb.BufferIndex(id)] = item{w, i}
+					i++
+				}
+				rb.CommitWriteN(w, lo, hi)
+			}
+		}(w)
+	}
+	read := make([][]item, readers)
+	var rg sync.WaitGroup
+	rg.Add(readers)
+	var mu sync.Mutex
+	left := writers * n
+	for r := 0; r < readers; r++ {
+		go func(r int) {
+			defer rg.Done()
+			for {
+				mu.Lock()
+				if left == 0 {
+					mu.Unlock()
+					return
+				}
+				max := 1 + r*4
+				if max > left {
+					max = left
+				}
+				lo, hi, err := rb.ReserveReadN(r, max)
+				if err != nil {
+					mu.Unlock()
+					t.Error(err)
+					return
+				}
+				left -= int(hi - lo + 1)
+				mu.Unlock()
+				for id := lo; id <= hi; id++ {
+					read[r] = append(read[r], slots[rb.BufferIndex(id)])
+				}
+				rb.CommitReadN(r, lo, hi)
+			}
+		}(r)
+	}
+	wg.Wait()
+	rg.Wait()
+	seen := make([][]bool, writers)
+	for w := range seen {
+		seen[w] = make([]bool, n)
+	}
+	for _, items := range read {
+		for _, v := range items {
+			if seen[v.w][v.seq] {
+				t.Fatalf("writer %d item %d read twice", v.w, v.seq)
+			}
+			seen[v.w][v.seq] = true
+		}
+	}
+	for w := range seen {
+		for seq, ok := range seen[w] {
+			if !ok {
+				t.Fatalf("writer %d item %d never read", w, seq)
+			}
+		}
+	}
+}
+
+func TestBatchSizeMisuse(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(4)
+	for _, n := range []int{0, 5} {
+		if _, _, err := rb.ReserveWriteN(0, n); !errors.Is(err, ErrBatchSize) {
+			t.Fatalf("ReserveWriteN(%d): %v", n, err)
+		}
+	}
+	if _, _, err := rb.ReserveReadN(0, 0); !errors.Is(err, ErrBatchSize) {
+		t.Fatalf("ReserveReadN(0): %v", err)
+	}
+	lo, hi, _ := rb.ReserveWriteN(0, 2)
+	if err := rb.CommitWriteN(0, hi, lo); !errors.Is(err, ErrBatchSize) {
+		t.Fatalf("CommitWriteN(%d, %d): %v", hi, lo, err)
+	}
+}
@@ -0,0 +1,146 @@
+package ringbuffer
+
+import (
+	"sync"
+	"time"
+)
+
+// Batcher collects items of a producer into a local batch and publishes it
+// through a BatchPublisher when it holds max items, or delay after its first
+// item, so low traffic periods don't strand items in a partial batch.
+// Delays are driven by a timer wheel shared by every Batcher, so idle
+// batchers cost no timer each; they fire with a resolution of a millisecond.
+type Batcher[T any] struct {
+	pub   *BatchPublisher[T]
+	wid   int
+	max   int
+	delay time.Duration
+
+	mu    sync.Mutex
+	items []T
+	gen   uint64 // bumped by every flush, so stale timers do nothing
+}
+
+// NewBatcher returns a Batcher publishing through p as writer wid.
+func NewBatcher[T any](p *BatchPublisher[T], wid, max int, delay time.Duration) *Batcher[T] {
+	return &Batcher[T]{pub: p, wid: wid, max: max, delay: delay, items: make([]T, 0, max)}
+}
+
+// Add appends v to the batch and publishes the batch if it is full.
+// It returns the error of publishing, ErrClosed once the ring is closed.
+// It is goroutine-safe.
+func (b *Batcher[T]) Add(v T) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.items = append(b.items, v)
+	if len(b.items) >= b.max {
+		return b.flush()
+	}
+	if len(b.items) == 1 {
+		gen := b.gen
+		batchWheel.after(b.delay, func() {
+			b.mu.Lock()
+			if b.gen == gen {
+				b.flush() //a closed ring drops the batch, like Add would
+			}
+			b.mu.Unlock()
+		})
+	}
+	return nil
+}
+
+// Flush publishes the batch now, even if it is not full.
+// It is goroutine-safe.
+func (b *Batcher[T]) Flush() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.flush()
+}
+
+// flush publishes the batch and empties it, published or not.
+func (b *Batcher[T]) flush() error {
+	b.gen++
+	if len(b.items) == 0 {
+		return nil
+	}
+	err := b.pub.Publish(b.wid, b.items)
+	var zero T
+	for i := range b.items {
+		b.items[i] = zero
+	}
+	b.items = b.items[:0]
+	return err
+}
+
+var batchWheel = newWheel(time.Millisecond, 512)
+
+// wheel is a hashed timer wheel. Its ticker goroutine only runs while some
+// timer is pending.
+type wheel struct {
+	tick time.Duration
+
+	mu      sync.Mutex
+	slots   [][]wheelTimer
+	pos     int
+	pending int
+	running bool
+}
+
+type wheelTimer struct {
+	rounds int // full turns left before firing
+	fn     func()
+}
+
+func newWheel(tick time.Duration, n int) *wheel {
+	return &wheel{tick: tick, slots: make([][]wheelTimer, n)}
+}
+
+// after calls fn on its own goroutine once d has passed, rounded up to a
+// tick; fn may wait on a full ring without holding up other timers.
+func (w *wheel) after(d time.Duration, fn func()) {
+	ticks := int((d + w.tick - 1) / w.tick)
+	if ticks < 1 {
+		ticks = 1
+	}
+	w.mu.Lock()
+	slot := (w.pos + ticks) % len(w.slots)
+	w.slots[slot] = append(w.slots[slot], wheelTimer{rounds: (ticks - 1) / len(w.slots), fn: fn})
+	w.pending++
+	if !w.running {
+		w.running = true
+		go w.run()
+	}
+	w.mu.Unlock()
+}
+
+func (w *wheel) run() {
+	t := time.NewTicker(w.tick)
+	defer t.Stop()
+	for range t.C {
+		w.mu.Lock()
+		w.pos = (w.pos + 1) % len(w.slots)
+		timers := w.slots[w.pos]
+		keep := timers[:0]
+		for _, e := range timers {
+			if e.rounds == 0 {
+				go e.fn()
+				w.pending--
+				continue
+			}
+			e.rounds--
+			keep = append(keep, e)
+		}
+		for i := len(keep); i < len(timers); i++ {
+			timers[i] = wheelTimer{} //drop fired closures
+		}
+		w.slots[w.pos] = keep
+		stop := w.pending == 0
+		if stop {
+			w.running = false
+		}
+		w.mu.Unlock()
+		if stop {
+			return
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package ringbuffer
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBatcher(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(16)
+	slots := make([]int, rb.Slots())
+	p := NewBatchPublisher(rb, func(id uint64, v int) { slots[rb.BufferIndex(id)] = v })
+	b := NewBatcher(p, 0, 3, 5*time.Millisecond)
+	read := func() int {
+		t.Helper()
+		id, err := rb.ReserveRead(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v := slots[rb.BufferIndex(id)]
+		rb.CommitRead(1, id)
+		return v
+	}
+
+	//a full batch is published at once
+	for v := 1; v <= 3; v++ {
+		if err := b.Add(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if rb.Len() != 3 {
+		t.Fatalf("%d items published by a full batch, want 3", rb.Len())
+	}
+	for v := 1; v <= 3; v++ {
+		if got := read(); got != v {
+			t.Fatalf("read %d, want %d", got, v)
+		}
+	}
+
+	//a partial batch waits for the delay
+	b.Add(4)
+	if rb.Len() != 0 {
+		t.Fatal("partial batch published before its delay")
+	}
+	start := time.Now()
+	if got := read(); got != 4 {
+		t.Fatalf("read %d, want 4", got)
+	}
+	if waited := time.Since(start); waited < 3*time.Millisecond {
+		t.Fatalf("partial batch published after %v", waited)
+	}
+
+	b.Add(5)
+	if err := b.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if rb.Len() != 1 {
+		t.Fatal("Flush did not publish the partial batch")
+	}
+	read()
+	time.Sleep(10 * time.Millisecond) //the stale timer of 5 does nothing
+	if rb.Len() != 0 {
+		t.Fatal("stale timer published again")
+	}
+
+	rb.Close()
+	b.Add(6)
+	b.Add(7)
+	if err := b.Add(8); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Add of a full batch after Close: %v, want ErrClosed", err)
+	}
+}
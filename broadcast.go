@@ -0,0 +1,243 @@
+package ringbuffer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// broadcast holds the Subscribers of a ring created WithBroadcast.
+type broadcast struct {
+	mu       sync.Mutex                    // serializes Subscribe and Close
+	subs     atomic.Pointer[[]*Subscriber] // copy on write, loaded by writers
+	waitDeps *waitList                     // Subscribers waiting for their dependencies
+}
+
+// WithBroadcast makes every consumer see every item (fan-out) instead of
+// competing for them, like the event processors of the Disruptor: each
+// consumer Subscribes and reads through its own cursor, and writers gate on
+// the slowest Subscriber. ReserveRead and CommitRead must not be used.
+// Items published while nobody is subscribed are dropped. Markers are
+// dropped once every Subscriber is past them.
+func WithBroadcast() Option {
+	return func(rb *RingBuffer) {
+		rb.broadcast = &broadcast{waitDeps: newWaitList()}
+	}
+}
+
+// Subscriber is a consumer of a broadcast ring with its own read cursor,
+// see WithBroadcast. A Subscriber is used by a single goroutine, except
+// Close.
+type Subscriber struct {
+	rb     *RingBuffer
+	deps   []*Subscriber   // Subscribers that must commit an id first, readonly
+	next   uint64          // next id to reserve, owner only
+	marks  []uint64        // markers skipped above cursor, owner only
+	topics map[uint16]bool // nil unless Filter, owner only
+	cursor atomic.Uint64   // ids below it are consumed
+	closed atomic.Bool
+}
+
+// Subscribe adds a Subscriber that sees every item reserved for write from
+// now on. The ring must be created WithBroadcast.
+// The Subscriber sees an item only once every Subscriber in after has
+// committed it, so the stages of a pipeline can share one ring, e.g. a
+// journal before the business logic. A closed Subscriber no longer holds
+// back the ones after it.
+// It is goroutine-safe.
+func (rb *RingBuffer) Subscribe(after ...*Subscriber) *Subscriber {
+	b := rb.broadcast
+	if b == nil {
+		panic("RingBuffer: Subscribe needs a ring created WithBroadcast")
+	}
+	for _, d := range after {
+		if d.rb != rb {
+			panic("RingBuffer: Subscribe after a Subscriber of another ring")
+		}
+	}
+	s := &Subscriber{rb: rb, deps: append([]*Subscriber(nil), after...)}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s.cursor.Store(rb.wReserve.Load())
+	subs := append(b.list(), s)
+	b.subs.Store(&subs)
+	//writers that checked their slot before s was listed hold ids below
+	//this, so s starts past them
+	s.next = rb.wReserve.Load()
+	s.cursor.Store(s.next)
+	return s
+}
+
+// ReserveRead returns the next id for s to read.
+// It will wait if s has seen every item, or for the Subscribers s comes
+// after, and returns ErrClosed once the ring is closed, see
+// WithDrainOnClose. Markers are skipped, calling the barrier handler of
+// barriers.
+func (s *Subscriber) ReserveRead(wid int) (uint64, error) {
+	rb := s.rb
+	for try := 1; ; try++ {
+		id := s.next
+		published := rb.canRead(id)
+		if published && s.depsPast(id) {
+			s.next++
+			if rb.tombs.count.Load() > 0 && s.skipMarker(wid, id) {
+				try = 0
+				continue
+			}
+			if s.topics != nil && !s.topics[rb.Meta(id).Topic] {
+				s.skip(id)
+				try = 0
+				continue
+			}
+			return id, nil
+		}
+		if rb.readClosed(id) {
+			return 0, ErrClosed
+		}
+		if rb.readStrategy().Spin(context.Background(), try, rb.yield) {
+			continue
+		}
+		if !published {
+			rb.waitReadR.wait(id+1, func() bool { return rb.canRead(id) || rb.readClosed(id) })
+			continue
+		}
+		rb.broadcast.waitDeps.wait(id+1, func() bool { return s.depsPast(id) || rb.readClosed(id) })
+	}
+}
+
+// depsPast reports whether every Subscriber s comes after committed id.
+func (s *Subscriber) depsPast(id uint64) bool {
+	for _, d := range s.deps {
+		if d.cursor.Load() <= id && !d.closed.Load() {
+			return false
+		}
+	}
+	return true
+}
+
+// CommitRead releases id to the writers once every Subscriber is past it.
+// Ids must be committed in reservation order.
+func (s *Subscriber) CommitRead(wid int, id uint64) error {
+	c := s.cursor.Load()
+	switch {
+	case id >= s.next:
+		return s.rb.misuse(fmt.Errorf("%w %d", ErrNotReserved, id))
+	case id < c:
+		return s.rb.misuse(fmt.Errorf("%w %d", ErrCommitted, id))
+	case id > c:
+		return s.rb.misuse(fmt.Errorf("RingBuffer: commit of %d out of order, next is %d", id, c))
+	}
+	for id++; len(s.marks) > 0 && s.marks[0] == id; id++ {
+		s.marks = s.marks[1:]
+	}
+	s.cursor.Store(id)
+	s.rb.subscriberMoved(id)
+	return nil
+}
+
+// Close removes s from its ring, so writers stop gating on it.
+// It is goroutine-safe.
+func (s *Subscriber) Close() {
+	s.closed.Store(true)
+	b := s.rb.broadcast
+	b.mu.Lock()
+	old := b.list()
+	subs := make([]*Subscriber, 0, len(old))
+	for _, o := range old {
+		if o != s {
+			subs = append(subs, o)
+		}
+	}
+	b.subs.Store(&subs)
+	b.mu.Unlock()
+	if s.rb.tombs.count.Load() > 0 {
+		s.rb.tombs.sweep(b.floor(s.rb))
+	}
+	s.rb.waitWriteR.wakeAll()
+	s.rb.waitBarrier.wakeAll()
+	b.waitDeps.wakeAll()
+}
+
+// skipMarker commits id if it is a marker, and reports whether it did so.
+// A marker after ids still being read is committed along with them.
+// Markers stay recorded until every Subscriber is past them.
+func (s *Subscriber) skipMarker(wid int, id uint64) bool {
+	m, ok := s.rb.tombs.marker(id)
+	if !ok {
+		return false
+	}
+	if m == markBarrier && s.rb.onBarrier != nil {
+		s.rb.onBarrier(wid, id)
+	}
+	s.skip(id)
+	return true
+}
+
+// skip commits id, which s doesn't return, now or along with the ids
+// before it still being read.
+func (s *Subscriber) skip(id uint64) {
+	if s.cursor.Load() == id {
+		s.cursor.Store(id + 1)
+		s.rb.subscriberMoved(id + 1)
+	} else {
+		s.marks = append(s.marks, id)
+	}
+}
+
+// Filter makes s only return the items whose SlotMeta.Topic is one of
+// topics, skipping and committing the others, so consumers of a broadcast
+// ring subscribe to their streams only. The ring must be created
+// WithSlotMeta. It must be called before s reads, by its goroutine.
+func (s *Subscriber) Filter(topics ...uint16) *Subscriber {
+	if s.rb.meta == nil {
+		panic("RingBuffer: Subscriber.Filter needs a ring created WithSlotMeta")
+	}
+	s.topics = make(map[uint16]bool, len(topics))
+	for _, t := range topics {
+		s.topics[t] = true
+	}
+	return s
+}
+
+// subscriberMoved wakes the goroutines waiting for a Subscriber that moved
+// its cursor to cursor, or for the slowest Subscriber, and drops the
+// markers every Subscriber is past.
+// Subscribers waiting for different dependencies share a wait list: a wake
+// by the cursor of another dependency may be spurious, but never missed.
+func (rb *RingBuffer) subscriberMoved(cursor uint64) {
+	rb.broadcast.waitDeps.wake(cursor)
+	if rb.tombs.count.Load() > 0 {
+		rb.tombs.sweep(rb.broadcast.floor(rb))
+	}
+	if rb.waitWriteR.waiters.Load() == 0 && rb.waitBarrier.waiters.Load() == 0 {
+		return
+	}
+	floor := rb.broadcast.floor(rb)
+	rb.waitWriteR.wake(floor)
+	rb.waitBarrier.wake(floor)
+}
+
+// list returns the current Subscribers, which must not be modified.
+func (b *broadcast) list() []*Subscriber {
+	if p := b.subs.Load(); p != nil {
+		return *p
+	}
+	return nil
+}
+
+// floor returns the cursor of the slowest Subscriber, or the write commit
+// if there is none.
+func (b *broadcast) floor(rb *RingBuffer) uint64 {
+	subs := b.list()
+	if len(subs) == 0 {
+		return rb.wCommit.Load()
+	}
+	floor := subs[0].cursor.Load()
+	for _, s := range subs[1:] {
+		if c := s.cursor.Load(); c < floor {
+			floor = c
+		}
+	}
+	return floor
+}
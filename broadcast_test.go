@@ -0,0 +1,259 @@
+package ringbuffer
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestBroadcastFanOut checks every Subscriber reads every item in order,
+// under writers gated by the slowest of them.
+func TestBroadcastFanOut(t *testing.T) {
+	defer parallel()()
+	const subs, writers, n = 3, 2, 2000
+	rb := MustNewRingBuffer(4, WithBroadcast())
+	vals := make([]int, rb.Slots())
+	var rg sync.WaitGroup
+	rg.Add(subs)
+	for i := 0; i < subs; i++ {
+		s := rb.Subscribe()
+		go func(i int) {
+			defer rg.Done()
+			next := make([]int, writers)
+			for k := 0; k < writers*n; k++ {
+				id, err := s.ReserveRead(i)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				v := vals[rb.BufferIndex(id)]
+				if w := v / n; v%n != next[w] {
+					t.Errorf("subscriber %d: writer %d item %d, want %d", i, w, v%n, next[w])
+				}
+				next[v/n]++
+				s.CommitRead(i, id)
+			}
+		}(i)
+	}
+	var wg sync.WaitGroup
+	wg.Add(writers)
+	for w := 0; w < writers; w++ {
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				id, _ := rb.ReserveWrite(w)
+				vals[rb.BufferIndex(id)] = w*n + i
+				rb.CommitWrite(w, id)
+			}
+		}(w)
+	}
+	wg.Wait()
+	rg.Wait()
+}
+
+// TestBroadcastSlowestGates pins that writers wait for the slowest
+// Subscriber, and stop waiting for it once it is closed.
+func TestBroadcastSlowestGates(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(2, WithBroadcast())
+	fast, slow := rb.Subscribe(), rb.Subscribe()
+	for i := 0; i < 2; i++ {
+		id, _ := rb.ReserveWrite(0)
+		rb.CommitWrite(0, id)
+		got, _ := fast.ReserveRead(0)
+		fast.CommitRead(0, got)
+	}
+	if _, ok := rb.TryReserveWrite(0); ok {
+		t.Fatal("writer passed a Subscriber that read nothing")
+	}
+	done := make(chan error)
+	go func() {
+		_, err := rb.ReserveWrite(0)
+		done <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	slow.Close()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBroadcastClose(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(2, WithBroadcast())
+	s := rb.Subscribe()
+	done := make(chan error)
+	go func() {
+		_, err := s.ReserveRead(0)
+		done <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	rb.Close()
+	if err := <-done; !errors.Is(err, ErrClosed) {
+		t.Fatalf("Subscriber waiting at Close: %v", err)
+	}
+}
+
+// TestBroadcastMarkersCompacted checks markers are dropped once the
+// slowest Subscriber is past them, without CompactTombstones.
+func TestBroadcastMarkersCompacted(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(8, WithBroadcast())
+	fast, slow := rb.Subscribe(), rb.Subscribe()
+	id, _ := rb.ReserveWrite(0)
+	rb.AbortWrite(0, id)
+	rb.Barrier(0)
+	id, _ = rb.ReserveWrite(0)
+	rb.CommitWrite(0, id)
+	read := func(s *Subscriber) {
+		t.Helper()
+		r, err := s.ReserveRead(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r != id {
+			t.Fatalf("read %d, want %d past the markers", r, id)
+		}
+		s.CommitRead(0, r)
+	}
+	read(fast)
+	if n := rb.Tombstones(); n != 2 {
+		t.Fatalf("%d markers after the fast Subscriber, want 2", n)
+	}
+	read(slow)
+	if n := rb.Tombstones(); n != 0 {
+		t.Fatalf("%d markers after every Subscriber passed them", n)
+	}
+
+	//closing the slowest Subscriber releases its markers too
+	rb.Barrier(0)
+	id, _ = rb.ReserveWrite(0)
+	rb.CommitWrite(0, id)
+	read(fast)
+	slow.Close()
+	if n := rb.Tombstones(); n != 0 {
+		t.Fatalf("%d markers after the slowest Subscriber closed", n)
+	}
+}
+
+func TestSubscriberFilter(t *testing.T) {
+	defer parallel()()
+	const n = 3000
+	rb := MustNewRingBuffer(4, WithBroadcast(), WithSlotMeta())
+	vals := make([]int, rb.Slots())
+	subs := []*Subscriber{rb.Subscribe().Filter(1), rb.Subscribe().Filter(2)}
+	var wg sync.WaitGroup
+	for i, s := range subs {
+		wg.Add(1)
+		go func(topic uint16, s *Subscriber) {
+			defer wg.Done()
+			want := int(topic) - 1 //topic 1 gets the even values, 2 the odd
+			for want < n {
+				id, err := s.ReserveRead(int(topic))
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if got := rb.Meta(id).Topic; got != topic {
+					t.Errorf("topic %d subscriber got topic %d", topic, got)
+				}
+				if v := vals[rb.BufferIndex(id)]; v != want {
+					t.Errorf("topic %d subscriber got %d, want %d", topic, v, want)
+				}
+				want += 2
+				s.CommitRead(int(topic), id)
+			}
+		}(uint16(i+1), s)
+	}
+	for i := 0; i < n; i++ {
+		id, _ := rb.ReserveWrite(0)
+		vals[rb.BufferIndex(id)] = i
+		rb.Meta(id).Topic = uint16(i%2 + 1)
+		rb.CommitWrite(0, id)
+	}
+	wg.Wait()
+}
+
+// TestSubscriberDependencies runs a diamond of Subscribers, a journal
+// before two handlers before a publisher, and checks no stage sees an id
+// before the stages it comes after committed it.
+func TestSubscriberDependencies(t *testing.T) {
+	defer parallel()()
+	const n = 3000
+	rb := MustNewRingBuffer(4, WithBroadcast())
+	journal := rb.Subscribe()
+	a, b := rb.Subscribe(journal), rb.Subscribe(journal)
+	out := rb.Subscribe(a, b)
+	stages := []*Subscriber{journal, a, b, out}
+	after := [][]int{nil, {0}, {0}, {1, 2}}
+	var done [4][]bool
+	var mu sync.Mutex
+	for i := range done {
+		done[i] = make([]bool, n)
+	}
+	var wg sync.WaitGroup
+	wg.Add(len(stages))
+	for i, s := range stages {
+		go func(i int, s *Subscriber) {
+			defer wg.Done()
+			for k := 0; k < n; k++ {
+				id, err := s.ReserveRead(i)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				mu.Lock()
+				for _, d := range after[i] {
+					if !done[d][id] {
+						t.Errorf("stage %d got id %d before stage %d committed it", i, id, d)
+					}
+				}
+				done[i][id] = true
+				mu.Unlock()
+				s.CommitRead(i, id)
+			}
+		}(i, s)
+	}
+	for i := 0; i < n; i++ {
+		id, _ := rb.ReserveWrite(0)
+		rb.CommitWrite(0, id)
+	}
+	wg.Wait()
+}
+
+// TestSubscriberDependencyClosed pins that a closed Subscriber no longer
+// holds back the ones after it.
+func TestSubscriberDependencyClosed(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(4, WithBroadcast())
+	first := rb.Subscribe()
+	second := rb.Subscribe(first)
+	id, _ := rb.ReserveWrite(0)
+	rb.CommitWrite(0, id)
+	done := make(chan uint64)
+	go func() {
+		got, _ := second.ReserveRead(0)
+		done <- got
+	}()
+	select {
+	case <-done:
+		t.Fatal("Subscriber read before the one it comes after")
+	case <-time.After(20 * time.Millisecond):
+	}
+	first.Close()
+	if got := <-done; got != id {
+		t.Fatalf("read %d, want %d", got, id)
+	}
+}
+
+func TestSubscribeAfterOtherRing(t *testing.T) {
+	defer parallel()()
+	other := MustNewRingBuffer(2, WithBroadcast()).Subscribe()
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Subscribe after a Subscriber of another ring accepted")
+		}
+	}()
+	MustNewRingBuffer(2, WithBroadcast()).Subscribe(other)
+}
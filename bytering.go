@@ -0,0 +1,109 @@
+package ringbuffer
+
+import (
+	"errors"
+	"io"
+
+	"ringbuffer/internal/wrapcopy"
+)
+
+// ByteRingBuffer is a goroutine-safe pipe with bounded memory: a ring of
+// bytes, each one an id of the sequencing core, implementing io.Reader,
+// io.Writer and io.Closer.
+type ByteRingBuffer struct {
+	rb  *RingBuffer
+	buf []byte
+}
+
+var _ io.ReadWriteCloser = (*ByteRingBuffer)(nil)
+
+// NewByteRingBuffer returns a byte ring holding size bytes at most.
+// It is always created WithDrainOnClose, so readers get every byte written
+// before Close. It fails where NewRingBuffer does.
+func NewByteRingBuffer(size int, opts ...Option) (*ByteRingBuffer, error) {
+	rb, err := NewRingBuffer(size, append(opts, WithDrainOnClose())...)
+	if err != nil {
+		return nil, err
+	}
+	return &ByteRingBuffer{rb: rb, buf: make([]byte, rb.Slots())}, nil
+}
+
+// MustNewByteRingBuffer is NewByteRingBuffer panicking on error.
+func MustNewByteRingBuffer(size int, opts ...Option) *ByteRingBuffer {
+	b, err := NewByteRingBuffer(size, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// RingBuffer returns the underlying id based ring.
+func (b *ByteRingBuffer) RingBuffer() *RingBuffer {
+	return b.rb
+}
+
+// Write writes p, waiting while the ring is full. Writes of at most Size
+// bytes are never interleaved with other writes. Once the ring is closed
+// it returns io.ErrClosedPipe.
+// It is goroutine-safe.
+func (b *ByteRingBuffer) Write(p []byte) (n int, err error) {
+	for n < len(p) {
+		chunk := len(p) - n
+		if chunk > b.rb.size {
+			chunk = b.rb.size
+		}
+		lo, hi, err := b.rb.ReserveWriteN(-1, chunk)
+		if err != nil {
+			return n, pipeErr(err)
+		}
+		wrapcopy.In(b.buf, b.rb.BufferIndex(lo), p[n:n+chunk])
+		b.rb.CommitWriteN(-1, lo, hi)
+		n += chunk
+	}
+	return n, nil
+}
+
+// Read reads up to len(p) bytes, waiting until at least one is written.
+// Once the ring is closed and drained it returns io.EOF.
+// It is goroutine-safe.
+func (b *ByteRingBuffer) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	max := len(p)
+	if max > b.rb.size {
+		max = b.rb.size
+	}
+	lo, hi, err := b.rb.ReserveReadN(-1, max)
+	if err != nil {
+		if errors.Is(err, ErrClosed) {
+			return 0, io.EOF
+		}
+		return 0, err
+	}
+	n := int(hi - lo + 1)
+	wrapcopy.Out(p[:n], b.buf, b.rb.BufferIndex(lo))
+	b.rb.CommitReadN(-1, lo, hi)
+	return n, nil
+}
+
+// Close closes the ring: later writes fail, and reads return io.EOF once
+// the bytes written before are read. Closing twice returns
+// io.ErrClosedPipe.
+// It is goroutine-safe.
+func (b *ByteRingBuffer) Close() error {
+	return pipeErr(b.rb.Close())
+}
+
+// Len returns the bytes written and not read yet.
+// It is goroutine-safe.
+func (b *ByteRingBuffer) Len() int {
+	return int(b.rb.wCommit.Load() - b.rb.rCommit.Load())
+}
+
+func pipeErr(err error) error {
+	if errors.Is(err, ErrClosed) {
+		return io.ErrClosedPipe
+	}
+	return err
+}
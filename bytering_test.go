@@ -0,0 +1,102 @@
+package ringbuffer
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+// TestByteRingBufferCopy pipes a payload larger than the ring through it
+// in writes and reads of random sizes.
+func TestByteRingBufferCopy(t *testing.T) {
+	defer parallel()()
+	for _, size := range []int{1, 7, 16} {
+		payload := make([]byte, 20000)
+		rand.Read(payload)
+		b := MustNewByteRingBuffer(size)
+		go func() {
+			for p := payload; len(p) > 0; {
+				n := 1 + rand.Intn(3*size)
+				if n > len(p) {
+					n = len(p)
+				}
+				b.Write(p[:n])
+				p = p[n:]
+			}
+			b.Close()
+		}()
+		var got bytes.Buffer
+		buf := make([]byte, 2*size+1)
+		for {
+			n, err := b.Read(buf[:1+rand.Intn(len(buf))])
+			got.Write(buf[:n])
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		if !bytes.Equal(got.Bytes(), payload) {
+			t.Fatalf("size %d: read %d bytes differing from the %d written", size, got.Len(), len(payload))
+		}
+	}
+}
+
+// TestByteRingBufferAtomicWrites pins that writes of at most Size bytes
+// from concurrent writers are not interleaved.
+func TestByteRingBufferAtomicWrites(t *testing.T) {
+	defer parallel()()
+	const writers, n, rec = 4, 1000, 5
+	b := MustNewByteRingBuffer(12)
+	var wg sync.WaitGroup
+	wg.Add(writers)
+	for w := 0; w < writers; w++ {
+		go func(w int) {
+			defer wg.Done()
+			p := bytes.Repeat([]byte{byte('a' + w)}, rec)
+			for i := 0; i < n; i++ {
+				if _, err := b.Write(p); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(w)
+	}
+	go func() {
+		wg.Wait()
+		b.Close()
+	}()
+	got, err := io.ReadAll(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != writers*n*rec {
+		t.Fatalf("read %d bytes, want %d", len(got), writers*n*rec)
+	}
+	for i := 0; i < len(got); i += rec {
+		if r := got[i : i+rec]; !bytes.Equal(r, bytes.Repeat(r[:1], rec)) {
+			t.Fatalf("write interleaved at byte %d: %q", i, r)
+		}
+	}
+}
+
+func TestByteRingBufferClose(t *testing.T) {
+	defer parallel()()
+	b := MustNewByteRingBuffer(4)
+	b.Write([]byte("ab"))
+	b.Close()
+	if _, err := b.Write([]byte("c")); !errors.Is(err, io.ErrClosedPipe) {
+		t.Fatalf("Write after Close: %v", err)
+	}
+	if err := b.Close(); !errors.Is(err, io.ErrClosedPipe) {
+		t.Fatalf("second Close: %v", err)
+	}
+	got, err := io.ReadAll(b)
+	if err != nil || string(got) != "ab" {
+		t.Fatalf("ReadAll() = %q, %v after Close", got, err)
+	}
+}
@@ -0,0 +1,70 @@
+package ringbuffer
+
+import (
+	"sync/atomic"
+)
+
+// byteWatermarks calls fn when bytes in flight cross high, and again when
+// they fall back to low.
+type byteWatermarks struct {
+	low, high int64
+	fn        func(inFlight int64, high bool)
+	above     atomic.Int32
+}
+
+// WithByteWatermarks calls fn with high=true when the payload bytes in flight
+// rise above high, and with high=false when they fall back to low or below.
+// Byte rings are sized in bytes, so this is their backpressure signal.
+func WithByteWatermarks(low, high int64, fn func(inFlight int64, high bool)) Option {
+	return func(rb *RingBuffer) {
+		rb.watermarks = &byteWatermarks{low: low, high: high, fn: fn}
+	}
+}
+
+func (w *byteWatermarks) check(inFlight int64) {
+	if inFlight > w.high {
+		if w.above.CompareAndSwap(0, 1) {
+			w.fn(inFlight, true)
+		}
+	} else if inFlight <= w.low {
+		if w.above.CompareAndSwap(1, 0) {
+			w.fn(inFlight, false)
+		}
+	}
+}
+
+// CommitWriteBytes commits write id like CommitWrite and accounts n payload
+// bytes as published.
+// It is goroutine-safe.
+func (rb *RingBuffer) CommitWriteBytes(wid int, id uint64, n int) error {
+	w := rb.bytesWritten.Add(uint64(n)) //before readers may account them
+	if err := rb.CommitWrite(wid, id); err != nil {
+		rb.bytesWritten.Add(-uint64(n))
+		return err
+	}
+	if rb.watermarks != nil {
+		rb.watermarks.check(int64(w - rb.bytesRead.Load()))
+	}
+	return nil
+}
+
+// CommitReadBytes commits read id like CommitRead and accounts n payload
+// bytes as consumed.
+// It is goroutine-safe.
+func (rb *RingBuffer) CommitReadBytes(wid int, id uint64, n int) error {
+	if err := rb.CommitRead(wid, id); err != nil {
+		return err
+	}
+	r := rb.bytesRead.Add(uint64(n))
+	if rb.watermarks != nil {
+		rb.watermarks.check(int64(rb.bytesWritten.Load() - r))
+	}
+	return nil
+}
+
+// BytesInFlight returns payload bytes published but not consumed yet.
+// It is goroutine-safe.
+func (rb *RingBuffer) BytesInFlight() int64 {
+	r := rb.bytesRead.Load()
+	return int64(rb.bytesWritten.Load() - r)
+}
@@ -0,0 +1,55 @@
+package ringbuffer
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestBytesInFlightNeverNegative(t *testing.T) {
+	defer parallel()()
+	const n, size = 20000, 100
+	var low atomic.Int64
+	rb := MustNewRingBuffer(4, WithByteWatermarks(0, 4*size, func(inFlight int64, high bool) {
+		if inFlight < low.Load() {
+			low.Store(inFlight)
+		}
+	}))
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < n; i++ {
+			id, _ := rb.ReserveWrite(0)
+			rb.CommitWriteBytes(0, id, size)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < n; i++ {
+			id, _ := rb.ReserveRead(1)
+			rb.CommitReadBytes(1, id, size)
+			if b := rb.BytesInFlight(); b < 0 {
+				t.Errorf("%d bytes in flight", b)
+				return
+			}
+		}
+	}()
+	wg.Wait()
+	if low.Load() < 0 || rb.BytesInFlight() != 0 {
+		t.Fatalf("in flight went down to %d, ends at %d", low.Load(), rb.BytesInFlight())
+	}
+}
+
+func TestCommitWriteBytesError(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(4, WithMisusePolicy(MisuseError))
+	id, _ := rb.ReserveWrite(0)
+	rb.CommitWriteBytes(0, id, 10)
+	if err := rb.CommitWriteBytes(0, id, 10); err == nil {
+		t.Fatal("committing twice succeeded")
+	}
+	if b := rb.BytesInFlight(); b != 10 {
+		t.Fatalf("%d bytes in flight, want 10", b)
+	}
+}
@@ -0,0 +1,63 @@
+package ringbuffer
+
+import (
+	"runtime"
+	"time"
+)
+
+// Calibration is the result of Calibrate.
+type Calibration struct {
+	Procs       int           // GOMAXPROCS at calibration
+	YieldCost   time.Duration // average cost of one yield, see WithYield
+	WakeLatency time.Duration // average park to wakeup latency
+	Spins       int           // yields worth doing before parking
+	Strategy    WaitStrategy  // chosen strategy
+}
+
+const (
+	calibrateRounds = 200
+	maxSpins        = 1000
+)
+
+// Calibrate runs a brief micro-benchmark (a few milliseconds) to pick a
+// wait strategy for this machine, and makes it the strategy of rb.
+// Yielding pays off while a few yields cost less than parking and being
+// woken up, so spins are sized from the ratio of the two.
+// Call it at startup, calibration is disturbed by a busy ring.
+func (rb *RingBuffer) Calibrate() Calibration {
+	c := Calibration{Procs: runtime.GOMAXPROCS(0)}
+
+	start := time.Now()
+	for i := 0; i < calibrateRounds; i++ {
+		rb.yield()
+	}
+	c.YieldCost = time.Since(start) / calibrateRounds
+
+	ping, pong := make(chan struct{}), make(chan struct{})
+	go func() {
+		for range ping {
+			pong <- struct{}{}
+		}
+	}()
+	start = time.Now()
+	for i := 0; i < calibrateRounds; i++ {
+		ping <- struct{}{}
+		<-pong
+	}
+	close(ping)
+	c.WakeLatency = time.Since(start) / (2 * calibrateRounds)
+
+	if c.Procs > 1 && c.YieldCost > 0 {
+		c.Spins = int(c.WakeLatency / c.YieldCost)
+		if c.Spins > maxSpins {
+			c.Spins = maxSpins
+		}
+	}
+	if c.Spins > 0 {
+		c.Strategy = YieldingWaitStrategy{Spins: c.Spins}
+	} else {
+		c.Strategy = BlockingWaitStrategy{}
+	}
+	rb.SetWaitStrategy(c.Strategy)
+	return c
+}
@@ -0,0 +1,32 @@
+package ringbuffer
+
+import (
+	"runtime"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCalibrate(t *testing.T) {
+	defer parallel()()
+	var yields atomic.Int64
+	rb := MustNewRingBuffer(4, WithYield(func() {
+		yields.Add(1)
+		runtime.Gosched()
+	}))
+	c := rb.Calibrate()
+	if yields.Load() < calibrateRounds {
+		t.Fatalf("%d yields, want the WithYield hook timed %d times", yields.Load(), calibrateRounds)
+	}
+	if c.Procs != runtime.GOMAXPROCS(0) || c.YieldCost <= 0 || c.WakeLatency <= 0 {
+		t.Fatalf("%+v", c)
+	}
+	if c.Spins < 0 || c.Spins > maxSpins {
+		t.Fatalf("%d spins out of [0, %d]", c.Spins, maxSpins)
+	}
+	if rb.WaitStrategy() != c.Strategy {
+		t.Fatalf("ring strategy %#v, want the calibrated %#v", rb.WaitStrategy(), c.Strategy)
+	}
+	if ys, ok := c.Strategy.(YieldingWaitStrategy); ok != (c.Spins > 0) || ok && ys.Spins != c.Spins {
+		t.Fatalf("strategy %#v for %d spins", c.Strategy, c.Spins)
+	}
+}
@@ -0,0 +1,153 @@
+package ringbuffer
+
+import (
+	"context"
+	"math"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// Chain absorbs bursts with two rings: a small hot ring and a large cold
+// one. Items go to the hot ring while it has room; when it fills, they
+// overflow to the cold ring until the cold ring drains. Hot items are
+// always older than cold ones, so readers take hot first and order is kept.
+// The hot ring stays small and cache friendly on the common path, the
+// cold one soaks up multi-second bursts.
+type Chain[T any] struct {
+	hot, cold           *RingBuffer
+	hotSlots, coldSlots []T
+	mu                  sync.Mutex // serializes Put
+	avail               *waitList  // readers waiting for an item
+	closed              atomic.Int32
+}
+
+// ChainStats are the Stats of both rings of a Chain.
+type ChainStats struct {
+	Hot  Stats
+	Cold Stats
+	Len  int // items in both rings
+}
+
+// NewChain returns a Chain of a hot ring of hotSize and a cold ring of
+// coldSize. Options apply to both rings. It fails where NewRingBuffer
+// does.
+func NewChain[T any](hotSize, coldSize int, opts ...Option) (*Chain[T], error) {
+	hot, err := NewRingBuffer(hotSize, opts...)
+	if err != nil {
+		return nil, err
+	}
+	cold, err := NewRingBuffer(coldSize, opts...)
+	if err != nil {
+		return nil, err
+	}
+	elem := unsafe.Sizeof(*new(T))
+	if err := checkSlots(hot.Slots()+cold.Slots(), elem); err != nil {
+		return nil, err
+	}
+	c := &Chain[T]{hot: hot, cold: cold, avail: newWaitList()}
+	c.hotSlots = make([]T, c.hot.Slots())
+	c.coldSlots = make([]T, c.cold.Slots())
+	return c, nil
+}
+
+// MustNewChain is NewChain panicking on error.
+func MustNewChain[T any](hotSize, coldSize int, opts ...Option) *Chain[T] {
+	c, err := NewChain[T](hotSize, coldSize, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// Put publishes v. It waits only if both rings are full.
+// It returns ErrClosed after Close.
+// It is goroutine-safe.
+func (c *Chain[T]) Put(v T) error {
+	c.mu.Lock()
+	if c.closed.Load() != 0 {
+		c.mu.Unlock()
+		return ErrClosed
+	}
+	//the hot ring only takes items while no cold item is left unread
+	coldEmpty := c.cold.rReserve.Load() >= c.cold.wReserve.Load()
+	if coldEmpty {
+		if id, ok := c.hot.tryReserveWrite(); ok {
+			c.hotSlots[c.hot.BufferIndex(id)] = v
+			c.hot.CommitWrite(-1, id)
+			c.mu.Unlock()
+			c.avail.wake(math.MaxUint64)
+			return nil
+		}
+	}
+	id, _ := c.cold.ReserveWrite(-1) //the rings of a Chain are never closed
+	c.coldSlots[c.cold.BufferIndex(id)] = v
+	c.cold.CommitWrite(-1, id)
+	c.mu.Unlock()
+	c.avail.wake(math.MaxUint64)
+	return nil
+}
+
+// Close stops Put; Get returns the items left, then reports the Chain
+// drained. Waiting Put calls finish first.
+// It is goroutine-safe.
+func (c *Chain[T]) Close() {
+	c.mu.Lock()
+	c.closed.Store(1)
+	c.mu.Unlock()
+	c.avail.wakeAll()
+}
+
+// Get waits for the oldest item, like a channel receive: ok is false once
+// the Chain is closed and drained.
+// It is goroutine-safe.
+func (c *Chain[T]) Get(wid int) (v T, ok bool) {
+	for try := 1; ; try++ {
+		//load closed first: no Put can publish after it reads set
+		closed := c.closed.Load() != 0
+		if v, ok := c.take(wid, c.hot, c.hotSlots); ok {
+			return v, true
+		}
+		if v, ok := c.take(wid, c.cold, c.coldSlots); ok {
+			return v, true
+		}
+		if closed && c.drained() {
+			return v, false
+		}
+		if c.hot.strategy().Spin(context.Background(), try, c.hot.yield) {
+			continue
+		}
+		c.avail.wait(0, func() bool {
+			return c.closed.Load() != 0 ||
+				c.hot.canRead(c.hot.rReserve.Load()) ||
+				c.cold.canRead(c.cold.rReserve.Load())
+		})
+	}
+}
+
+// drained reports whether every published item was taken.
+func (c *Chain[T]) drained() bool {
+	return c.hot.rReserve.Load() >= c.hot.wCommit.Load() &&
+		c.cold.rReserve.Load() >= c.cold.wCommit.Load()
+}
+
+func (c *Chain[T]) take(wid int, rb *RingBuffer, slots []T) (T, bool) {
+	var zero T
+	id, ok := rb.tryReserveRead(wid)
+	if !ok {
+		return zero, false
+	}
+	idx := rb.BufferIndex(id)
+	v := slots[idx]
+	slots[idx] = zero
+	rb.CommitRead(wid, id)
+	return v, true
+}
+
+// Stats returns the Stats of both rings.
+// It is goroutine-safe.
+func (c *Chain[T]) Stats() ChainStats {
+	s := ChainStats{Hot: c.hot.Stats(), Cold: c.cold.Stats()}
+	s.Len = c.hot.ApproxLen() + c.cold.ApproxLen()
+	return s
+}
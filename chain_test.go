@@ -0,0 +1,68 @@
+package ringbuffer
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+// TestChainOverflowOrder overflows the hot ring into the cold one and
+// checks a reader gets every item in order.
+func TestChainOverflowOrder(t *testing.T) {
+	defer parallel()()
+	c := MustNewChain[int](4, 32)
+	for i := 0; i < 20; i++ {
+		c.Put(i)
+	}
+	if s := c.Stats(); s.Len != 20 || s.Hot.WriteCommit != 4 {
+		t.Fatalf("Stats() = %+v after 20 puts", s)
+	}
+	for i := 0; i < 20; i++ {
+		if v, ok := c.Get(0); !ok || v != i {
+			t.Fatalf("Get() = %d, %v, want %d", v, ok, i)
+		}
+	}
+	c.Put(20) //cold ring drained, back to the hot one
+	if v, _ := c.Get(0); v != 20 || c.Stats().Hot.WriteCommit != 5 {
+		t.Fatalf("Get() = %d, hot write commit %d", v, c.Stats().Hot.WriteCommit)
+	}
+}
+
+func TestChainConcurrent(t *testing.T) {
+	defer parallel()()
+	const writers, n = 4, 2000
+	c := MustNewChain[int](4, 16)
+	var wg sync.WaitGroup
+	wg.Add(writers)
+	for w := 0; w < writers; w++ {
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				c.Put(w*n + i)
+			}
+		}(w)
+	}
+	go func() {
+		wg.Wait()
+		c.Close()
+	}()
+	next := make([]int, writers)
+	count := 0
+	for {
+		v, ok := c.Get(0)
+		if !ok {
+			break
+		}
+		if w := v / n; v%n != next[w] {
+			t.Fatalf("writer %d: got item %d, want %d", w, v%n, next[w])
+		}
+		next[v/n]++
+		count++
+	}
+	if count != writers*n {
+		t.Fatalf("got %d items before the Chain was drained, want %d", count, writers*n)
+	}
+	if err := c.Put(0); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Put after Close: %v", err)
+	}
+}
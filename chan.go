@@ -0,0 +1,64 @@
+package ringbuffer
+
+import "context"
+
+// ToChan pumps the items of t into a channel of capacity size, so the ring
+// can feed select based code. The channel is closed once t is closed, see
+// WithDrainOnClose, or ctx is done; an item taken from t when ctx is done
+// is dropped. A consumer not keeping up holds back the pump, and the ring
+// fills up behind it.
+func (t *TypedRingBuffer[T]) ToChan(ctx context.Context, size int) <-chan T {
+	ch := make(chan T, size)
+	t.rb.Go("tochan", 0, func() {
+		defer close(ch)
+		for {
+			id, err := t.rb.ReserveReadContext(ctx, -1)
+			if err != nil {
+				return
+			}
+			v := *t.Slot(id)
+			t.CommitRead(-1, id)
+			select {
+			case ch <- v:
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+	return ch
+}
+
+// FromChan pumps the items received from ch into t, so select based
+// producers can feed the ring. A full ring holds back the pump, and ch
+// fills up behind it. The returned channel gets nil once ch is closed and
+// drained, ErrClosed once t is closed, or ctx.Err() once ctx is done; an
+// item received when t is closed or ctx is done is dropped. FromChan never
+// closes t.
+func (t *TypedRingBuffer[T]) FromChan(ctx context.Context, ch <-chan T) <-chan error {
+	done := make(chan error, 1)
+	t.rb.Go("fromchan", 0, func() {
+		done <- t.pump(ctx, ch)
+	})
+	return done
+}
+
+func (t *TypedRingBuffer[T]) pump(ctx context.Context, ch <-chan T) error {
+	for {
+		var v T
+		var ok bool
+		select {
+		case v, ok = <-ch:
+			if !ok {
+				return nil
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		id, err := t.rb.ReserveWriteContext(ctx, -1)
+		if err != nil {
+			return err
+		}
+		*t.reuse(id) = v
+		t.rb.CommitWrite(-1, id)
+	}
+}
@@ -0,0 +1,62 @@
+package ringbuffer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestChanBridges(t *testing.T) {
+	defer parallel()()
+	const n = 100
+	ring := MustNewTypedRingBuffer[int](4, WithDrainOnClose())
+	in := make(chan int)
+	done := ring.FromChan(context.Background(), in)
+	out := ring.ToChan(context.Background(), 2)
+	go func() {
+		for i := 0; i < n; i++ {
+			in <- i
+		}
+		close(in)
+	}()
+	for i := 0; i < n; i++ {
+		if v := <-out; v != i {
+			t.Fatalf("got %d, want %d", v, i)
+		}
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("FromChan after its channel closed: %v", err)
+	}
+	ring.RingBuffer().Close()
+	select {
+	case v, ok := <-out:
+		if ok {
+			t.Fatalf("got %d after Close", v)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("ToChan channel still open after Close")
+	}
+}
+
+func TestChanBridgesContext(t *testing.T) {
+	defer parallel()()
+	ring := MustNewTypedRingBuffer[int](4)
+	ctx, cancel := context.WithCancel(context.Background())
+	out := ring.ToChan(ctx, 0)
+	done := ring.FromChan(ctx, make(chan int))
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("FromChan after cancel: %v", err)
+	}
+	if _, ok := <-out; ok {
+		t.Fatal("ToChan channel still open after cancel")
+	}
+
+	ring.RingBuffer().Close()
+	in := make(chan int, 1)
+	in <- 1
+	if err := <-ring.FromChan(context.Background(), in); !errors.Is(err, ErrClosed) {
+		t.Fatalf("FromChan into a closed ring: %v", err)
+	}
+}
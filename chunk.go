@@ -0,0 +1,100 @@
+package ringbuffer
+
+import (
+	"context"
+	"sync"
+)
+
+// WithChunk makes a Writer pre-claim n write ids at a time from the ring,
+// at least one, and hand them out to its own ReserveWrite calls, so writers
+// contend on the shared write reserve once every n publishes instead of on
+// every one. Use one Writer per goroutine.
+// Ids of a chunk are published in order with those of the other writers:
+// readers wait for the ids a Writer claimed and did not use yet, so an idle
+// Writer should Flush. Close gives up the unused ids of every Writer, as
+// tombstones. Chunks are claimed ahead of free slots, whatever
+// WithReservationCap or the OnFull policy, and their writes block while the
+// ring is full.
+func WithChunk(n int) WriterOption {
+	return func(w *Writer) {
+		if n < 1 {
+			n = 1
+		}
+		w.chunk = uint64(n)
+	}
+}
+
+// chunkedWriters are the Writers of a ring created WithChunk.
+type chunkedWriters struct {
+	mu sync.Mutex
+	ws []*Writer
+}
+
+// add registers w, for Close to give up its unused ids.
+func (c *chunkedWriters) add(w *Writer) {
+	c.mu.Lock()
+	c.ws = append(c.ws, w)
+	c.mu.Unlock()
+}
+
+// flushAll gives up the unused ids of every Writer, see Close.
+func (c *chunkedWriters) flushAll() {
+	c.mu.Lock()
+	ws := c.ws
+	c.mu.Unlock()
+	for _, w := range ws {
+		w.Flush()
+	}
+}
+
+// reserveChunked takes the next id of the chunk of w, claiming a new chunk
+// if it is used up, and waits for its slot.
+func (w *Writer) reserveChunked() (id uint64, err error) {
+	rb := w.rb
+	if rb.gate != nil {
+		<-rb.gate
+	}
+	if rb.closed.Load() != 0 {
+		return 0, ErrClosed
+	}
+	if rb.credits != nil {
+		if err := rb.credits.take(context.Background(), rb, 1); err != nil {
+			return 0, err
+		}
+		defer func() {
+			if err != nil {
+				rb.credits.refund(1)
+			}
+		}()
+	}
+	w.mu.Lock()
+	if w.next == w.end {
+		lo := rb.takeWrite(w.chunk)
+		if rb.closed.Load() != 0 { //closed meanwhile, drain readers may not wait for the chunk
+			w.mu.Unlock()
+			return 0, rb.abortClosed(w.wid, lo, lo+w.chunk-1)
+		}
+		w.next, w.end = lo, lo+w.chunk
+	}
+	id = w.next
+	w.next++
+	w.mu.Unlock()
+	if err := rb.awaitWrite(w.wid, id); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// Flush gives up the ids w claimed under WithChunk and did not use yet, as
+// tombstones, so that readers don't wait for them. The next ReserveWrite
+// claims a new chunk.
+// It is goroutine-safe.
+func (w *Writer) Flush() {
+	w.mu.Lock()
+	lo, hi := w.next, w.end
+	w.next = w.end
+	w.mu.Unlock()
+	for id := lo; id < hi; id++ {
+		w.rb.AbortWrite(w.wid, id)
+	}
+}
@@ -0,0 +1,57 @@
+package ringbuffer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWriterChunk(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(16)
+	a := rb.NewWriter(0, WithChunk(4))
+	b := rb.NewWriter(1, WithChunk(4))
+	var ids []uint64
+	for _, w := range []*Writer{a, a, b} {
+		id, err := w.ReserveWrite()
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.CommitWrite(id)
+		ids = append(ids, id)
+	}
+	if ids[0] != 0 || ids[1] != 1 || ids[2] != 4 {
+		t.Fatalf("ids %v, want [0 1 4] from chunks [0, 4) and [4, 8)", ids)
+	}
+	if s := rb.Stats(); s.WriteReserve != 8 {
+		t.Fatalf("write reserve %d, want two chunks claimed", s.WriteReserve)
+	}
+
+	read := make(chan uint64, 3)
+	go func() {
+		for i := 0; i < 3; i++ {
+			id, _ := rb.ReserveRead(2)
+			rb.CommitRead(2, id)
+			read <- id
+		}
+	}()
+	<-read
+	<-read
+	select {
+	case id := <-read:
+		t.Fatalf("read %d past ids claimed by a", id)
+	case <-time.After(20 * time.Millisecond):
+	}
+	a.Flush()
+	if id := <-read; id != 4 {
+		t.Fatalf("read %d after Flush, want 4", id)
+	}
+
+	//Close gives up the unused ids of b
+	rb.Close()
+	if s := rb.Stats(); s.WriteCommit != s.WriteReserve {
+		t.Fatalf("write commit %d, reserve %d after Close", s.WriteCommit, s.WriteReserve)
+	}
+	if _, err := b.ReserveWrite(); err == nil {
+		t.Fatal("ReserveWrite from a chunk after Close")
+	}
+}
@@ -0,0 +1,81 @@
+package ringbuffer
+
+// closed states
+const (
+	closedNow   = 1 // readers stop at once
+	closedDrain = 2 // readers stop once the ring is drained
+)
+
+// WithDrainOnClose lets readers take the items published before Close,
+// and those of writers that had reserved before it, before they get
+// ErrClosed. Without it, readers get ErrClosed as soon as the ring is
+// closed.
+func WithDrainOnClose() Option {
+	return func(rb *RingBuffer) {
+		rb.drainOnClose = true
+	}
+}
+
+// Close signals end of stream. Waiting and later ReserveWrite calls return
+// ErrClosed, and so do ReserveRead calls, after draining the ring if
+// WithDrainOnClose is set. Writers holding a reservation may still commit
+// it. Closing a closed ring returns ErrClosed.
+// It is goroutine-safe.
+func (rb *RingBuffer) Close() error {
+	if rb.drainOnClose {
+		return rb.close(closedDrain)
+	}
+	return rb.close(closedNow)
+}
+
+// close closes the ring in state.
+func (rb *RingBuffer) close(state int32) error {
+	if !rb.closed.CompareAndSwap(0, state) {
+		return ErrClosed
+	}
+	rb.chunked.flushAll()
+	for _, w := range []*waitList{rb.waitWriteR, rb.waitReadR, rb.waitWriteC, rb.waitReadC, rb.waitBarrier} {
+		w.wakeAll()
+	}
+	if rb.credits != nil {
+		rb.credits.wait.wakeAll()
+	}
+	if rb.broadcast != nil {
+		rb.broadcast.waitDeps.wakeAll()
+	}
+	return nil
+}
+
+// Closed reports whether Close was called.
+// It is goroutine-safe.
+func (rb *RingBuffer) Closed() bool {
+	return rb.closed.Load() != 0
+}
+
+// readClosed reports whether a reader of id gives up with ErrClosed: at
+// once without drain, else once no write reservation covers id, since
+// writers reserving concurrently with Close re-check it and abort.
+func (rb *RingBuffer) readClosed(id uint64) bool {
+	switch rb.closed.Load() {
+	case 0:
+		return false
+	case closedNow:
+		return true
+	}
+	return id >= rb.wReserve.Load()
+}
+
+// readersClosed reports whether the ring is closed without drain.
+func (rb *RingBuffer) readersClosed() bool {
+	return rb.closed.Load() == closedNow
+}
+
+// abortClosed gives up write ids [lo, hi] taken by a writer that found the
+// ring closed, as tombstones, so they don't block the commits of the
+// writers still holding earlier ids.
+func (rb *RingBuffer) abortClosed(wid int, lo, hi uint64) error {
+	for id := lo; id <= hi; id++ {
+		rb.AbortWrite(wid, id)
+	}
+	return ErrClosed
+}
@@ -0,0 +1,115 @@
+package ringbuffer
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestCloseWakesWaiters closes a ring under a writer waiting for room and
+// a reader waiting for an item of another ring.
+func TestCloseWakesWaiters(t *testing.T) {
+	defer parallel()()
+	full, empty := MustNewRingBuffer(1), MustNewRingBuffer(1)
+	id, _ := full.ReserveWrite(0)
+	full.CommitWrite(0, id)
+	errs := make(chan error, 2)
+	go func() {
+		_, err := full.ReserveWrite(0)
+		errs <- err
+	}()
+	go func() {
+		_, err := empty.ReserveRead(0)
+		errs <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	full.Close()
+	empty.Close()
+	for i := 0; i < 2; i++ {
+		if err := <-errs; !errors.Is(err, ErrClosed) {
+			t.Fatalf("waiter woken by Close: %v", err)
+		}
+	}
+	if _, err := full.ReserveRead(0); !errors.Is(err, ErrClosed) {
+		t.Fatalf("ReserveRead without drain: %v", err)
+	}
+	if err := full.Close(); !errors.Is(err, ErrClosed) {
+		t.Fatalf("second Close: %v", err)
+	}
+	if !full.Closed() {
+		t.Fatal("Closed() is false")
+	}
+}
+
+// TestCloseDrain pins that with WithDrainOnClose readers get the items
+// published before Close and the reservation committed after it.
+func TestCloseDrain(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(4, WithDrainOnClose())
+	first, _ := rb.ReserveWrite(0)
+	rb.CommitWrite(0, first)
+	held, _ := rb.ReserveWrite(1)
+	rb.Close()
+	if _, err := rb.ReserveWrite(0); !errors.Is(err, ErrClosed) {
+		t.Fatalf("ReserveWrite after Close: %v", err)
+	}
+	if err := rb.CommitWrite(1, held); err != nil {
+		t.Fatalf("CommitWrite of a reservation held over Close: %v", err)
+	}
+	for _, want := range []uint64{first, held} {
+		id, err := rb.ReserveRead(0)
+		if err != nil || id != want {
+			t.Fatalf("ReserveRead() = %d, %v, want %d", id, err, want)
+		}
+		rb.CommitRead(0, id)
+	}
+	if _, err := rb.ReserveRead(0); !errors.Is(err, ErrClosed) {
+		t.Fatalf("ReserveRead of a drained ring: %v", err)
+	}
+}
+
+// TestCloseDrainConcurrent closes a ring under writers and readers and
+// checks readers drain exactly the committed items.
+func TestCloseDrainConcurrent(t *testing.T) {
+	defer parallel()()
+	const writers, readers = 4, 2
+	rb := MustNewRingBuffer(8, WithDrainOnClose())
+	var committed, read atomic.Int64
+	var wg sync.WaitGroup
+	wg.Add(writers + readers)
+	for w := 0; w < writers; w++ {
+		go func(w int) {
+			defer wg.Done()
+			for {
+				id, err := rb.ReserveWrite(w)
+				if err != nil {
+					return
+				}
+				if rb.CommitWrite(w, id) == nil {
+					committed.Add(1)
+				}
+			}
+		}(w)
+	}
+	for r := 0; r < readers; r++ {
+		go func(r int) {
+			defer wg.Done()
+			for {
+				id, err := rb.ReserveRead(r)
+				if err != nil {
+					return
+				}
+				rb.CommitRead(r, id)
+				read.Add(1)
+			}
+		}(r)
+	}
+	time.Sleep(20 * time.Millisecond)
+	rb.Close()
+	wg.Wait()
+	if read.Load() != committed.Load() {
+		t.Fatalf("read %d items of %d committed", read.Load(), committed.Load())
+	}
+}
@@ -0,0 +1,246 @@
+// Command ringviz renders ring debug events as an HTML timeline.
+//
+// It reads the JSON lines written by ringbuffer.JSONSink, from the files
+// given as arguments or from stdin, and draws one lane per ring and worker
+// id with a bar per operation from its start to its "done" event. Worker id
+// -1, shared by any number of goroutines, gets as many lanes as it has
+// operations at once. Bars are coloured by op; retries are marked with
+// ticks, so contended stretches stand out at a glance.
+//
+//	ringviz -o trace.html events.jsonl
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"ringbuffer"
+)
+
+var (
+	output = flag.String("o", "", "output file, stdout if empty")
+	width  = flag.Int("width", 1600, "timeline width in pixels")
+)
+
+const (
+	laneHeight = 20
+	labelWidth = 140
+)
+
+// ops are the ops in legend order.
+var ops = []string{
+	ringbuffer.OpReserveWrite, ringbuffer.OpReserveWriteN,
+	ringbuffer.OpCommitWrite, ringbuffer.OpCommitWriteN,
+	ringbuffer.OpReserveRead, ringbuffer.OpReserveReadN,
+	ringbuffer.OpCommitRead, ringbuffer.OpCommitReadN,
+}
+
+var colors = map[string]string{
+	ringbuffer.OpReserveWrite:  "#4e79a7",
+	ringbuffer.OpReserveWriteN: "#76b7b2",
+	ringbuffer.OpCommitWrite:   "#59a14f",
+	ringbuffer.OpCommitWriteN:  "#8cd17d",
+	ringbuffer.OpReserveRead:   "#f28e2b",
+	ringbuffer.OpReserveReadN:  "#edc948",
+	ringbuffer.OpCommitRead:    "#e15759",
+	ringbuffer.OpCommitReadN:   "#ff9d9a",
+}
+
+type lane struct {
+	ring string
+	wid  int
+	sub  int // of the concurrent operations of a negative wid
+}
+
+// assignLanes returns the lane of every event. The operations of a worker
+// id run one at a time and share its lane. A negative wid is used by any
+// number of goroutines at once, so each of its operations takes the first
+// lane of the ring free over its span, and its try events the lane of the
+// operation they fall in.
+func assignLanes(events []ringbuffer.Event) []lane {
+	type span struct {
+		start, end time.Time
+		op         string
+		sub        int
+	}
+	lanes := make([]lane, len(events))
+	var shared []int
+	for i, e := range events {
+		lanes[i] = lane{e.Ring, e.Wid, 0}
+		if e.Wid < 0 && e.Phase == ringbuffer.PhaseDone {
+			shared = append(shared, i)
+		}
+	}
+	start := func(i int) time.Time { return events[i].Time.Add(-events[i].Waited) }
+	sort.SliceStable(shared, func(a, b int) bool { return start(shared[a]).Before(start(shared[b])) })
+	ends := map[lane][]time.Time{} //per ring and wid, end of the last operation of each lane
+	spans := map[lane][]span{}
+	for _, i := range shared {
+		e, k := events[i], lane{events[i].Ring, events[i].Wid, 0}
+		s := start(i)
+		busy := ends[k]
+		sub := len(busy)
+		for j, end := range busy {
+			if !end.After(s) {
+				sub = j
+				break
+			}
+		}
+		if sub == len(busy) {
+			busy = append(busy, e.Time)
+		} else {
+			busy[sub] = e.Time
+		}
+		ends[k] = busy
+		spans[k] = append(spans[k], span{s, e.Time, e.Op, sub})
+		lanes[i].sub = sub
+	}
+	for i, e := range events {
+		if e.Wid >= 0 || e.Phase == ringbuffer.PhaseDone {
+			continue
+		}
+		for _, sp := range spans[lane{e.Ring, e.Wid, 0}] {
+			if sp.op == e.Op && !e.Time.Before(sp.start) && !e.Time.After(sp.end) {
+				lanes[i].sub = sp.sub
+				break
+			}
+		}
+	}
+	return lanes
+}
+
+func main() {
+	flag.Parse()
+	var events []ringbuffer.Event
+	if flag.NArg() == 0 {
+		events = read(os.Stdin, events)
+	}
+	for _, name := range flag.Args() {
+		f, err := os.Open(name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		events = read(f, events)
+		f.Close()
+	}
+	if len(events) == 0 {
+		log.Fatal("ringviz: no events")
+	}
+
+	w := io.Writer(os.Stdout)
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		w = f
+	}
+	bw := bufio.NewWriter(w)
+	render(bw, events)
+	if err := bw.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func read(r io.Reader, events []ringbuffer.Event) []ringbuffer.Event {
+	dec := json.NewDecoder(r)
+	for {
+		var e ringbuffer.Event
+		if err := dec.Decode(&e); err == io.EOF {
+			return events
+		} else if err != nil {
+			log.Fatal(err)
+		}
+		events = append(events, e)
+	}
+}
+
+func render(w io.Writer, events []ringbuffer.Event) {
+	start, end := events[0].Time, events[0].Time
+	laneOf := assignLanes(events)
+	lanes := map[lane]int{}
+	var order []lane
+	for i, e := range events {
+		if s := e.Time.Add(-e.Waited); s.Before(start) {
+			start = s
+		}
+		if e.Time.After(end) {
+			end = e.Time
+		}
+		l := laneOf[i]
+		if _, ok := lanes[l]; !ok {
+			lanes[l] = 0
+			order = append(order, l)
+		}
+	}
+	sort.Slice(order, func(i, j int) bool {
+		if order[i].ring != order[j].ring {
+			return order[i].ring < order[j].ring
+		}
+		if order[i].wid != order[j].wid {
+			return order[i].wid < order[j].wid
+		}
+		return order[i].sub < order[j].sub
+	})
+	for i, l := range order {
+		lanes[l] = i
+	}
+	span := end.Sub(start)
+	if span <= 0 {
+		span = 1
+	}
+	x := func(t time.Time) float64 {
+		return labelWidth + float64(t.Sub(start))*float64(*width)/float64(span)
+	}
+
+	height := len(order)*laneHeight + 40
+	fmt.Fprintf(w, "<!DOCTYPE html>\n<html><head><meta charset=\"utf-8\"><title>ringviz</title></head><body>\n")
+	fmt.Fprintf(w, "<p>%d events, %s from %s</p>\n", len(events), span, start.Format(time.RFC3339Nano))
+	fmt.Fprintf(w, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" font-family=\"monospace\" font-size=\"11\">\n",
+		labelWidth+*width+10, height)
+	for i, l := range order {
+		y := i * laneHeight
+		label := fmt.Sprintf("%s wid=%d", l.ring, l.wid)
+		if l.wid < 0 {
+			label += fmt.Sprintf("#%d", l.sub)
+		}
+		fmt.Fprintf(w, "<text x=\"4\" y=\"%d\">%s</text>\n", y+14, html.EscapeString(label))
+		fmt.Fprintf(w, "<line x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\" stroke=\"#ddd\"/>\n", labelWidth, y+laneHeight, labelWidth+*width, y+laneHeight)
+	}
+	for i, e := range events {
+		y := lanes[laneOf[i]] * laneHeight
+		switch e.Phase {
+		case ringbuffer.PhaseDone:
+			x0, x1 := x(e.Time.Add(-e.Waited)), x(e.Time)
+			if x1-x0 < 1 {
+				x1 = x0 + 1
+			}
+			color := colors[e.Op]
+			if color == "" {
+				color = "#999"
+			}
+			fmt.Fprintf(w, "<rect x=\"%.1f\" y=\"%d\" width=\"%.1f\" height=\"%d\" fill=\"%s\"><title>%s id=%d tries=%d waited=%s rR=%d rC=%d wR=%d wC=%d</title></rect>\n",
+				x0, y+3, x1-x0, laneHeight-6, color, html.EscapeString(e.Op), e.ID, e.Try, e.Waited, e.RReserve, e.RCommit, e.WReserve, e.WCommit)
+		case ringbuffer.PhaseTry:
+			if e.Try > 1 {
+				fmt.Fprintf(w, "<line x1=\"%.1f\" y1=\"%d\" x2=\"%.1f\" y2=\"%d\" stroke=\"#000\" stroke-opacity=\"0.4\"/>\n",
+					x(e.Time), y+1, x(e.Time), y+laneHeight-1)
+			}
+		}
+	}
+	y := len(order)*laneHeight + 20
+	for i, op := range ops {
+		fmt.Fprintf(w, "<rect x=\"%d\" y=\"%d\" width=\"10\" height=\"10\" fill=\"%s\"/><text x=\"%d\" y=\"%d\">%s</text>\n",
+			labelWidth+i*110, y, colors[op], labelWidth+i*110+14, y+9, op)
+	}
+	fmt.Fprintf(w, "</svg>\n</body></html>\n")
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"ringbuffer"
+)
+
+func done(ring, op string, wid int, start time.Time, from, to time.Duration) ringbuffer.Event {
+	return ringbuffer.Event{
+		Time:   start.Add(to),
+		Ring:   ring,
+		Op:     op,
+		Phase:  ringbuffer.PhaseDone,
+		Wid:    wid,
+		Waited: to - from,
+	}
+}
+
+// TestAssignLanes checks overlapping operations of wid -1 get lanes of
+// their own, and the operations of a worker id share its lane.
+func TestAssignLanes(t *testing.T) {
+	t0 := time.Unix(0, 0)
+	events := []ringbuffer.Event{
+		done("r", ringbuffer.OpReserveWrite, -1, t0, 0, 10),
+		done("r", ringbuffer.OpReserveWrite, -1, t0, 5, 15), //overlaps the first
+		done("r", ringbuffer.OpCommitWrite, -1, t0, 12, 20), //first lane free again
+		done("r", ringbuffer.OpReserveRead, 0, t0, 0, 10),
+		done("r", ringbuffer.OpReserveRead, 0, t0, 5, 15),
+		{Time: t0.Add(7), Ring: "r", Op: ringbuffer.OpReserveWrite, Phase: ringbuffer.PhaseTry, Wid: -1, Try: 2},
+	}
+	got := assignLanes(events)
+	want := []int{0, 1, 0, 0, 0, 0}
+	for i, l := range got {
+		if l.sub != want[i] || l.wid != events[i].Wid {
+			t.Fatalf("event %d in lane %+v, want sub lane %d", i, l, want[i])
+		}
+	}
+}
+
+func TestRenderBatchOps(t *testing.T) {
+	t0 := time.Unix(0, 0)
+	var events []ringbuffer.Event
+	for i, op := range ops {
+		events = append(events, done("r", op, i, t0, time.Duration(i), time.Duration(i+1)))
+	}
+	var out bytes.Buffer
+	render(&out, events)
+	for _, op := range ops {
+		c, ok := colors[op]
+		if !ok {
+			t.Fatalf("no color for %s", op)
+		}
+		if n := strings.Count(out.String(), `fill="`+c+`"`); n != 2 { //bar and legend
+			t.Fatalf("%s drawn %d times in %s", op, n, c)
+		}
+	}
+}
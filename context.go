@@ -0,0 +1,181 @@
+package ringbuffer
+
+import (
+	"context"
+	"time"
+)
+
+// ReserveWriteContext reserves the next write id like ReserveWrite, but
+// gives up and returns ctx.Err() once ctx is done, so a blocked producer
+// can be unblocked on shutdown. No id is taken until a slot is free, so a
+// cancelled call leaves nothing to abort. It returns ErrClosed once the
+// ring is closed.
+// It is goroutine-safe.
+func (rb *RingBuffer) ReserveWriteContext(ctx context.Context, wid int) (id uint64, err error) {
+	if rb.instr != nil {
+		defer rb.instrumented(OpReserveWrite, wid, time.Now(), &id, &err)
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if rb.contractChecks && rb.singleWriter {
+		checkOwner(&rb.writerOwner, "writer")
+	}
+	if rb.gate != nil {
+		select {
+		case <-rb.gate:
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+	if rb.credits != nil {
+		if err := rb.credits.take(ctx, rb, 1); err != nil {
+			return 0, err
+		}
+		id, err := rb.reserveWriteContext(ctx, wid)
+		if err != nil {
+			rb.credits.refund(1)
+		}
+		return id, err
+	}
+	return rb.reserveWriteContext(ctx, wid)
+}
+
+func (rb *RingBuffer) reserveWriteContext(ctx context.Context, wid int) (uint64, error) {
+	if id, ok, err := rb.tryReserveWriteFull(wid); ok || err != nil {
+		return id, err
+	}
+
+	defer rb.watch(ctx, rb.waitWriteR)()
+	defer rb.waitEnd(OpReserveWrite, wid, time.Now())
+	rb.observeFull(wid)
+	for try := 1; ; try++ {
+		c := rb.wCommit.Load()
+		if id, ok, err := rb.tryReserveWriteFull(wid); ok || err != nil {
+			return id, err
+		}
+		if rb.closed.Load() != 0 {
+			return 0, ErrClosed
+		}
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		if rb.strategy().Spin(ctx, try, rb.yield) {
+			continue
+		}
+		next := rb.wReserve.Load()
+		rb.waitWriteR.wait(rb.writeNeed(next), func() bool {
+			return ctx.Err() != nil || rb.closed.Load() != 0 ||
+				rb.canWrite(rb.wReserve.Load()) || rb.canOverwrite(c)
+		})
+		rb.observeWakeup(OpReserveWrite, wid)
+	}
+}
+
+// ReserveReadContext reserves the next read id like ReserveRead, but gives
+// up and returns ctx.Err() once ctx is done. No id is taken until one is
+// published, so a cancelled call leaves nothing behind. It returns
+// ErrClosed once the ring is closed, see WithDrainOnClose.
+// It is goroutine-safe.
+func (rb *RingBuffer) ReserveReadContext(ctx context.Context, wid int) (id uint64, err error) {
+	if rb.instr != nil {
+		defer rb.instrumented(OpReserveRead, wid, time.Now(), &id, &err)
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if rb.contractChecks && rb.singleReader {
+		checkOwner(&rb.readerOwner, "reader")
+	}
+	if rb.readersClosed() {
+		return 0, ErrClosed
+	}
+	if id, ok := rb.tryReserveRead(wid); ok {
+		return id, nil
+	}
+
+	defer rb.watch(ctx, rb.waitReadR)()
+	defer rb.waitEnd(OpReserveRead, wid, time.Now())
+	rb.observeEmpty(wid)
+	for try := 1; ; try++ {
+		if id, ok := rb.tryReserveRead(wid); ok {
+			return id, nil
+		}
+		if rb.readClosed(rb.rReserve.Load()) {
+			return 0, ErrClosed
+		}
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		if rb.readStrategy().Spin(ctx, try, rb.yield) {
+			continue
+		}
+		next := rb.rReserve.Load()
+		rb.waitReadR.wait(next+1, func() bool {
+			r := rb.rReserve.Load()
+			return ctx.Err() != nil || rb.canRead(r) || rb.readClosed(r)
+		})
+		rb.observeWakeup(OpReserveRead, wid)
+	}
+}
+
+// watch wakes the waiters of w once ctx is done, until the returned func
+// is called.
+func (rb *RingBuffer) watch(ctx context.Context, w *waitList) func() {
+	if ctx.Done() == nil {
+		return func() {}
+	}
+	stop := context.AfterFunc(ctx, w.wakeAll)
+	return func() { stop() }
+}
+
+// CommitWriteContext commits write id like CommitWrite, but gives up and
+// returns ctx.Err() if ctx is done, leaving id reserved: the caller may try
+// again or AbortWrite it. As CommitWrite never waits, ctx is only checked
+// up front.
+// It is goroutine-safe.
+func (rb *RingBuffer) CommitWriteContext(ctx context.Context, wid int, id uint64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return rb.CommitWrite(wid, id)
+}
+
+// CommitReadContext commits read id like CommitRead, but gives up and
+// returns ctx.Err() once ctx is done while an earlier id is still not
+// committed, so shutdown never hangs on a stuck predecessor. The commit of
+// id then stays pending: id remains reserved, writers don't reuse its slot,
+// and the caller must try again once the predecessor is done.
+// It is goroutine-safe.
+func (rb *RingBuffer) CommitReadContext(ctx context.Context, wid int, id uint64) (err error) {
+	if rb.instr != nil {
+		defer rb.instrumented(OpCommitRead, wid, time.Now(), &id, &err)
+	}
+	if rb.observer != nil {
+		defer rb.observeCommit(OpCommitRead, wid, id, id, &err)
+	}
+	if err := rb.checkCommit(id, &rb.rReserve, &rb.rCommit); err != nil {
+		return err
+	}
+	if rb.relaxedRead {
+		return rb.commitReadRelaxed(id)
+	}
+	if rb.tryCommitRead(id) {
+		return nil
+	}
+	defer rb.watch(ctx, rb.waitReadC)()
+	defer rb.waitEnd(OpCommitRead, wid, time.Now())
+	for try := 1; ; try++ {
+		if rb.tryCommitRead(id) {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if rb.strategy().Spin(ctx, try, rb.yield) {
+			continue
+		}
+		rb.waitReadC.wait(id, func() bool { return ctx.Err() != nil || rb.rCommit.Load() == id })
+		rb.observeWakeup(OpCommitRead, wid)
+	}
+}
@@ -0,0 +1,63 @@
+package ringbuffer
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+)
+
+// WithSingleWriter declares that a single goroutine writes to the ring.
+// Write ids are then claimed with plain stores instead of atomic adds and
+// compare and swap loops, see NewSPMCRingBuffer.
+func WithSingleWriter() Option {
+	return func(rb *RingBuffer) {
+		rb.singleWriter = true
+	}
+}
+
+// WithSingleReader declares that a single goroutine reads from the ring.
+// Read ids are then claimed with plain stores instead of atomic adds and
+// compare and swap loops, see NewMPSCRingBuffer.
+func WithSingleReader() Option {
+	return func(rb *RingBuffer) {
+		rb.singleReader = true
+	}
+}
+
+// WithContractChecks makes ReserveWrite and ReserveRead panic when a second
+// goroutine uses a side declared single by WithSingleWriter or
+// WithSingleReader; such violations otherwise corrupt the ring silently.
+// The first goroutine to use a side owns it. A check costs a goroutine id
+// lookup per reservation, it is meant for tests and debugging.
+func WithContractChecks() Option {
+	return func(rb *RingBuffer) {
+		rb.contractChecks = true
+	}
+}
+
+// checkOwner panics if the calling goroutine is not the owner of a single
+// side, taking ownership if there is none yet.
+func checkOwner(owner *atomic.Int64, side string) {
+	g := goid()
+	if owner.CompareAndSwap(0, g) {
+		return
+	}
+	if o := owner.Load(); o != g {
+		panic(fmt.Sprintf("RingBuffer: single %s contract broken: goroutine %d, owner goroutine %d", side, g, o))
+	}
+}
+
+// goid returns the id of the calling goroutine, from its stack header
+// "goroutine 123 [running]:".
+func goid() int64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i > 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseInt(string(b), 10, 64)
+	return id
+}
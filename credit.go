@@ -0,0 +1,91 @@
+package ringbuffer
+
+import (
+	"context"
+	"math"
+	"sync/atomic"
+)
+
+// credits are the items producers may still publish under WithCredits.
+type credits struct {
+	n    atomic.Int64
+	wait *waitList // writers waiting for a grant
+}
+
+// WithCredits switches rb to consumer driven flow control, for downstream
+// components that signal demand (reactive streams): on top of the ring
+// capacity, producers may only reserve as many ids as consumers granted
+// with Grant. initial credits are granted up front.
+func WithCredits(initial int) Option {
+	return func(rb *RingBuffer) {
+		rb.credits = &credits{wait: newWaitList()}
+		rb.credits.n.Store(int64(initial))
+	}
+}
+
+// Grant gives producers n more write reservations. The ring must be
+// created WithCredits.
+// It is goroutine-safe.
+func (rb *RingBuffer) Grant(n int) {
+	if rb.credits == nil {
+		panic("RingBuffer: Grant needs a ring created WithCredits")
+	}
+	rb.credits.n.Add(int64(n))
+	rb.credits.wait.wake(math.MaxUint64)
+}
+
+// Credits returns the write reservations granted and not used yet, or -1
+// if rb is not created WithCredits.
+// It is goroutine-safe.
+func (rb *RingBuffer) Credits() int {
+	if rb.credits == nil {
+		return -1
+	}
+	return int(rb.credits.n.Load())
+}
+
+// tryTake takes n credits if granted.
+func (c *credits) tryTake(n int64) bool {
+	for {
+		v := c.n.Load()
+		if v < n {
+			return false
+		}
+		if c.n.CompareAndSwap(v, v-n) {
+			return true
+		}
+	}
+}
+
+// refund gives back n credits taken for a reservation that failed.
+func (c *credits) refund(n int64) {
+	c.n.Add(n)
+	c.wait.wake(math.MaxUint64)
+}
+
+// take waits for n credits. It returns ErrClosed once rb is closed, and
+// ctx.Err() once ctx is done.
+func (c *credits) take(ctx context.Context, rb *RingBuffer, n int64) error {
+	if c.tryTake(n) {
+		return nil
+	}
+	defer rb.watch(ctx, c.wait)()
+	ready := func() bool {
+		return c.n.Load() >= n || rb.closed.Load() != 0 || ctx.Err() != nil
+	}
+	for try := 1; ; try++ {
+		if c.tryTake(n) {
+			return nil
+		}
+		if rb.closed.Load() != 0 {
+			return ErrClosed
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if rb.strategy().Spin(ctx, try, rb.yield) {
+			continue
+		}
+		c.wait.wait(0, ready)
+	}
+}
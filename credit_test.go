@@ -0,0 +1,109 @@
+package ringbuffer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestCreditsBoundWrites pins that writers reserve no more ids than
+// granted, whatever the room in the ring.
+func TestCreditsBoundWrites(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(8, WithCredits(2))
+	for i := 0; i < 2; i++ {
+		id, _ := rb.ReserveWrite(0)
+		rb.CommitWrite(0, id)
+	}
+	if got := rb.Credits(); got != 0 {
+		t.Fatalf("Credits() = %d after 2 writes, want 0", got)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := rb.ReserveWriteContext(ctx, 0); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ReserveWriteContext without credits: %v", err)
+	}
+	if got := rb.Credits(); got != 0 {
+		t.Fatalf("Credits() = %d after a failed reservation, want 0", got)
+	}
+	done := make(chan error)
+	go func() {
+		_, err := rb.ReserveWrite(0)
+		done <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	rb.Grant(1)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if got := MustNewRingBuffer(2).Credits(); got != -1 {
+		t.Fatalf("Credits() = %d without WithCredits", got)
+	}
+}
+
+func TestCreditsClose(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(8, WithCredits(0))
+	done := make(chan error)
+	go func() {
+		_, err := rb.ReserveWrite(0)
+		done <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	rb.Close()
+	if err := <-done; !errors.Is(err, ErrClosed) {
+		t.Fatalf("ReserveWrite waiting for credits at Close: %v", err)
+	}
+}
+
+// TestCreditsRefund checks a batch reservation given up at Close returns
+// its credits.
+func TestCreditsRefund(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(2, WithCredits(4))
+	lo, hi, _ := rb.ReserveWriteN(0, 2)
+	rb.CommitWriteN(0, lo, hi)
+	done := make(chan error)
+	go func() {
+		_, _, err := rb.ReserveWriteN(0, 2)
+		done <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	rb.Close()
+	if err := <-done; !errors.Is(err, ErrClosed) {
+		t.Fatalf("ReserveWriteN waiting for slots at Close: %v", err)
+	}
+	if got := rb.Credits(); got != 2 {
+		t.Fatalf("Credits() = %d after Close, want 2", got)
+	}
+}
+
+// TestCreditsConcurrent has a consumer grant one credit per item read and
+// checks the producers never run more than the granted window ahead.
+func TestCreditsConcurrent(t *testing.T) {
+	defer parallel()()
+	const writers, n, window = 4, 1000, 3
+	rb := MustNewRingBuffer(16, WithCredits(window))
+	var wg sync.WaitGroup
+	wg.Add(writers)
+	for w := 0; w < writers; w++ {
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				id, _ := rb.ReserveWrite(w)
+				rb.CommitWrite(w, id)
+			}
+		}(w)
+	}
+	for i := 0; i < writers*n; i++ {
+		id, _ := rb.ReserveRead(0)
+		rb.CommitRead(0, id)
+		if ahead := rb.Stats().WriteReserve - (id + 1); ahead > window {
+			t.Fatalf("writers reserved %d ids past a window of %d", ahead, window)
+		}
+		rb.Grant(1)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,92 @@
+package ringbuffer
+
+import (
+	"container/list"
+	"sync"
+)
+
+// DedupPublisher publishes items keyed by a caller given id, dropping any
+// item whose key was published within the last window keys, so retrying
+// producers (e.g. network ingesters) don't inject duplicates downstream.
+// Keys older than the window are forgotten, least recently seen first.
+type DedupPublisher[K comparable, T any] struct {
+	rb    *RingBuffer
+	store func(id uint64, v T)
+
+	mu     sync.Mutex
+	window int
+	seen   map[K]*list.Element
+	lru    list.List // of K, most recent first
+	dups   uint64
+}
+
+// NewDedupPublisher returns a DedupPublisher on rb remembering window keys.
+// store is called to put item v into the slot of id, between reservation
+// and commit.
+func NewDedupPublisher[K comparable, T any](rb *RingBuffer, window int, store func(id uint64, v T)) *DedupPublisher[K, T] {
+	return &DedupPublisher[K, T]{
+		rb:     rb,
+		store:  store,
+		window: window,
+		seen:   make(map[K]*list.Element, window),
+	}
+}
+
+// Publish publishes v unless key is in the window, and reports whether it
+// did. It waits while the ring is full, and returns ErrClosed once the ring
+// is closed. A key whose item was not published is forgotten, so a retry
+// with it is not dropped.
+// It is goroutine-safe; of concurrent calls with the same key, one wins.
+func (p *DedupPublisher[K, T]) Publish(wid int, key K, v T) (bool, error) {
+	e := p.admit(key)
+	if e == nil {
+		return false, nil
+	}
+	id, err := p.rb.ReserveWrite(wid)
+	if err != nil {
+		p.forget(e)
+		return false, err
+	}
+	p.store(id, v)
+	return true, p.rb.CommitWrite(wid, id)
+}
+
+// admit records key and returns its window entry, or nil if key is
+// already in the window.
+func (p *DedupPublisher[K, T]) admit(key K) *list.Element {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if e, ok := p.seen[key]; ok {
+		p.lru.MoveToFront(e)
+		p.dups++
+		return nil
+	}
+	e := p.lru.PushFront(key)
+	p.seen[key] = e
+	if p.lru.Len() > p.window {
+		oldest := p.lru.Back()
+		p.lru.Remove(oldest)
+		delete(p.seen, oldest.Value.(K))
+	}
+	return e
+}
+
+// forget drops entry e of admit from the window, unless it was evicted
+// already.
+func (p *DedupPublisher[K, T]) forget(e *list.Element) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key := e.Value.(K)
+	if p.seen[key] == e {
+		p.lru.Remove(e)
+		delete(p.seen, key)
+	}
+}
+
+// Duplicates returns the number of items dropped as duplicates.
+// It is goroutine-safe.
+func (p *DedupPublisher[K, T]) Duplicates() uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.dups
+}
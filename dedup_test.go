@@ -0,0 +1,58 @@
+package ringbuffer
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestDedupPublisher(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(8)
+	vals := make([]string, rb.Slots())
+	p := NewDedupPublisher[int](rb, 2, func(id uint64, v string) {
+		vals[rb.BufferIndex(id)] = v
+	})
+	var got []string
+	for _, k := range []int{1, 2, 1, 3, 1} {
+		ok, err := p.Publish(0, k, string(rune('a'+k)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok {
+			got = append(got, vals[rb.BufferIndex(rb.Stats().WriteCommit-1)])
+		}
+	}
+	//1 and 2, 1 dropped, 3 evicts 2, 1 still in the window
+	if want := "[b c d]"; fmt.Sprint(got) != want {
+		t.Fatalf("published %v, want %s", got, want)
+	}
+	if d := p.Duplicates(); d != 2 {
+		t.Fatalf("%d duplicates, want 2", d)
+	}
+	if ok, _ := p.Publish(0, 2, "v"); !ok {
+		t.Fatal("evicted key 2 dropped")
+	}
+	if n := rb.Len(); n != 4 {
+		t.Fatalf("%d items in the ring, want 4", n)
+	}
+}
+
+func TestDedupPublisherForgetsUnpublished(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(4)
+	p := NewDedupPublisher[string](rb, 4, func(uint64, int) {})
+	rb.Close()
+	if ok, err := p.Publish(0, "a", 1); ok || !errors.Is(err, ErrClosed) {
+		t.Fatalf("Publish: %v, %v, want false, ErrClosed", ok, err)
+	}
+	if ok, err := p.Publish(0, "a", 1); !errors.Is(err, ErrClosed) {
+		t.Fatalf("retry: %v, %v, want false, ErrClosed", ok, err)
+	}
+	if d := p.Duplicates(); d != 0 {
+		t.Fatal("retry dropped as a duplicate")
+	}
+	if n := len(p.seen); n != 0 {
+		t.Fatalf("%d keys in the window, want 0", n)
+	}
+}
@@ -0,0 +1,74 @@
+package ringbuffer
+
+import (
+	"math/bits"
+	"sync/atomic"
+)
+
+// DistanceHistogram counts waits by their distance in slots from the gating
+// cursor: bucket i counts distances in [2^i, 2^(i+1)), the last bucket
+// everything beyond.
+type DistanceHistogram [16]uint64
+
+// WaitDistances are the histograms of reserve waits kept by
+// WithWaitDistances.
+// Writers at distance 1 wait on the oldest unread item, the signature of
+// one slow consumer; writers far from the read commit point to a buffer too
+// small for the number of producers.
+type WaitDistances struct {
+	Write DistanceHistogram // write id - last writable id
+	Read  DistanceHistogram // read id - last readable id
+}
+
+// WithWaitDistances records, for every reserve that has to wait, how far
+// it is from the cursor it waits for.
+func WithWaitDistances() Option {
+	return func(rb *RingBuffer) {
+		rb.distances = new(WaitDistances)
+	}
+}
+
+// WaitDistances returns a snapshot of the wait distance histograms, all
+// zero without WithWaitDistances.
+// It is goroutine-safe.
+func (rb *RingBuffer) WaitDistances() WaitDistances {
+	var d WaitDistances
+	if rb.distances == nil {
+		return d
+	}
+	for i := range d.Write {
+		d.Write[i] = atomic.LoadUint64(&rb.distances.Write[i])
+		d.Read[i] = atomic.LoadUint64(&rb.distances.Read[i])
+	}
+	return d
+}
+
+// writeWaited records a wait of write id.
+func (rb *RingBuffer) writeWaited(id uint64) {
+	if rb.distances == nil {
+		return
+	}
+	limit := rb.rCommit.Load() + uint64(rb.size) // first unwritable id
+	if id >= limit {
+		rb.distances.Write.add(id - limit + 1)
+	}
+}
+
+// readWaited records a wait of read id.
+func (rb *RingBuffer) readWaited(id uint64) {
+	if rb.distances == nil {
+		return
+	}
+	limit := rb.wCommit.Load() // first unreadable id
+	if id >= limit {
+		rb.distances.Read.add(id - limit + 1)
+	}
+}
+
+func (h *DistanceHistogram) add(distance uint64) {
+	i := bits.Len64(distance) - 1
+	if i >= len(h) {
+		i = len(h) - 1
+	}
+	atomic.AddUint64(&h[i], 1)
+}
@@ -0,0 +1,58 @@
+package ringbuffer
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDistanceHistogram(t *testing.T) {
+	var h DistanceHistogram
+	for _, d := range []uint64{1, 2, 3, 4, 1 << 20} {
+		h.add(d)
+	}
+	if h[0] != 1 || h[1] != 2 || h[2] != 1 || h[len(h)-1] != 1 {
+		t.Fatalf("%v", h)
+	}
+}
+
+func TestWaitDistances(t *testing.T) {
+	defer parallel()()
+	if d := MustNewRingBuffer(2).WaitDistances(); d != (WaitDistances{}) {
+		t.Fatalf("%+v without WithWaitDistances", d)
+	}
+	rb := MustNewRingBuffer(2, WithWaitDistances())
+	read := make(chan struct{})
+	go func() {
+		id, _ := rb.ReserveRead(1) //waits on the empty ring, at distance 1
+		rb.CommitRead(1, id)
+		close(read)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	id, _ := rb.ReserveWrite(0)
+	rb.CommitWrite(0, id)
+	<-read
+
+	//two writers wait on the full ring, at distances 1 and 2
+	fill(t, rb)
+	var wg sync.WaitGroup
+	for w := 0; w < 2; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			id, _ := rb.ReserveWrite(w)
+			rb.CommitWrite(w, id)
+		}(w)
+		time.Sleep(10 * time.Millisecond)
+	}
+	for i := 0; i < 2; i++ {
+		id, _ := rb.ReserveRead(1)
+		rb.CommitRead(1, id)
+	}
+	wg.Wait()
+
+	d := rb.WaitDistances()
+	if d.Read[0] != 1 || d.Write[0] != 1 || d.Write[1] != 1 {
+		t.Fatalf("read %v, write %v, want one read wait at 1 and writes at 1 and 2", d.Read, d.Write)
+	}
+}
@@ -0,0 +1,120 @@
+package ringbuffer
+
+import (
+	"unsafe"
+
+	"ringbuffer/internal/prefetch"
+)
+
+// DoubleBuffer is a frame oriented producer/consumer double buffer built on
+// two rings.
+// The producer fills one frame while the consumer drains the other, frames
+// are swapped when full or flushed. The producer waits if the frame it
+// swaps to, full or flushed, is still being drained.
+// It supports one producer goroutine and one consumer goroutine.
+// For slots of a cache line or more, Drain prefetches the next slot while
+// the current one is processed.
+type DoubleBuffer[T any] struct {
+	frames   [2]*RingBuffer
+	slots    [2][]T
+	ready    chan frame // frames handed over to the consumer
+	released chan int   // frames drained by the consumer
+
+	wFrame int     // frame being filled, producer only
+	wCount int     // items in the frame being filled, producer only
+	busy   [2]bool // frames handed over and not released, producer only
+}
+
+type frame struct {
+	index int
+	count int
+}
+
+// NewDoubleBuffer returns a DoubleBuffer with two frames of frameSize items.
+// Options apply to both underlying rings. It fails where NewRingBuffer
+// does, and if frameSize slots of T don't fit in memory.
+func NewDoubleBuffer[T any](frameSize int, opts ...Option) (*DoubleBuffer[T], error) {
+	d := &DoubleBuffer[T]{ready: make(chan frame, 2), released: make(chan int, 2)}
+	for i := range d.frames {
+		rb, err := NewRingBuffer(frameSize, opts...)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkSlots(rb.Slots(), unsafe.Sizeof(*new(T))); err != nil {
+			return nil, err
+		}
+		d.frames[i] = rb
+		d.slots[i] = make([]T, rb.Slots())
+	}
+	return d, nil
+}
+
+// MustNewDoubleBuffer is NewDoubleBuffer panicking on error.
+func MustNewDoubleBuffer[T any](frameSize int, opts ...Option) *DoubleBuffer[T] {
+	d, err := NewDoubleBuffer[T](frameSize, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// Put appends v to the frame being filled and swaps frames when it is full.
+func (d *DoubleBuffer[T]) Put(v T) {
+	for d.busy[d.wFrame] {
+		d.busy[<-d.released] = false
+	}
+	rb := d.frames[d.wFrame]
+	id, _ := rb.ReserveWrite(0) //frame rings are never closed
+	d.slots[d.wFrame][rb.BufferIndex(id)] = v
+	rb.CommitWrite(0, id)
+	d.wCount++
+	if d.wCount == rb.Size() {
+		d.Flush()
+	}
+}
+
+// Flush hands the frame being filled over to the consumer, even if it is
+// not full, and starts filling the other frame.
+func (d *DoubleBuffer[T]) Flush() {
+	if d.wCount == 0 {
+		return
+	}
+	d.busy[d.wFrame] = true
+	d.ready <- frame{index: d.wFrame, count: d.wCount}
+	d.wFrame ^= 1
+	d.wCount = 0
+}
+
+// Close flushes the pending frame and tells the consumer no more frames
+// will come.
+func (d *DoubleBuffer[T]) Close() {
+	d.Flush()
+	close(d.ready)
+}
+
+// Drain waits for the next full or flushed frame and calls fn for each of its
+// items in order, then releases the frame to the producer.
+// It returns the number of items drained, and false once the DoubleBuffer is
+// closed and every frame was drained.
+func (d *DoubleBuffer[T]) Drain(fn func(v T)) (int, bool) {
+	f, ok := <-d.ready
+	if !ok {
+		return 0, false
+	}
+	rb, slots := d.frames[f.index], d.slots[f.index]
+	var zero T
+	large := unsafe.Sizeof(zero) >= prefetch.CacheLine
+	for i := 0; i < f.count; i++ {
+		id, _ := rb.ReserveRead(0)
+		idx := rb.BufferIndex(id)
+		if large && i+1 < f.count {
+			//load next slot while fn works on this one
+			prefetch.Pointer(unsafe.Pointer(&slots[rb.BufferIndex(id+1)]))
+		}
+		fn(slots[idx])
+		slots[idx] = zero
+		rb.CommitRead(0, id)
+	}
+	d.released <- f.index
+	return f.count, true
+}
@@ -0,0 +1,78 @@
+package ringbuffer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDoubleBufferWaitsForFlushedFrame(t *testing.T) {
+	defer parallel()()
+	d := MustNewDoubleBuffer[int](8)
+	for i := 0; i < 3; i++ {
+		d.Put(i)
+	}
+	d.Flush() //frame A, partial
+	d.Put(3)
+	d.Flush() //frame B, partial
+
+	put := make(chan struct{})
+	go func() {
+		d.Put(4) //back to frame A
+		close(put)
+	}()
+	select {
+	case <-put:
+		t.Fatal("Put wrote into a frame not drained yet")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	var got []int
+	collect := func(v int) { got = append(got, v) }
+	d.Drain(collect)
+	<-put
+	d.Drain(collect)
+	d.Close()
+	for {
+		if _, ok := d.Drain(collect); !ok {
+			break
+		}
+	}
+	for i, v := range got {
+		if v != i {
+			t.Fatalf("drained %v", got)
+		}
+	}
+	if len(got) != 5 {
+		t.Fatalf("drained %v", got)
+	}
+}
+
+func TestDoubleBufferConcurrent(t *testing.T) {
+	defer parallel()()
+	const n = 10000
+	d := MustNewDoubleBuffer[int](16)
+	go func() {
+		for i := 0; i < n; i++ {
+			d.Put(i)
+			if i%7 == 0 {
+				d.Flush()
+			}
+		}
+		d.Close()
+	}()
+	next := 0
+	for {
+		_, ok := d.Drain(func(v int) {
+			if v != next {
+				t.Fatalf("drained %d, want %d", v, next)
+			}
+			next++
+		})
+		if !ok {
+			break
+		}
+	}
+	if next != n {
+		t.Fatalf("drained %d items, want %d", next, n)
+	}
+}
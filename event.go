@@ -0,0 +1,134 @@
+package ringbuffer
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Event is one ring state transition, emitted to the debug sink while Debug
+// is enabled.
+//
+// Every operation emits one "try" event per check of its condition and a
+// final "done" event. Encoded by JSONSink, one event per line:
+//
+//	{"time":"2006-01-02T15:04:05.999999999Z","ring":"name","labels":{"shard":"3"},
+//	 "op":"ReserveWrite","phase":"try","wid":1,"id":42,"rR":40,"rC":40,"wR":43,"wC":42,
+//	 "try":1,"waited":0}
+//
+// time is RFC 3339 with nanoseconds; waited is in nanoseconds since the
+// operation started; the cursors are sampled when the event is emitted.
+type Event struct {
+	Time     time.Time         `json:"time"`
+	Ring     string            `json:"ring,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"` // of WithLabels, shared by every event of the ring, read only
+	Op       string            `json:"op"`               // one of the Op constants
+	Phase    string            `json:"phase"`            // PhaseTry or PhaseDone
+	Wid      int               `json:"wid"`
+	ID       uint64            `json:"id"` // the id in flight, or the next candidate for ReserveWrite under WithReservationCap
+	RReserve uint64            `json:"rR"`
+	RCommit  uint64            `json:"rC"`
+	WReserve uint64            `json:"wR"`
+	WCommit  uint64            `json:"wC"`
+	Try      int               `json:"try"`
+	Waited   time.Duration     `json:"waited"`
+}
+
+// Event ops.
+const (
+	OpReserveWrite  = "ReserveWrite"
+	OpReserveWriteN = "ReserveWriteN"
+	OpCommitWrite   = "CommitWrite"
+	OpCommitWriteN  = "CommitWriteN"
+	OpReserveRead   = "ReserveRead"
+	OpReserveReadN  = "ReserveReadN"
+	OpCommitRead    = "CommitRead"
+	OpCommitReadN   = "CommitReadN"
+)
+
+// Event phases.
+const (
+	PhaseTry  = "try"
+	PhaseDone = "done"
+)
+
+// DebugSink receives ring events. Emit is called concurrently from every
+// goroutine using the ring.
+type DebugSink interface {
+	Emit(e Event)
+}
+
+// WithDebugSink sends debug events to s instead of JSON lines on stdout.
+// Services would rather use WithLogger.
+func WithDebugSink(s DebugSink) Option {
+	return func(rb *RingBuffer) {
+		rb.sink = s
+	}
+}
+
+type jsonSink struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// JSONSink returns a sink writing events to w as JSON lines.
+func JSONSink(w io.Writer) DebugSink {
+	return &jsonSink{enc: json.NewEncoder(w)}
+}
+
+func (s *jsonSink) Emit(e Event) {
+	s.mu.Lock()
+	s.enc.Encode(e)
+	s.mu.Unlock()
+}
+
+var stdoutSink = JSONSink(os.Stdout)
+
+// trace emits the events of one operation.
+type trace struct {
+	rb    *RingBuffer
+	op    string
+	wid   int
+	start time.Time
+}
+
+func (rb *RingBuffer) trace(op string, wid int) trace {
+	return trace{rb: rb, op: op, wid: wid, start: time.Now()}
+}
+
+func (t trace) emit(phase string, id uint64, try int) {
+	rb := t.rb
+	if rb == nil { //Debug was enabled during the operation
+		return
+	}
+	now := time.Now()
+	sink := rb.sink
+	if sink == nil {
+		sink = stdoutSink
+	}
+	sink.Emit(Event{
+		Time:     now,
+		Ring:     rb.name,
+		Labels:   rb.labels,
+		Op:       t.op,
+		Phase:    phase,
+		Wid:      t.wid,
+		ID:       id,
+		RReserve: rb.rReserve.Load(),
+		RCommit:  rb.rCommit.Load(),
+		WReserve: rb.wReserve.Load(),
+		WCommit:  rb.wCommit.Load(),
+		Try:      try,
+		Waited:   now.Sub(t.start),
+	})
+}
+
+func (t trace) try(id uint64, try int) {
+	t.emit(PhaseTry, id, try)
+}
+
+func (t trace) done(id uint64, try int) {
+	t.emit(PhaseDone, id, try)
+}
@@ -0,0 +1,32 @@
+package ringbuffer
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+// TestEventRingLabels checks debug events carry the ring name and labels.
+func TestEventRingLabels(t *testing.T) {
+	defer parallel()()
+	var out bytes.Buffer
+	rb := MustNewRingBuffer(2, WithName("orders"), WithLabels("shard", "3"),
+		WithDebugSink(JSONSink(&out)), WithDebug())
+	id, _ := rb.ReserveWrite(1)
+	rb.CommitWrite(1, id)
+	dec := json.NewDecoder(&out)
+	n := 0
+	for dec.More() {
+		var e Event
+		if err := dec.Decode(&e); err != nil {
+			t.Fatal(err)
+		}
+		if e.Ring != "orders" || e.Labels["shard"] != "3" {
+			t.Fatalf("event of ring %q labels %v", e.Ring, e.Labels)
+		}
+		n++
+	}
+	if n == 0 {
+		t.Fatal("no events emitted")
+	}
+}
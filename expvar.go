@@ -0,0 +1,77 @@
+package ringbuffer
+
+import (
+	"expvar"
+	"fmt"
+	"sync"
+)
+
+// WithExpvar publishes the counters of the ring via expvar, so services
+// exposing /debug/vars show them: in the map var name, keyed by the ring
+// name and labels, e.g. "orders{shard=3}". Rings given the same name share
+// its map. NewRingBuffer fails for a ring without WithName or WithLabels,
+// if its key is already in the map, or if name is published as another
+// kind of var. Vars can't be unpublished, so the ring stays referenced by
+// expvar for the life of the process.
+func WithExpvar(name string) Option {
+	return func(rb *RingBuffer) {
+		rb.expvarName = name
+	}
+}
+
+// expvarStats is the expvar value of a ring.
+type expvarStats struct {
+	Occupancy    uint64 `json:"occupancy"`
+	ReadReserve  uint64 `json:"rReserve"`
+	ReadCommit   uint64 `json:"rCommit"`
+	WriteReserve uint64 `json:"wReserve"`
+	WriteCommit  uint64 `json:"wCommit"`
+	Waits        uint64 `json:"waits"`     // of every phase
+	WaitNanos    int64  `json:"waitNanos"` // of every phase
+	Overwritten  uint64 `json:"overwritten"`
+	Fallbacks    uint64 `json:"fallbacks"`
+}
+
+// expvarMu serializes creating the maps of WithExpvar.
+var expvarMu sync.Mutex
+
+// publishExpvar publishes rb in its WithExpvar map, if any.
+func (rb *RingBuffer) publishExpvar() error {
+	if rb.expvarName == "" {
+		return nil
+	}
+	key := rb.key()
+	if key == "" {
+		return fmt.Errorf("RingBuffer: WithExpvar %q needs WithName or WithLabels", rb.expvarName)
+	}
+	expvarMu.Lock()
+	defer expvarMu.Unlock()
+	m, ok := expvar.Get(rb.expvarName).(*expvar.Map)
+	if !ok {
+		if expvar.Get(rb.expvarName) != nil {
+			return fmt.Errorf("RingBuffer: expvar %q already published", rb.expvarName)
+		}
+		m = expvar.NewMap(rb.expvarName)
+	}
+	if m.Get(key) != nil {
+		return fmt.Errorf("RingBuffer: %s already published in expvar %q", key, rb.expvarName)
+	}
+	m.Set(key, expvar.Func(func() any {
+		s := rb.Stats()
+		v := expvarStats{
+			Occupancy:    s.Occupancy,
+			ReadReserve:  s.ReadReserve,
+			ReadCommit:   s.ReadCommit,
+			WriteReserve: s.WriteReserve,
+			WriteCommit:  s.WriteCommit,
+			Overwritten:  s.Overwritten,
+			Fallbacks:    s.Fallbacks,
+		}
+		for _, w := range []WaitStats{s.ReserveWriteWaits, s.ReserveReadWaits, s.CommitReadWaits} {
+			v.Waits += w.Count
+			v.WaitNanos += int64(w.Time)
+		}
+		return v
+	}))
+	return nil
+}
@@ -0,0 +1,48 @@
+package ringbuffer
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"sync/atomic"
+	"testing"
+)
+
+// expvarRuns makes the var names unique per run, vars live as long as the
+// process.
+var expvarRuns atomic.Int32
+
+func TestExpvar(t *testing.T) {
+	defer parallel()()
+	name := fmt.Sprintf("test_rings_%d", expvarRuns.Add(1))
+	a := MustNewRingBuffer(4, WithExpvar(name), WithName("orders"), WithLabels("shard", "1"))
+	MustNewRingBuffer(4, WithExpvar(name), WithName("orders"), WithLabels("shard", "2"))
+	id, _ := a.ReserveWrite(0)
+	a.CommitWrite(0, id)
+
+	m, ok := expvar.Get(name).(*expvar.Map)
+	if !ok {
+		t.Fatalf("%s is not a map var", name)
+	}
+	var v expvarStats
+	if err := json.Unmarshal([]byte(m.Get("orders{shard=1}").String()), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.WriteCommit != 1 || v.Occupancy != 1 {
+		t.Fatalf("orders{shard=1}: %+v", v)
+	}
+	if m.Get("orders{shard=2}") == nil {
+		t.Fatal("second ring not in the map")
+	}
+
+	if _, err := NewRingBuffer(4, WithExpvar(name), WithName("orders"), WithLabels("shard", "1")); err == nil {
+		t.Fatal("same ring key published twice")
+	}
+	if _, err := NewRingBuffer(4, WithExpvar(name)); err == nil {
+		t.Fatal("anonymous ring published")
+	}
+	expvar.NewInt(name + "_int")
+	if _, err := NewRingBuffer(4, WithExpvar(name+"_int"), WithName("x")); err == nil {
+		t.Fatal("ring published into an Int var")
+	}
+}
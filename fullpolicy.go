@@ -0,0 +1,87 @@
+package ringbuffer
+
+import "errors"
+
+// ErrFull reports a write refused because the ring is full, see
+// FullDropNewest.
+var ErrFull = errors.New("RingBuffer: full")
+
+// FullPolicy tells a writer what to do when the ring is full.
+type FullPolicy int
+
+const (
+	// FullBlock waits for a reader to free a slot. It is the default.
+	FullBlock FullPolicy = iota
+	// FullDropNewest drops the write: the reservation returns ErrFull, and
+	// TryReserveWrite false.
+	FullDropNewest
+	// FullDropOldest drops the oldest unread entry to make room, see
+	// WithOverwrite.
+	FullDropOldest
+)
+
+// WithOnFull sets what writers do when the ring is full. Under any policy
+// but FullBlock, write reservations only take ids whose slot is free, like
+// WithReservationCap.
+func WithOnFull(p FullPolicy) Option {
+	return func(rb *RingBuffer) {
+		rb.onFull = nil
+		rb.overwrite = p == FullDropOldest
+		if p != FullBlock {
+			rb.onFull = func(int) FullPolicy { return p }
+		}
+	}
+}
+
+// WithOnFullFunc makes writers that find the ring full call fn with their
+// wid, and apply the policy it returns, e.g. to count or log stalls, or to
+// block some writers and drop the writes of others. fn is called again each
+// time a writer checks a full ring, so it should be fast.
+// FullDropOldest needs the ring in overwrite mode, opted in by WithOverwrite
+// given before WithOnFullFunc; otherwise it waits like FullBlock. Overwrite
+// mode makes writers waiting on a full ring wake on every publish, so rings
+// that never drop the oldest entry don't pay for it.
+func WithOnFullFunc(fn func(wid int) FullPolicy) Option {
+	return func(rb *RingBuffer) {
+		rb.onFull = fn
+	}
+}
+
+// full applies the OnFull policy to writer wid finding rb full. It returns
+// ErrFull if the writer should give up, or whether it should check again
+// at once instead of waiting.
+func (rb *RingBuffer) full(wid int) (retry bool, err error) {
+	if rb.onFull == nil {
+		return false, nil
+	}
+	switch rb.onFull(wid) {
+	case FullDropNewest:
+		rb.observeFull(wid)
+		return false, ErrFull
+	case FullDropOldest:
+		if !rb.overwrite {
+			return false, nil
+		}
+		rb.observeFull(wid)
+		return rb.dropOldest(), nil
+	}
+	return false, nil
+}
+
+// tryReserveWriteFull is tryReserveWrite applying the OnFull policy. It
+// fails with ErrFull under FullDropNewest, and without an error once the
+// ring is closed or if the writer should wait.
+func (rb *RingBuffer) tryReserveWriteFull(wid int) (uint64, bool, error) {
+	for {
+		if id, ok := rb.tryReserveWrite(); ok {
+			return id, true, nil
+		}
+		if rb.closed.Load() != 0 {
+			return 0, false, nil
+		}
+		retry, err := rb.full(wid)
+		if !retry {
+			return 0, false, err
+		}
+	}
+}
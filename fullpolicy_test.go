@@ -0,0 +1,116 @@
+package ringbuffer
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fill publishes ids into rb until it is full.
+func fill(t *testing.T, rb *RingBuffer) {
+	t.Helper()
+	for i := 0; i < rb.Size(); i++ {
+		id, err := rb.ReserveWrite(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rb.CommitWrite(0, id)
+	}
+}
+
+func TestOnFullDropNewest(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(2, WithOnFull(FullDropNewest))
+	fill(t, rb)
+	if _, err := rb.ReserveWrite(0); !errors.Is(err, ErrFull) {
+		t.Fatalf("ReserveWrite on a full ring: %v, want ErrFull", err)
+	}
+	if _, ok := rb.TryReserveWrite(0); ok {
+		t.Fatal("TryReserveWrite on a full ring succeeded")
+	}
+	if s := rb.Stats(); s.WriteReserve != 2 || s.Overwritten != 0 {
+		t.Fatalf("%+v after dropped writes", s)
+	}
+}
+
+func TestOnFullDropOldest(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(2, WithOnFull(FullDropOldest))
+	fill(t, rb)
+	id, err := rb.ReserveWrite(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rb.CommitWrite(0, id)
+	if got := rb.Stats().Overwritten; got != 1 {
+		t.Fatalf("%d overwritten, want 1", got)
+	}
+	if r, _ := rb.ReserveRead(0); r != 1 {
+		t.Fatalf("read id %d, want the oldest kept, 1", r)
+	}
+}
+
+// TestOnFullFunc blocks writer 0 and drops the writes of writer 1.
+func TestOnFullFunc(t *testing.T) {
+	defer parallel()()
+	var calls atomic.Int32
+	rb := MustNewRingBuffer(2, WithOnFullFunc(func(wid int) FullPolicy {
+		calls.Add(1)
+		if wid == 1 {
+			return FullDropNewest
+		}
+		return FullBlock
+	}))
+	if rb.overwrite {
+		t.Fatal("WithOnFullFunc switched to overwrite mode")
+	}
+	fill(t, rb)
+	if _, err := rb.ReserveWrite(1); !errors.Is(err, ErrFull) {
+		t.Fatalf("writer 1: %v, want ErrFull", err)
+	}
+	done := make(chan error)
+	go func() {
+		id, err := rb.ReserveWrite(0)
+		if err == nil {
+			rb.CommitWrite(0, id)
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		t.Fatalf("writer 0 did not wait: %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	r, _ := rb.ReserveRead(0)
+	rb.CommitRead(0, r)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() < 2 {
+		t.Fatalf("fn called %d times", calls.Load())
+	}
+}
+
+// TestOnFullFuncDropOldest checks FullDropOldest waits unless the ring opted
+// in to overwrite mode.
+func TestOnFullFuncDropOldest(t *testing.T) {
+	defer parallel()()
+	oldest := func(int) FullPolicy { return FullDropOldest }
+	rb := MustNewRingBuffer(2, WithOnFullFunc(oldest))
+	fill(t, rb)
+	if _, ok := rb.TryReserveWrite(0); ok {
+		t.Fatal("dropped the oldest entry without WithOverwrite")
+	}
+
+	rb = MustNewRingBuffer(2, WithOverwrite(), WithOnFullFunc(oldest))
+	fill(t, rb)
+	id, ok := rb.TryReserveWrite(0)
+	if !ok {
+		t.Fatal("TryReserveWrite failed in overwrite mode")
+	}
+	rb.CommitWrite(0, id)
+	if got := rb.Stats().Overwritten; got != 1 {
+		t.Fatalf("%d overwritten, want 1", got)
+	}
+}
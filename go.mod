@@ -1,6 +1,6 @@
 module ringbuffer
 
-go 1.15
+go 1.21
 
 replace github.com/gxlb/ringbuffer => ./
 
@@ -0,0 +1,25 @@
+//go:build !magicmod
+
+package ringbuffer
+
+import "math/bits"
+
+// BufferIndex returns logic index of buffer by id
+func (rb *RingBuffer) BufferIndex(id uint64) int {
+	if rb.divPow2 {
+		return int(id & rb.divMask)
+	}
+	return int(id % uint64(rb.slots))
+}
+
+// initIndex keeps Size slots, indexed by a modulo, unless the size is a
+// power of two or WithPowerOfTwoSlots rounds the slots up to one, so
+// BufferIndex is a mask.
+func (rb *RingBuffer) initIndex() {
+	rb.slots = rb.size
+	if rb.pow2Slots {
+		rb.slots = 1 << bits.Len64(uint64(rb.size-1))
+	}
+	rb.divPow2 = rb.slots&(rb.slots-1) == 0
+	rb.divMask = uint64(rb.slots - 1)
+}
@@ -0,0 +1,39 @@
+//go:build magicmod
+
+package ringbuffer
+
+import "math/bits"
+
+// With the magicmod build tag, BufferIndex replaces the hardware divide of
+// id % size by a multiply and shifts (Granlund–Montgomery division by an
+// invariant integer), for rings of exactly Size slots whose slot memory
+// matters more than the cost of the index.
+
+// BufferIndex returns logic index of buffer by id
+func (rb *RingBuffer) BufferIndex(id uint64) int {
+	if rb.divShift == 0 { //power of two
+		return int(id & rb.divMask)
+	}
+	t, _ := bits.Mul64(rb.divMagic, id)
+	q := (t + (id-t)>>1) >> (rb.divShift - 1)
+	return int(id - q*uint64(rb.slots))
+}
+
+// initIndex precomputes the magic number of the slots, Size unless
+// WithPowerOfTwoSlots rounds them up.
+// For l = ceil(log2(size)), the 65 bit multiplier is
+// floor(2^(64+l) / size) + 1; divMagic keeps its low 64 bits.
+func (rb *RingBuffer) initIndex() {
+	rb.slots = rb.size
+	if rb.pow2Slots {
+		rb.slots = 1 << bits.Len64(uint64(rb.size-1))
+	}
+	d := uint64(rb.slots)
+	if d&(d-1) == 0 {
+		rb.divShift, rb.divMask = 0, d-1
+		return
+	}
+	l := uint(bits.Len64(d - 1))
+	m, _ := bits.Div64((uint64(1)<<l)-d, 0, d)
+	rb.divMagic, rb.divShift = m+1, l
+}
@@ -0,0 +1,128 @@
+// Package ingest reads packets straight into the slots of a ring.
+//
+// Every slot owns a fixed region of one preallocated buffer. The reading
+// goroutine claims a slot, receives a datagram into its region and
+// publishes it; consumers process the region in place and release it. No
+// allocation happens per packet.
+package ingest
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/netip"
+
+	"ringbuffer"
+)
+
+// Packet is a received packet. Data aliases the slot region and is valid
+// until the packet is released.
+type Packet struct {
+	ID   uint64
+	Data []byte
+	Addr netip.AddrPort // sender, zero for raw sockets
+}
+
+// Ring is a ring of packet regions.
+// One goroutine reads packets in, any number of goroutines consume them.
+type Ring struct {
+	rb    *ringbuffer.RingBuffer
+	frame int
+	buf   []byte
+	lens  []int
+	addrs []netip.AddrPort
+}
+
+// NewRing returns a Ring of size slots of frameSize bytes each.
+// Datagrams larger than frameSize are truncated. It fails where
+// ringbuffer.NewRingBuffer does, and if the frames don't fit in memory.
+func NewRing(size, frameSize int, opts ...ringbuffer.Option) (*Ring, error) {
+	rb, err := ringbuffer.NewRingBuffer(size, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if frameSize <= 0 || rb.Slots() > math.MaxInt/frameSize {
+		return nil, fmt.Errorf("ingest: invalid frame size %d", frameSize)
+	}
+	return &Ring{
+		rb:    rb,
+		frame: frameSize,
+		buf:   make([]byte, rb.Slots()*frameSize),
+		lens:  make([]int, rb.Slots()),
+		addrs: make([]netip.AddrPort, rb.Slots()),
+	}, nil
+}
+
+// MustNewRing is NewRing panicking on error.
+func MustNewRing(size, frameSize int, opts ...ringbuffer.Option) *Ring {
+	r, err := NewRing(size, frameSize, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// RingBuffer returns the underlying ring, e.g. for Stats.
+func (r *Ring) RingBuffer() *ringbuffer.RingBuffer {
+	return r.rb
+}
+
+// region returns the buffer of the slot of id.
+func (r *Ring) region(id uint64) []byte {
+	off := r.rb.BufferIndex(id) * r.frame
+	return r.buf[off : off+r.frame : off+r.frame]
+}
+
+// claim returns the slot for the next packet. It returns ErrClosed once
+// the ring is closed.
+func (r *Ring) claim() (uint64, error) {
+	return r.rb.ReserveWrite(-1)
+}
+
+func (r *Ring) publish(id uint64, n int, addr netip.AddrPort) {
+	idx := r.rb.BufferIndex(id)
+	r.lens[idx] = n
+	r.addrs[idx] = addr
+	r.rb.CommitWriteBytes(-1, id, n)
+}
+
+// ReadUDP receives datagrams from conn into the ring until a read fails,
+// and returns that error, giving up the slot it was reading into. It waits
+// while the ring is full, and returns ErrClosed once the ring is closed.
+// Only one goroutine may read into a Ring at a time.
+func (r *Ring) ReadUDP(conn *net.UDPConn) error {
+	for {
+		id, err := r.claim()
+		if err != nil {
+			return err
+		}
+		n, addr, err := conn.ReadFromUDPAddrPort(r.region(id))
+		if err != nil {
+			r.rb.AbortWrite(-1, id)
+			return err
+		}
+		r.publish(id, n, addr)
+	}
+}
+
+// Next waits for the next packet. It returns ErrClosed once the ring is
+// closed, see ringbuffer.WithDrainOnClose.
+// It is goroutine-safe.
+func (r *Ring) Next(wid int) (Packet, error) {
+	id, err := r.rb.ReserveRead(wid)
+	if err != nil {
+		return Packet{}, err
+	}
+	idx := r.rb.BufferIndex(id)
+	return Packet{
+		ID:   id,
+		Data: r.region(id)[:r.lens[idx]],
+		Addr: r.addrs[idx],
+	}, nil
+}
+
+// Release gives the region of p back to the reader.
+// It is goroutine-safe.
+func (r *Ring) Release(wid int, p Packet) {
+	r.rb.CommitReadBytes(wid, p.ID, len(p.Data))
+}
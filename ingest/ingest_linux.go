@@ -0,0 +1,30 @@
+package ingest
+
+import (
+	"net/netip"
+	"syscall"
+)
+
+// ReadSocket receives packets from the socket fd into the ring until a read
+// fails, and returns that error, giving up the slot it was reading into.
+// fd may be any datagram socket, including an AF_PACKET socket to capture
+// link layer frames. It waits while the ring is full, and returns ErrClosed
+// once the ring is closed.
+// Only one goroutine may read into a Ring at a time.
+func (r *Ring) ReadSocket(fd int) error {
+	for {
+		id, err := r.claim()
+		if err != nil {
+			return err
+		}
+		n, _, err := syscall.Recvfrom(fd, r.region(id), 0)
+		for err == syscall.EINTR {
+			n, _, err = syscall.Recvfrom(fd, r.region(id), 0)
+		}
+		if err != nil {
+			r.rb.AbortWrite(-1, id)
+			return err
+		}
+		r.publish(id, n, netip.AddrPort{})
+	}
+}
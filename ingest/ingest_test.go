@@ -0,0 +1,96 @@
+package ingest
+
+import (
+	"errors"
+	"net"
+	"runtime"
+	"testing"
+	"time"
+
+	"ringbuffer"
+)
+
+// parallel sets GOMAXPROCS high enough for a RingBuffer and returns a func
+// restoring it.
+func parallel() func() {
+	old := runtime.GOMAXPROCS(4)
+	return func() { runtime.GOMAXPROCS(old) }
+}
+
+func listen(t *testing.T) (*net.UDPConn, *net.UDPConn) {
+	t.Helper()
+	in, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Skip("no loopback:", err)
+	}
+	out, err := net.DialUDP("udp", nil, in.LocalAddr().(*net.UDPAddr))
+	if err != nil {
+		in.Close()
+		t.Fatal(err)
+	}
+	return in, out
+}
+
+func TestReadUDP(t *testing.T) {
+	defer parallel()()
+	in, out := listen(t)
+	defer out.Close()
+	r := MustNewRing(8, 16)
+	done := make(chan error, 1)
+	go func() { done <- r.ReadUDP(in) }()
+
+	msgs := []string{"one", "two", "a datagram longer than a frame"}
+	for _, m := range msgs {
+		if _, err := out.Write([]byte(m)); err != nil {
+			t.Fatal(err)
+		}
+		p, err := r.Next(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := m
+		if len(want) > 16 {
+			want = want[:16]
+		}
+		if string(p.Data) != want {
+			t.Fatalf("got %q, want %q", p.Data, want)
+		}
+		if p.Addr.Port() != uint16(out.LocalAddr().(*net.UDPAddr).Port) {
+			t.Fatalf("sender %v, want %v", p.Addr, out.LocalAddr())
+		}
+		r.Release(0, p)
+	}
+
+	//a failed read gives up its slot
+	in.Close()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("ReadUDP returned nil")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ReadUDP did not return after the conn was closed")
+	}
+	s := r.RingBuffer().Stats()
+	if s.WriteCommit != s.WriteReserve {
+		t.Fatalf("write reserve %d, commit %d: failed read kept its slot",
+			s.WriteReserve, s.WriteCommit)
+	}
+}
+
+func TestNextAfterClose(t *testing.T) {
+	defer parallel()()
+	r := MustNewRing(4, 16)
+	if err := r.RingBuffer().Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Next(0); !errors.Is(err, ringbuffer.ErrClosed) {
+		t.Fatalf("Next: %v, want ErrClosed", err)
+	}
+	in, out := listen(t)
+	defer in.Close()
+	defer out.Close()
+	if err := r.ReadUDP(in); !errors.Is(err, ringbuffer.ErrClosed) {
+		t.Fatalf("ReadUDP: %v, want ErrClosed", err)
+	}
+}
@@ -0,0 +1,69 @@
+package ringbuffer
+
+import "time"
+
+// Instrumentation receives the operations of a ring, for tracing and
+// metrics, see WithInstrumentation. The otelring module wires it to
+// OpenTelemetry, keeping this package free of dependencies.
+// Its methods run on the calling goroutine and must not block.
+type Instrumentation interface {
+	// Op is called once ReserveWrite, CommitWrite, CommitWriteN,
+	// ReserveRead or CommitRead returns, with the time it was called. id is
+	// the id reserved or committed, the first one for CommitWriteN. Their
+	// Context and Timeout variants are reported as them, and so are
+	// their Try variants when they succeed.
+	Op(op string, wid int, id uint64, start time.Time, err error)
+	// Latency is called when ReserveRead returns id, with the time since
+	// id was published.
+	Latency(wid int, id uint64, d time.Duration)
+}
+
+// WithInstrumentation reports the operations of the ring to in. It costs
+// a clock read per operation, plus those of in.
+func WithInstrumentation(in Instrumentation) Option {
+	return func(rb *RingBuffer) {
+		rb.instr = in
+	}
+}
+
+// instrumented reports an operation that started at start to the
+// Instrumentation of rb; it is deferred with pointers to the results.
+func (rb *RingBuffer) instrumented(op string, wid int, start time.Time, id *uint64, err *error) {
+	rb.instr.Op(op, wid, *id, start, *err)
+	if (op == OpReserveRead || op == OpReserveReadN) && *err == nil {
+		published := rb.publishTimes[rb.BufferIndex(*id)].Load()
+		rb.instr.Latency(wid, *id, time.Duration(time.Now().UnixNano()-published))
+	}
+}
+
+// instrumentedTry reports a Try operation that started at start, if it
+// succeeded; it is deferred with pointers to the results.
+func (rb *RingBuffer) instrumentedTry(op string, wid int, start time.Time, id *uint64, ok *bool) {
+	if *ok {
+		var err error
+		rb.instrumented(op, wid, start, id, &err)
+	}
+}
+
+// committedTry reports a TryCommitWrite or TryCommitRead of id that started
+// at start, if it committed, to the Instrumentation and the Observer.
+func (rb *RingBuffer) committedTry(op string, wid int, start time.Time, id uint64, ok *bool, err *error) {
+	if !*ok {
+		return
+	}
+	if rb.instr != nil {
+		rb.instrumented(op, wid, start, &id, err)
+	}
+	if rb.observer != nil {
+		rb.observeCommit(op, wid, id, id, err)
+	}
+}
+
+// stampPublish records the publish time of write ids [lo, hi] for
+// Instrumentation.Latency.
+func (rb *RingBuffer) stampPublish(lo, hi uint64) {
+	now := time.Now().UnixNano()
+	for id := lo; id <= hi; id++ {
+		rb.publishTimes[rb.BufferIndex(id)].Store(now)
+	}
+}
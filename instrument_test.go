@@ -0,0 +1,87 @@
+package ringbuffer
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+type opCounter struct {
+	mu      sync.Mutex
+	ops     map[string]int
+	latency int
+}
+
+func (c *opCounter) Op(op string, wid int, id uint64, start time.Time, err error) {
+	c.mu.Lock()
+	c.ops[op]++
+	c.mu.Unlock()
+}
+
+func (c *opCounter) Latency(wid int, id uint64, d time.Duration) {
+	c.mu.Lock()
+	c.latency++
+	c.mu.Unlock()
+}
+
+// TestInstrumentationVariants checks that the Context, Timeout and Try
+// variants are reported like the plain calls.
+func TestInstrumentationVariants(t *testing.T) {
+	defer parallel()()
+	in := &opCounter{ops: map[string]int{}}
+	obs := &commitRecorder{}
+	rb := MustNewRingBuffer(4, WithInstrumentation(in), WithObserver(obs))
+	ctx := context.Background()
+
+	id, _ := rb.ReserveWriteContext(ctx, 0)
+	rb.CommitWriteContext(ctx, 0, id)
+	id, _ = rb.ReserveReadContext(ctx, 1)
+	rb.CommitReadContext(ctx, 1, id)
+
+	id, _ = rb.ReserveWriteTimeout(0, 0)
+	rb.CommitWriteTimeout(0, id, 0)
+	id, _ = rb.ReserveReadTimeout(1, 0)
+	rb.CommitReadTimeout(1, id, 0)
+
+	id, _ = rb.TryReserveWrite(0)
+	rb.TryCommitWrite(0, id)
+	id, _ = rb.TryReserveRead(1)
+	rb.TryCommitRead(1, id)
+	if _, ok := rb.TryReserveRead(1); ok {
+		t.Fatal("read from an empty ring")
+	}
+
+	for _, op := range []string{OpReserveWrite, OpCommitWrite, OpReserveRead, OpCommitRead} {
+		if in.ops[op] != 3 {
+			t.Fatalf("%s reported %d times, want 3: %v", op, in.ops[op], in.ops)
+		}
+	}
+	if in.latency != 3 {
+		t.Fatalf("%d latencies reported, want 3", in.latency)
+	}
+	if len(obs.wids) != 6 {
+		t.Fatalf("%d commits observed, want 6", len(obs.wids))
+	}
+}
+
+func TestContextWaitWakesOnCancel(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := rb.ReserveReadContext(ctx, 0)
+		done <- err
+	}()
+	time.Sleep(5 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("cancel didn't wake the reader")
+	}
+}
@@ -0,0 +1,32 @@
+package ringbuffer
+
+// Producer is the write side of a ring: reserve an id, fill its slot, and
+// commit it.
+type Producer interface {
+	ReserveWrite(wid int) (uint64, error)
+	CommitWrite(wid int, id uint64) error
+}
+
+// Consumer is the read side of a ring: reserve an id, read its slot, and
+// commit it.
+type Consumer interface {
+	ReserveRead(wid int) (uint64, error)
+	CommitRead(wid int, id uint64) error
+}
+
+// Ring is an id based ring, so code accepting it works with any of
+// RingBuffer, its MPSC and SPMC flavors, SPSCRingBuffer, or a mock such as
+// ringbuffertest.MockRing.
+type Ring interface {
+	Producer
+	Consumer
+	BufferIndex(id uint64) int
+	Size() int
+	Close() error
+}
+
+var (
+	_ Ring     = (*RingBuffer)(nil)
+	_ Ring     = (*SPSCRingBuffer)(nil)
+	_ Consumer = (*Subscriber)(nil)
+)
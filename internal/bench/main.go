@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"ringbuffer"
 	"runtime"
@@ -13,15 +14,30 @@ var (
 	maxId       = uint64(bufferSize * 1000)
 	workerCount = 5
 	wg          sync.WaitGroup
-	rb          = ringbuffer.NewRingBuffer(bufferSize)
+	rb          *ringbuffer.RingBuffer
 	debug       = true
+	work        = false // simulate per-item work in delay
 )
 
 func main() {
+	flag.BoolVar(&debug, "debug", debug, "emit debug events")
+	runMismatch := flag.Bool("scenarios", false, "run the producer/consumer rate mismatch scenarios")
+	flag.Parse()
+	if *runMismatch {
+		runScenarios()
+		return
+	}
+
 	start := time.Now()
 	cpus := runtime.NumCPU()
 	runtime.GOMAXPROCS(cpus)
 	fmt.Printf("%s start, cpus=%d workerCount=%d bufferSize=%d maxId=%d\n", start, cpus, workerCount, bufferSize, maxId)
+	var err error
+	rb, err = ringbuffer.NewRingBuffer(bufferSize)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
 	rb.Debug(debug)
 	wg.Add(workerCount * 2)
 	for i := 0; i < workerCount; i++ {
@@ -36,7 +52,9 @@ func main() {
 }
 
 func delay(id int) {
-	return
+	if !work {
+		return
+	}
 	x := 0
 	for i := 0; i < id; i++ {
 		for j := 0; j < id; j++ {
@@ -52,7 +70,7 @@ func reader(wid int) {
 		if debug {
 			//fmt.Printf("reader wid=%d try hold\n", wid)
 		}
-		id := rb.ReserveRead(wid)
+		id, _ := rb.ReserveRead(wid)
 		if debug {
 			//fmt.Printf("reader wid=%d hold %d\n", wid, id)
 		}
@@ -76,7 +94,7 @@ func writer(wid int) {
 		if debug {
 			//fmt.Printf("writer wid=%d try hold\n", wid)
 		}
-		id := rb.ReserveWrite(wid)
+		id, _ := rb.ReserveWrite(wid)
 		if debug {
 			//fmt.Printf("writer wid=%d hold %d\n", wid, id)
 		}
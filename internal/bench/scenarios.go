@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"ringbuffer"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// scenario is a producer/consumer rate mismatch to run the ring under.
+type scenario struct {
+	name      string
+	producers int
+	consumers int
+	items     int           // items per producer
+	produce   time.Duration // work per produced item
+	consume   time.Duration // work per consumed item
+	burst     int           // items per burst, 0 for a steady rate
+	pause     time.Duration // pause between bursts
+}
+
+var scenarios = []scenario{
+	{name: "uniform", producers: 4, consumers: 4, items: 20000},
+	{name: "fast producers, slow consumers", producers: 4, consumers: 2, items: 2000, consume: 20 * time.Microsecond},
+	{name: "slow producers, fast consumers", producers: 2, consumers: 4, items: 4000, produce: 20 * time.Microsecond},
+	{name: "bursty producers", producers: 2, consumers: 2, items: 8000, burst: 2000, pause: 2 * time.Millisecond},
+	{name: "many idle readers", producers: 1, consumers: 32, items: 4000, produce: 10 * time.Microsecond},
+}
+
+var strategies = []struct {
+	name string
+	ws   ringbuffer.WaitStrategy
+}{
+	{"blocking", ringbuffer.BlockingWaitStrategy{}},
+	{"yielding", ringbuffer.YieldingWaitStrategy{Spins: 100}},
+	{"busyspin", ringbuffer.BusySpinWaitStrategy{}},
+}
+
+var sizes = []int{16, 1024}
+
+// runScenarios runs every scenario under every wait strategy and buffer
+// size, and reports throughput and the mean time a producer waited for a
+// free slot and a consumer for an item.
+func runScenarios() {
+	if runtime.GOMAXPROCS(0) < 4 { //rings require parallelism, see RingBuffer
+		runtime.GOMAXPROCS(4)
+	}
+	fmt.Printf("%-32s %-9s %5s %12s %12s %12s\n", "scenario", "strategy", "size", "items/s", "write wait", "read wait")
+	for _, sc := range scenarios {
+		for _, st := range strategies {
+			if _, ok := st.ws.(ringbuffer.BusySpinWaitStrategy); ok && runtime.NumCPU() < sc.producers+sc.consumers {
+				//a spinning waiter holds its core until preempted
+				fmt.Printf("%-32s %-9s skipped, needs a core per goroutine\n", sc.name, st.name)
+				continue
+			}
+			for _, size := range sizes {
+				r := sc.run(st.ws, size)
+				fmt.Printf("%-32s %-9s %5d %12.0f %12s %12s\n", sc.name, st.name, size, r.rate, r.writeWait, r.readWait)
+			}
+		}
+	}
+}
+
+// result is the outcome of one scenario run.
+type result struct {
+	rate      float64       // items per second
+	writeWait time.Duration // mean ReserveWrite time
+	readWait  time.Duration // mean ReserveRead time
+}
+
+func (sc scenario) run(ws ringbuffer.WaitStrategy, size int) result {
+	rb := ringbuffer.MustNewRingBuffer(size, ringbuffer.WithWaitStrategy(ws), ringbuffer.WithDrainOnClose())
+	var writeWait, readWait, read atomic.Int64
+	var producers, consumers sync.WaitGroup
+	start := time.Now()
+	for i := 0; i < sc.producers; i++ {
+		producers.Add(1)
+		go func(wid int) {
+			defer producers.Done()
+			for n := 0; n < sc.items; n++ {
+				if sc.burst > 0 && n > 0 && n%sc.burst == 0 {
+					time.Sleep(sc.pause)
+				}
+				spin(sc.produce)
+				t := time.Now()
+				id, err := rb.ReserveWrite(wid)
+				writeWait.Add(int64(time.Since(t)))
+				if err != nil {
+					return
+				}
+				rb.CommitWrite(wid, id)
+			}
+		}(i + 1)
+	}
+	for i := 0; i < sc.consumers; i++ {
+		consumers.Add(1)
+		go func(wid int) {
+			defer consumers.Done()
+			for {
+				t := time.Now()
+				id, err := rb.ReserveRead(wid)
+				if err != nil {
+					return
+				}
+				readWait.Add(int64(time.Since(t)))
+				read.Add(1)
+				spin(sc.consume)
+				rb.CommitRead(wid, id)
+			}
+		}(-i - 1)
+	}
+	producers.Wait()
+	rb.Close()
+	consumers.Wait()
+	cost := time.Since(start)
+
+	written := int64(sc.producers * sc.items)
+	r := result{rate: float64(read.Load()) / cost.Seconds()}
+	r.writeWait = time.Duration(writeWait.Load() / written)
+	if n := read.Load(); n > 0 {
+		r.readWait = time.Duration(readWait.Load() / n)
+	}
+	return r
+}
+
+// spin busy works for d, finer grained than time.Sleep.
+func spin(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	for t := time.Now(); time.Since(t) < d; {
+	}
+}
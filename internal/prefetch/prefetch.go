@@ -0,0 +1,7 @@
+// Package prefetch issues cache prefetch hints.
+// Build with the noprefetch tag to turn the hints into no-ops, e.g. to
+// measure their effect.
+package prefetch
+
+// CacheLine is the assumed cache line size in bytes.
+const CacheLine = 64
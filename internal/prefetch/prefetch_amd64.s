@@ -0,0 +1,9 @@
+//go:build !noprefetch
+
+#include "textflag.h"
+
+// func Pointer(p unsafe.Pointer)
+TEXT ·Pointer(SB), NOSPLIT, $0-8
+	MOVQ p+0(FP), AX
+	PREFETCHT0 (AX)
+	RET
@@ -0,0 +1,10 @@
+//go:build !(amd64 || arm64) || noprefetch
+
+package prefetch
+
+import (
+	"unsafe"
+)
+
+// Pointer hints the CPU to load the cache line of p.
+func Pointer(p unsafe.Pointer) {}
@@ -0,0 +1,62 @@
+package wrapcopy
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestWrap(t *testing.T) {
+	for _, size := range []int{1, 7, 64, 100} {
+		for off := 0; off < size; off++ {
+			for n := 0; n <= size; n++ {
+				buf := make([]byte, size)
+				src := make([]byte, n)
+				for i := range src {
+					src[i] = byte(i + 1)
+				}
+				In(buf, off, src)
+				for i := range src {
+					if buf[(off+i)%size] != src[i] {
+						t.Fatalf("In size %d off %d n %d: byte %d", size, off, n, i)
+					}
+				}
+				dst := make([]byte, n)
+				Out(dst, buf, off)
+				if !bytes.Equal(dst, src) {
+					t.Fatalf("Out size %d off %d n %d", size, off, n)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkIn(b *testing.B) {
+	for _, n := range []int{8, 24, 48, 256, 4096} {
+		buf := make([]byte, 1<<16)
+		src := make([]byte, n)
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			b.SetBytes(int64(n))
+			off := 0
+			for i := 0; i < b.N; i++ {
+				In(buf, off, src)
+				off = (off + n + 3) & (len(buf) - 1)
+			}
+		})
+	}
+}
+
+func BenchmarkOut(b *testing.B) {
+	for _, n := range []int{8, 24, 48, 256, 4096} {
+		buf := make([]byte, 1<<16)
+		dst := make([]byte, n)
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			b.SetBytes(int64(n))
+			off := 0
+			for i := 0; i < b.N; i++ {
+				Out(dst, buf, off)
+				off = (off + n + 3) & (len(buf) - 1)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package ringbuffer
+
+import (
+	"context"
+	"runtime/pprof"
+	"strconv"
+)
+
+// ProfileLabels returns the pprof labels of a goroutine serving the ring:
+// the ring name, its role (e.g. "consumer", "worker"), its worker id and
+// the ring labels of WithLabels, but those named ring, role or worker.
+func (rb *RingBuffer) ProfileLabels(role string, worker int) pprof.LabelSet {
+	kv := make([]string, 0, 6+2*len(rb.labels))
+	for k, v := range rb.labels {
+		switch k {
+		case "ring", "role", "worker":
+		default:
+			kv = append(kv, k, v)
+		}
+	}
+	kv = append(kv, "ring", rb.name, "role", role, "worker", strconv.Itoa(worker))
+	return pprof.Labels(kv...)
+}
+
+// Go runs fn on a new goroutine tagged with ProfileLabels, so CPU profiles
+// of a busy pipeline attribute its samples to the ring and stage.
+func (rb *RingBuffer) Go(role string, worker int, fn func()) {
+	go pprof.Do(context.Background(), rb.ProfileLabels(role, worker), func(context.Context) {
+		fn()
+	})
+}
@@ -0,0 +1,27 @@
+package ringbuffer
+
+import (
+	"context"
+	"runtime/pprof"
+	"testing"
+)
+
+func TestProfileLabels(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(2, WithName("orders"), WithLabels("shard", "3", "role", "ignored"))
+	ctx := pprof.WithLabels(context.Background(), rb.ProfileLabels("consumer", 1))
+	got := map[string]string{}
+	pprof.ForLabels(ctx, func(k, v string) bool {
+		got[k] = v
+		return true
+	})
+	want := map[string]string{"ring": "orders", "role": "consumer", "worker": "1", "shard": "3"}
+	if len(got) != len(want) {
+		t.Fatalf("labels %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Fatalf("labels %v, want %v", got, want)
+		}
+	}
+}
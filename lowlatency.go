@@ -0,0 +1,40 @@
+package ringbuffer
+
+import "runtime/debug"
+
+// WithLowLatency tunes rb for handoffs in the low microseconds, e.g. for
+// trading or telemetry: waiters busy-spin, burning a core each, any idle
+// wait strategy is dropped, and the per-slot state is touched up front so
+// that the first lap doesn't page fault.
+// Reserve and commit don't allocate, so they never run GC assists; keep the
+// payloads preallocated too (TypedRingBuffer, Pool), and see TuneGC for
+// pauses.
+func WithLowLatency() Option {
+	return func(rb *RingBuffer) {
+		rb.waitStrategy.Store(strategyBox{BusySpinWaitStrategy{}})
+		rb.idlePeriod = 0
+		rb.lowLatency = true
+	}
+}
+
+// prefault touches the per-slot state of rb, see WithLowLatency.
+func (rb *RingBuffer) prefault() {
+	for i := range rb.writeDone {
+		rb.writeDone[i].Store(0)
+	}
+	for i := range rb.readDone {
+		rb.readDone[i].Store(0)
+	}
+	for i := range rb.meta {
+		rb.meta[i] = SlotMeta{}
+	}
+}
+
+// TuneGC sets the garbage collection target percentage like
+// debug.SetGCPercent, and returns a func restoring the previous one. A
+// latency critical section with a bounded heap can raise it, or turn the
+// collector off with -1, and restore it after.
+func TuneGC(percent int) (restore func()) {
+	old := debug.SetGCPercent(percent)
+	return func() { debug.SetGCPercent(old) }
+}
@@ -0,0 +1,85 @@
+package ringbuffer
+
+import (
+	"runtime"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestLowLatencyNoAlloc(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(16, WithLowLatency())
+	allocs := testing.AllocsPerRun(1000, func() {
+		id, _ := rb.ReserveWrite(0)
+		rb.CommitWrite(0, id)
+		id, _ = rb.ReserveRead(0)
+		rb.CommitRead(0, id)
+	})
+	if allocs != 0 {
+		t.Fatalf("%v allocations per round trip", allocs)
+	}
+}
+
+// BenchmarkLowLatency measures the publish to consume latency of a ring
+// WithLowLatency while another goroutine keeps the collector busy, and
+// reports its distribution.
+func BenchmarkLowLatency(b *testing.B) {
+	if runtime.NumCPU() < 2 {
+		b.Skip("needs a core for each of the spinning producer and consumer")
+	}
+	b.Run("gc", func(b *testing.B) { benchLowLatency(b, 100) })
+	b.Run("nogc", func(b *testing.B) { benchLowLatency(b, -1) })
+}
+
+func benchLowLatency(b *testing.B, gcPercent int) {
+	defer parallel()()
+	defer TuneGC(gcPercent)()
+	rb := MustNewRingBuffer(1024, WithLowLatency())
+	stamps := make([]int64, rb.Slots())
+	lat := make([]time.Duration, b.N)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() { //garbage source
+		var sink [][]byte
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			sink = append(sink, make([]byte, 1024))
+			if len(sink) == 1024 {
+				sink = nil
+			}
+		}
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range lat {
+			id, _ := rb.ReserveRead(0)
+			lat[i] = time.Duration(time.Now().UnixNano() - stamps[rb.BufferIndex(id)])
+			rb.CommitRead(0, id)
+		}
+	}()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		id, _ := rb.ReserveWrite(0)
+		stamps[rb.BufferIndex(id)] = time.Now().UnixNano()
+		rb.CommitWrite(0, id)
+		for !rb.PassedBarrier(id) { //one item in flight: latency, not queueing
+		}
+	}
+	<-done
+	b.StopTimer()
+
+	sort.Slice(lat, func(i, j int) bool { return lat[i] < lat[j] })
+	q := func(p float64) float64 { return float64(lat[int(p*float64(len(lat)-1))]) }
+	b.ReportMetric(q(0.5), "p50-ns")
+	b.ReportMetric(q(0.99), "p99-ns")
+	b.ReportMetric(q(0.999), "p99.9-ns")
+	b.ReportMetric(q(1), "max-ns")
+}
@@ -0,0 +1,29 @@
+package ringbuffer
+
+// SlotMeta is the fixed-size header kept per slot by WithSlotMeta.
+// Writers fill it between ReserveWrite and CommitWrite, readers see it
+// between ReserveRead and CommitRead, like the slot payload itself.
+type SlotMeta struct {
+	Type      uint16
+	Flags     uint16
+	Topic     uint16 // logical stream, see TopicMux and Subscriber.Filter
+	Length    uint32
+	Timestamp int64 // UnixNano, set by the writer
+}
+
+// WithSlotMeta keeps a SlotMeta per slot beside the payload, so rings can
+// carry routing info without embedding it in the payload type.
+func WithSlotMeta() Option {
+	return func(rb *RingBuffer) {
+		rb.withMeta = true
+	}
+}
+
+// Meta returns the metadata of the slot of id, or nil without WithSlotMeta.
+// The caller must hold a write or read reservation of id.
+func (rb *RingBuffer) Meta(id uint64) *SlotMeta {
+	if rb.meta == nil {
+		return nil
+	}
+	return &rb.meta[rb.BufferIndex(id)]
+}
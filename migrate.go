@@ -0,0 +1,80 @@
+package ringbuffer
+
+import "runtime"
+
+// Migrate resizes a live ring by replacing it: it creates a ring of newSize
+// slots configured by opts, redirects producers to it, and drains every item
+// left in rb into it in order, calling move to copy the payload of old id
+// into the slot of new id. It returns the new ring, or the error of
+// NewRingBuffer leaving rb as it was.
+//
+// Producers and consumers must get the ring from Current for every
+// reservation, so they pick up the new ring. Writers of the new ring wait
+// until the old items are moved, so they land after them; readers of the
+// new ring may start at once. The old ring is closed, so late calls on it
+// return ErrClosed and the caller should retry on Current; consumers still
+// reading the old ring may take some of the old items before Migrate does.
+// Items left when the new ring is closed meanwhile are dropped.
+// Migrating a ring that was already migrated migrates its Current ring.
+// A ring with a single reader, see NewMPSCRingBuffer, is not drained by
+// Migrate, which would be a second reader: move is not called, and the
+// reader takes the old items itself, reading the old ring until ErrClosed
+// before it switches to Current.
+// It is goroutine-safe.
+func (rb *RingBuffer) Migrate(newSize int, move func(oldId, newId uint64), opts ...Option) (*RingBuffer, error) {
+	rb.migrateMu.Lock()
+	if rb.successor.Load() != nil {
+		rb.migrateMu.Unlock()
+		return rb.Current().Migrate(newSize, move, opts...)
+	}
+	nr, err := NewRingBuffer(newSize, opts...)
+	if err != nil {
+		rb.migrateMu.Unlock()
+		return nil, err
+	}
+	gate := make(chan struct{})
+	if !rb.singleReader {
+		nr.gate = gate
+	}
+	rb.successor.Store(nr)
+	rb.close(closedDrain) //writers reserving after it abort, readers drain
+	rb.migrateMu.Unlock()
+	if rb.singleReader {
+		return nr, nil
+	}
+
+	for {
+		id, ok := rb.tryReserveRead(-1)
+		if !ok {
+			//nothing readable: done once no write reservation is pending
+			if rb.rReserve.Load() >= rb.wReserve.Load() {
+				break
+			}
+			runtime.Gosched()
+			continue
+		}
+		nid, err := nr.reserveWrite(-1)
+		if err != nil {
+			rb.CommitRead(-1, id)
+			break
+		}
+		move(id, nid)
+		nr.CommitWrite(-1, nid)
+		rb.CommitRead(-1, id)
+	}
+	close(gate)
+	return nr, nil
+}
+
+// Current returns the ring that replaced rb by Migrate, following repeated
+// migrations, or rb itself if it was never migrated.
+// It is goroutine-safe.
+func (rb *RingBuffer) Current() *RingBuffer {
+	for {
+		next, _ := rb.successor.Load().(*RingBuffer)
+		if next == nil {
+			return rb
+		}
+		rb = next
+	}
+}
@@ -0,0 +1,108 @@
+package ringbuffer
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+// TestMigrateMovesInOrder migrates a full ring without readers and checks
+// the items land first in the new ring, in order, before new writes.
+func TestMigrateMovesInOrder(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(4)
+	slots := map[int][]int{4: make([]int, 4), 16: make([]int, 16)}
+	for i := 0; i < 4; i++ {
+		id, _ := rb.ReserveWrite(0)
+		slots[4][rb.BufferIndex(id)] = i
+		rb.CommitWrite(0, id)
+	}
+	nr, err := rb.Migrate(16, func(oldId, newId uint64) {
+		slots[16][newId%16] = slots[4][rb.BufferIndex(oldId)]
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rb.Current() != nr || nr.Current() != nr || nr.Size() != 16 {
+		t.Fatal("Current() is not the new ring")
+	}
+	if _, err := rb.ReserveWrite(0); !errors.Is(err, ErrClosed) {
+		t.Fatalf("ReserveWrite on the old ring: %v", err)
+	}
+	id, _ := nr.ReserveWrite(0)
+	slots[16][nr.BufferIndex(id)] = 4
+	nr.CommitWrite(0, id)
+	for i := 0; i < 5; i++ {
+		id, _ := nr.ReserveRead(0)
+		if v := slots[16][nr.BufferIndex(id)]; v != i {
+			t.Fatalf("got %d, want %d", v, i)
+		}
+		nr.CommitRead(0, id)
+	}
+	if _, err := rb.Migrate(0, nil); !errors.Is(err, ErrInvalidSize) {
+		t.Fatalf("Migrate to size 0: %v", err)
+	}
+	if rb.Current() != nr {
+		t.Fatal("failed Migrate replaced the ring")
+	}
+}
+
+// TestMigrateLive migrates a ring under producers and a consumer that get
+// the ring from Current, and checks every item is read exactly once.
+func TestMigrateLive(t *testing.T) {
+	defer parallel()()
+	const writers, n = 4, 2000
+	rb := MustNewRingBuffer(4)
+	slots := map[int][]int{4: make([]int, 4), 16: make([]int, 16), 64: make([]int, 64)}
+	var wg sync.WaitGroup
+	wg.Add(writers)
+	for w := 0; w < writers; w++ {
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < n; {
+				r := rb.Current()
+				id, err := r.ReserveWrite(w)
+				if err != nil {
+					continue //migrated, retry on Current
+				}
+				slots[r.Size()][r.BufferIndex(id)] = w*n + i
+				if r.CommitWrite(w, id) == nil {
+					i++
+				}
+			}
+		}(w)
+	}
+	migrated := make(chan struct{})
+	go func() {
+		defer close(migrated)
+		for _, size := range []int{16, 64} {
+			old := rb.Current()
+			_, err := old.Migrate(size, func(oldId, newId uint64) {
+				slots[size][newId%uint64(size)] = slots[old.Size()][old.BufferIndex(oldId)]
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}
+	}()
+	seen := make([]bool, writers*n)
+	for count := 0; count < writers*n; {
+		r := rb.Current()
+		id, err := r.ReserveRead(0)
+		if err != nil {
+			continue
+		}
+		v := slots[r.Size()][r.BufferIndex(id)]
+		r.CommitRead(0, id)
+		if seen[v] {
+			t.Fatalf("item %d read twice", v)
+		}
+		seen[v] = true
+		count++
+	}
+	wg.Wait()
+	<-migrated
+	if got := rb.Current().Size(); got != 64 {
+		t.Fatalf("Current().Size() = %d after migrating twice", got)
+	}
+}
@@ -0,0 +1,66 @@
+package ringbuffer
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// MaxSize is the largest ring size accepted by NewRingBuffer. It bounds
+// the per slot state of a ring, up to 48 bytes a slot, to what can be
+// allocated.
+const MaxSize = 1 << 28
+
+var (
+	// ErrInvalidSize reports a ring size out of [1, MaxSize].
+	ErrInvalidSize = errors.New("RingBuffer: invalid size")
+	// ErrNotReserved reports a commit of an id that was never reserved.
+	ErrNotReserved = errors.New("RingBuffer: commit of unreserved id")
+	// ErrCommitted reports a second commit of the same id.
+	ErrCommitted = errors.New("RingBuffer: id already committed")
+	// ErrBatchSize reports a batch reservation of less than one id or more
+	// than the ring size, or an empty commit range.
+	ErrBatchSize = errors.New("RingBuffer: invalid batch size")
+	// ErrClosed reports use of a closed buffer, or that a closed buffer is
+	// drained.
+	ErrClosed = errors.New("RingBuffer: closed")
+)
+
+// MisusePolicy tells a ring what to do when the API is misused.
+type MisusePolicy int
+
+const (
+	// MisuseError returns the misuse as an error and leaves the ring as it
+	// was. It is the default.
+	MisuseError MisusePolicy = iota
+	// MisusePanic panics at the misuse, failing fast.
+	MisusePanic
+)
+
+// WithMisusePolicy sets what the ring does on API misuse, such as
+// committing an id that was never reserved, which otherwise waits forever.
+func WithMisusePolicy(p MisusePolicy) Option {
+	return func(rb *RingBuffer) {
+		rb.misusePolicy = p
+	}
+}
+
+// misuse applies the misuse policy to err.
+func (rb *RingBuffer) misuse(err error) error {
+	if rb.misusePolicy == MisusePanic {
+		panic(err)
+	}
+	return err
+}
+
+// checkCommit validates a commit of id against the reserve and commit
+// cursors of one side.
+func (rb *RingBuffer) checkCommit(id uint64, reserve, commit *atomic.Uint64) error {
+	if id >= reserve.Load() {
+		return rb.misuse(fmt.Errorf("%w %d", ErrNotReserved, id))
+	}
+	if id < commit.Load() {
+		return rb.misuse(fmt.Errorf("%w %d", ErrCommitted, id))
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package ringbuffer
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestMisuseDefault pins that misuse is returned as an error by default,
+// leaving the ring usable.
+func TestMisuseDefault(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(4)
+	if err := rb.CommitWrite(0, 0); !errors.Is(err, ErrNotReserved) {
+		t.Fatalf("CommitWrite of unreserved id: %v", err)
+	}
+	id, _ := rb.ReserveWrite(0)
+	if err := rb.CommitWrite(0, id); err != nil {
+		t.Fatal(err)
+	}
+	if err := rb.CommitWrite(0, id); !errors.Is(err, ErrCommitted) {
+		t.Fatalf("second CommitWrite: %v", err)
+	}
+	if _, _, err := rb.ReserveWriteN(0, 5); !errors.Is(err, ErrBatchSize) {
+		t.Fatalf("ReserveWriteN over Size: %v", err)
+	}
+	if got, _ := rb.ReserveRead(0); got != id {
+		t.Fatalf("read %d, want %d", got, id)
+	}
+}
+
+func TestMisusePanic(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(4, WithMisusePolicy(MisusePanic))
+	defer func() {
+		if v, _ := recover().(error); !errors.Is(v, ErrNotReserved) {
+			t.Fatalf("recovered %v", v)
+		}
+	}()
+	rb.CommitWrite(0, 0)
+	t.Fatal("CommitWrite of unreserved id returned")
+}
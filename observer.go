@@ -0,0 +1,70 @@
+package ringbuffer
+
+import "time"
+
+// Observer is called back on the state transitions of a ring, to build
+// custom metrics, tracing or debugging on them, see WithObserver. Its
+// methods run on the goroutine of the operation, often in its hot path, so
+// they must be fast and must not block or use the ring. Embed NopObserver
+// to implement only some of them.
+type Observer interface {
+	// OnFull is called when wid finds the ring full: once per reserve
+	// call that then waits, and each time a WithOnFull policy drops an
+	// entry instead.
+	OnFull(wid int)
+	// OnEmpty is called once per ReserveRead call by wid that finds no
+	// entry to read and waits.
+	OnEmpty(wid int)
+	// OnReserveWait is called when op, ReserveWrite, ReserveRead or
+	// CommitRead, goes on after waiting for waited.
+	OnReserveWait(op string, wid int, waited time.Duration)
+	// OnWakeup is called each time op by wid is woken up from parking.
+	// The operation checks again and may park again, so wakeups in excess
+	// of waits are spurious.
+	OnWakeup(op string, wid int)
+	// OnCommit is called once op, CommitWrite(N) or CommitRead(N),
+	// committed ids [lo, hi].
+	OnCommit(op string, wid int, lo, hi uint64)
+}
+
+// NopObserver is an Observer doing nothing, to embed.
+type NopObserver struct{}
+
+func (NopObserver) OnFull(wid int)                                         {}
+func (NopObserver) OnEmpty(wid int)                                        {}
+func (NopObserver) OnReserveWait(op string, wid int, waited time.Duration) {}
+func (NopObserver) OnWakeup(op string, wid int)                            {}
+func (NopObserver) OnCommit(op string, wid int, lo, hi uint64)             {}
+
+// WithObserver calls o back on the state transitions of the ring.
+func WithObserver(o Observer) Option {
+	return func(rb *RingBuffer) {
+		rb.observer = o
+	}
+}
+
+func (rb *RingBuffer) observeFull(wid int) {
+	if rb.observer != nil {
+		rb.observer.OnFull(wid)
+	}
+}
+
+func (rb *RingBuffer) observeEmpty(wid int) {
+	if rb.observer != nil {
+		rb.observer.OnEmpty(wid)
+	}
+}
+
+func (rb *RingBuffer) observeWakeup(op string, wid int) {
+	if rb.observer != nil {
+		rb.observer.OnWakeup(op, wid)
+	}
+}
+
+// observeCommit reports a commit of [lo, hi] unless *err is set; it is
+// deferred with a pointer to the result.
+func (rb *RingBuffer) observeCommit(op string, wid int, lo, hi uint64, err *error) {
+	if *err == nil {
+		rb.observer.OnCommit(op, wid, lo, hi)
+	}
+}
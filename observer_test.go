@@ -0,0 +1,190 @@
+package ringbuffer
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// eventRecorder records the callbacks of an Observer as strings.
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []string
+	waited time.Duration
+}
+
+func (r *eventRecorder) add(format string, args ...interface{}) {
+	r.mu.Lock()
+	r.events = append(r.events, fmt.Sprintf(format, args...))
+	r.mu.Unlock()
+}
+
+func (r *eventRecorder) OnFull(wid int)  { r.add("full %d", wid) }
+func (r *eventRecorder) OnEmpty(wid int) { r.add("empty %d", wid) }
+func (r *eventRecorder) OnReserveWait(op string, wid int, waited time.Duration) {
+	r.add("wait %s %d", op, wid)
+	r.mu.Lock()
+	r.waited += waited
+	r.mu.Unlock()
+}
+func (r *eventRecorder) OnWakeup(op string, wid int) { r.add("wakeup %s %d", op, wid) }
+func (r *eventRecorder) OnCommit(op string, wid int, lo, hi uint64) {
+	r.add("commit %s %d [%d, %d]", op, wid, lo, hi)
+}
+
+// take returns the events recorded since the last take.
+func (r *eventRecorder) take() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e := r.events
+	r.events = nil
+	return e
+}
+
+// countEvents returns how many of events are event.
+func countEvents(events []string, event string) int {
+	n := 0
+	for _, e := range events {
+		if e == event {
+			n++
+		}
+	}
+	return n
+}
+
+func TestObserverCommits(t *testing.T) {
+	defer parallel()()
+	obs := &eventRecorder{}
+	rb := MustNewRingBuffer(4, WithObserver(obs), WithMisusePolicy(MisuseError))
+	id, _ := rb.ReserveWrite(1)
+	rb.CommitWrite(1, id)
+	lo, hi, _ := rb.ReserveWriteN(2, 2)
+	rb.CommitWriteN(2, lo, hi)
+	rb.CommitWrite(1, id) //misuse, not a commit
+	r, _ := rb.ReserveRead(3)
+	rb.CommitRead(3, r)
+	lo, hi, _ = rb.ReserveReadN(4, 2)
+	rb.CommitReadN(4, lo, hi)
+	want := []string{
+		"commit CommitWrite 1 [0, 0]",
+		"commit CommitWriteN 2 [1, 2]",
+		"commit CommitRead 3 [0, 0]",
+		"commit CommitReadN 4 [1, 2]",
+	}
+	if got := obs.take(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("events %q, want %q", got, want)
+	}
+}
+
+// TestObserverWaits pins the full, empty, wakeup and wait callbacks of
+// writers and readers that park.
+func TestObserverWaits(t *testing.T) {
+	defer parallel()()
+	obs := &eventRecorder{}
+	rb := MustNewRingBuffer(1, WithObserver(obs), WithWaitStrategy(BlockingWaitStrategy{}))
+	id, _ := rb.ReserveWrite(0)
+	rb.CommitWrite(0, id)
+	obs.take()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		id, _ := rb.ReserveWrite(7)
+		rb.CommitWrite(7, id)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	r, _ := rb.ReserveRead(0)
+	rb.CommitRead(0, r)
+	<-done
+	events := obs.take()
+	if n := countEvents(events, "full 7"); n != 1 {
+		t.Fatalf("OnFull called %d times for one waiting reserve: %q", n, events)
+	}
+	if n := countEvents(events, "wait ReserveWrite 7"); n != 1 {
+		t.Fatalf("OnReserveWait called %d times: %q", n, events)
+	}
+	if n := countEvents(events, "wakeup ReserveWrite 7"); n < 1 {
+		t.Fatalf("no OnWakeup of the parked writer: %q", events)
+	}
+	if obs.waited < 10*time.Millisecond {
+		t.Fatalf("waited %v, parked for 20ms", obs.waited)
+	}
+
+	r, _ = rb.ReserveRead(0)
+	rb.CommitRead(0, r)
+	obs.take()
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		id, _ := rb.ReserveWrite(0)
+		rb.CommitWrite(0, id)
+	}()
+	rb.ReserveRead(5)
+	events = obs.take()
+	if n := countEvents(events, "empty 5"); n != 1 {
+		t.Fatalf("OnEmpty called %d times for one waiting read: %q", n, events)
+	}
+	if n := countEvents(events, "wait ReserveRead 5"); n != 1 {
+		t.Fatalf("OnReserveWait of the reader called %d times: %q", n, events)
+	}
+}
+
+// TestObserverBatchWaits checks the batch calls report their waits like
+// the single id ones.
+func TestObserverBatchWaits(t *testing.T) {
+	defer parallel()()
+	obs := &eventRecorder{}
+	in := &opCounter{ops: map[string]int{}}
+	rb := MustNewRingBuffer(2, WithObserver(obs), WithInstrumentation(in), WithWaitStrategy(BlockingWaitStrategy{}))
+	lo, hi, _ := rb.ReserveWriteN(0, 2)
+	rb.CommitWriteN(0, lo, hi)
+	obs.take()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		lo, hi, _ := rb.ReserveWriteN(7, 2)
+		rb.CommitWriteN(7, lo, hi)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	lo, hi, _ = rb.ReserveReadN(0, 2)
+	rb.CommitReadN(0, lo, hi)
+	<-done
+	events := obs.take()
+	for _, e := range []string{"full 7", "wait ReserveWriteN 7"} {
+		if n := countEvents(events, e); n != 1 {
+			t.Fatalf("%q reported %d times: %q", e, n, events)
+		}
+	}
+	if n := countEvents(events, "wakeup ReserveWriteN 7"); n < 1 {
+		t.Fatalf("no OnWakeup of the parked writer: %q", events)
+	}
+
+	lo, hi, _ = rb.ReserveReadN(0, 2)
+	rb.CommitReadN(0, lo, hi)
+	obs.take()
+	done = make(chan struct{})
+	go func() {
+		defer close(done)
+		time.Sleep(20 * time.Millisecond)
+		lo, hi, _ := rb.ReserveWriteN(0, 1)
+		rb.CommitWriteN(0, lo, hi)
+	}()
+	rb.ReserveReadN(5, 2)
+	<-done
+	events = obs.take()
+	for _, e := range []string{"empty 5", "wait ReserveReadN 5"} {
+		if n := countEvents(events, e); n != 1 {
+			t.Fatalf("%q reported %d times: %q", e, n, events)
+		}
+	}
+	s := rb.Stats()
+	if s.ReserveWriteWaits.Count != 1 || s.ReserveReadWaits.Count != 1 {
+		t.Fatalf("waits not counted: %+v", s)
+	}
+	for _, op := range []string{OpReserveWriteN, OpCommitWriteN, OpReserveReadN, OpCommitReadN} {
+		if in.ops[op] == 0 {
+			t.Fatalf("%s not instrumented: %v", op, in.ops)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package ringbuffer
+
+import (
+	"time"
+)
+
+// Option configures a RingBuffer at construction.
+type Option func(*RingBuffer)
+
+// WithWaitStrategy sets the strategy used by readers and writers while they
+// wait. Default is BlockingWaitStrategy.
+func WithWaitStrategy(ws WaitStrategy) Option {
+	return func(rb *RingBuffer) {
+		rb.waitStrategy.Store(strategyBox{ws})
+	}
+}
+
+// WithIdleWaitStrategy switches readers to ws once the ring has seen no
+// write for period, and back to the normal strategy on the next write.
+// It lets a busy-spinning consumer sleep down during quiet hours.
+// NewRingBuffer fails if ws is nil.
+func WithIdleWaitStrategy(period time.Duration, ws WaitStrategy) Option {
+	return func(rb *RingBuffer) {
+		rb.idlePeriod = period
+		rb.idleStrategy = ws
+	}
+}
+
+// WithYield sets the function spinning wait strategies call to give up the
+// processor, runtime.Gosched by default. Frameworks with their own task
+// scheduler (game loops, fibers) can hook the ring's waiting into it.
+func WithYield(yield func()) Option {
+	return func(rb *RingBuffer) {
+		rb.yield = yield
+	}
+}
+
+// WithName names the ring. The name is included in logs, Stats and Show.
+func WithName(name string) Option {
+	return func(rb *RingBuffer) {
+		rb.name = name
+	}
+}
+
+// WithLabels attaches key/value labels to the ring, given as pairs
+// key1, value1, key2, value2...
+// Labels are included in logs, Stats and Show.
+func WithLabels(kv ...string) Option {
+	return func(rb *RingBuffer) {
+		if rb.labels == nil {
+			rb.labels = make(map[string]string, len(kv)/2)
+		}
+		for i := 0; i+1 < len(kv); i += 2 {
+			rb.labels[kv[i]] = kv[i+1]
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package ringbuffer
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+var orderings = []Ordering{OrderStrict, OrderRelaxed}
+
+// TestOrderingReadFIFO pins the guarantees shared by both modes: readers
+// reserve ids in write order, each id once, and a single reader consumes
+// in FIFO order.
+func TestOrderingReadFIFO(t *testing.T) {
+	defer parallel()()
+	for _, o := range orderings {
+		const readers, n = 4, 4000
+		rb := MustNewRingBuffer(8, WithOrdering(o))
+		if rb.Ordering() != o {
+			t.Fatalf("%v: Ordering() = %v", o, rb.Ordering())
+		}
+		slots := make([]uint64, rb.Slots())
+		go func() {
+			for i := uint64(0); i < n; i++ {
+				id, _ := rb.ReserveWrite(0)
+				slots[rb.BufferIndex(id)] = i
+				rb.CommitWrite(0, id)
+			}
+		}()
+		seen := make([]int32, n)
+		var wg sync.WaitGroup
+		wg.Add(readers)
+		for r := 0; r < readers; r++ {
+			go func(r int) {
+				defer wg.Done()
+				next := uint64(0)
+				for i := 0; i < n/readers; i++ {
+					id, _ := rb.ReserveRead(r)
+					v := slots[rb.BufferIndex(id)]
+					if v != id || id < next {
+						t.Errorf("%v: reader %d got %d at id %d after id %d", o, r, v, id, next)
+					}
+					next = id + 1
+					seen[v]++
+					rb.CommitRead(r, id)
+				}
+			}(r)
+		}
+		wg.Wait()
+		for v, c := range seen {
+			if c != 1 {
+				t.Fatalf("%v: item %d read %d times", o, v, c)
+			}
+		}
+	}
+}
+
+// TestOrderingCommitRead pins where the modes differ: a reader finishing
+// id 1 before id 0 waits in CommitRead under OrderStrict only, while the
+// read commit, and so the room of writers, moves in id order in both.
+func TestOrderingCommitRead(t *testing.T) {
+	defer parallel()()
+	for _, o := range orderings {
+		rb := MustNewRingBuffer(2, WithOrdering(o))
+		for i := 0; i < 2; i++ {
+			id, _ := rb.ReserveWrite(0)
+			rb.CommitWrite(0, id)
+		}
+		first, _ := rb.ReserveRead(0)
+		second, _ := rb.ReserveRead(1)
+
+		returned := make(chan struct{})
+		go func() {
+			rb.CommitRead(1, second)
+			close(returned)
+		}()
+		select {
+		case <-returned:
+			if o == OrderStrict {
+				t.Fatalf("%v: CommitRead of id %d returned before id %d", o, second, first)
+			}
+		case <-time.After(50 * time.Millisecond):
+			if o == OrderRelaxed {
+				t.Fatalf("%v: CommitRead of id %d waits for id %d", o, second, first)
+			}
+		}
+		if got := rb.Stats().ReadCommit; got != first {
+			t.Fatalf("%v: read commit %d before id %d is committed", o, got, first)
+		}
+		if _, ok := rb.TryReserveWrite(0); ok {
+			t.Fatalf("%v: slot reused before id %d is committed", o, first)
+		}
+
+		rb.CommitRead(0, first)
+		<-returned
+		if got := rb.Stats().ReadCommit; got != second+1 {
+			t.Fatalf("%v: read commit %d, want %d", o, got, second+1)
+		}
+	}
+}
+
+func TestOrderingInvalid(t *testing.T) {
+	defer parallel()()
+	if _, err := NewRingBuffer(2, WithOrdering(Ordering(2))); err == nil {
+		t.Fatal("unknown Ordering accepted")
+	}
+}
@@ -0,0 +1,14 @@
+module ringbuffer/otelring
+
+go 1.25.0
+
+replace ringbuffer => ../
+
+require (
+	go.opentelemetry.io/otel v1.46.0
+	go.opentelemetry.io/otel/metric v1.46.0
+	go.opentelemetry.io/otel/trace v1.46.0
+	ringbuffer v0.0.0-00010101000000-000000000000
+)
+
+require github.com/cespare/xxhash/v2 v2.3.0 // indirect
@@ -0,0 +1,90 @@
+// Package otelring reports the operations of a ringbuffer to OpenTelemetry,
+// as spans and histograms. It is a separate module so ringbuffer itself
+// stays free of dependencies.
+//
+//	in, err := otelring.New("orders", otel.GetTracerProvider(), otel.GetMeterProvider())
+//	rb, err := ringbuffer.NewRingBuffer(1024, ringbuffer.WithInstrumentation(in))
+package otelring
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"ringbuffer"
+)
+
+const scope = "ringbuffer/otelring"
+
+type instrumentation struct {
+	tracer  trace.Tracer
+	ops     metric.Float64Histogram
+	latency metric.Float64Histogram
+	ring    attribute.KeyValue
+}
+
+// New returns an Instrumentation recording a span and a
+// ringbuffer.op.duration sample per operation, and a
+// ringbuffer.publish.latency sample per event read, the time from its
+// publish to ReserveRead. All carry the attribute ringbuffer.name=name.
+// Spans are only started for operations that wait or fail, as a span per
+// uncontended operation would cost more than the operation. A nil tp or mp
+// disables spans or metrics.
+func New(name string, tp trace.TracerProvider, mp metric.MeterProvider) (ringbuffer.Instrumentation, error) {
+	in := &instrumentation{ring: attribute.String("ringbuffer.name", name)}
+	if tp != nil {
+		in.tracer = tp.Tracer(scope)
+	}
+	if mp != nil {
+		m := mp.Meter(scope)
+		var err error
+		if in.ops, err = m.Float64Histogram("ringbuffer.op.duration",
+			metric.WithUnit("s"),
+			metric.WithDescription("Duration of ring operations, waits included.")); err != nil {
+			return nil, err
+		}
+		if in.latency, err = m.Float64Histogram("ringbuffer.publish.latency",
+			metric.WithUnit("s"),
+			metric.WithDescription("Time from an event's publish to its read.")); err != nil {
+			return nil, err
+		}
+	}
+	return in, nil
+}
+
+// spanThreshold is the shortest operation given a span; shorter ones didn't
+// wait.
+const spanThreshold = 50 * time.Microsecond
+
+func (in *instrumentation) Op(op string, wid int, id uint64, start time.Time, err error) {
+	end := time.Now()
+	d := end.Sub(start)
+	if in.ops != nil {
+		in.ops.Record(context.Background(), d.Seconds(),
+			metric.WithAttributes(in.ring, attribute.String("ringbuffer.op", op), attribute.Bool("error", err != nil)))
+	}
+	if in.tracer == nil || (err == nil && d < spanThreshold) {
+		return
+	}
+	_, span := in.tracer.Start(context.Background(), "ringbuffer."+op,
+		trace.WithTimestamp(start),
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(in.ring,
+			attribute.Int("ringbuffer.wid", wid),
+			attribute.Int64("ringbuffer.id", int64(id))))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End(trace.WithTimestamp(end))
+}
+
+func (in *instrumentation) Latency(wid int, id uint64, d time.Duration) {
+	if in.latency != nil {
+		in.latency.Record(context.Background(), d.Seconds(), metric.WithAttributes(in.ring))
+	}
+}
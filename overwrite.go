@@ -0,0 +1,62 @@
+package ringbuffer
+
+// WithOverwrite makes a full ring drop its oldest unread entry to make room
+// for a writer instead of blocking it, e.g. for telemetry keeping the last
+// events where the producer must never stall. Dropped entries are counted in
+// Stats.Overwritten.
+// A writer never overwrites a slot a reader holds: entries reserved for read
+// are committed by their reader first, so a writer only waits for readers
+// in hand, and for earlier writers to publish. It has no effect on rings
+// created WithBroadcast.
+// It is WithOnFull(FullDropOldest).
+func WithOverwrite() Option {
+	return WithOnFull(FullDropOldest)
+}
+
+// canOverwrite reports whether a writer that found rb full at write commit c
+// should check again, as entries were published since that it may drop.
+func (rb *RingBuffer) canOverwrite(c uint64) bool {
+	return rb.overwrite && rb.wCommit.Load() != c
+}
+
+// dropOldest takes the oldest unread entry and discards it. It reports
+// whether the caller should check again: false if no published entry is
+// left unread.
+// The entry is marked done like a relaxed read commit, so the writer never
+// waits for the readers still holding earlier entries: the read commit
+// moves over it once they commit. Drops are not reported as read commits.
+func (rb *RingBuffer) dropOldest() bool {
+	if !rb.overwrite || rb.broadcast != nil {
+		return false
+	}
+	r := rb.rReserve.Load()
+	if r >= rb.wCommit.Load() {
+		return false
+	}
+	if !rb.rReserve.CompareAndSwap(r, r+1) { //lost to a reader or another writer
+		return true
+	}
+	if rb.audit != nil {
+		rb.audit.reserved(r)
+	}
+	if m, ok := rb.tombs.take(r); ok {
+		if m == markBarrier && rb.onBarrier != nil {
+			rb.onBarrier(-1, r)
+		}
+	} else {
+		rb.overwritten.Add(1)
+	}
+	rb.commitReadRelaxed(r)
+	return true
+}
+
+// commitDropped moves the read commit over the entries dropped by writers
+// that follow it, once a reader committed up to them.
+func (rb *RingBuffer) commitDropped() {
+	for {
+		r := rb.rCommit.Load()
+		if rb.readDone[rb.BufferIndex(r)].Load() != r+1 || !rb.casCommitRead(r, r) {
+			return
+		}
+	}
+}
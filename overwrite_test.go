@@ -0,0 +1,161 @@
+package ringbuffer
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type commitRecorder struct {
+	NopObserver
+	mu   sync.Mutex
+	wids []int
+}
+
+func (c *commitRecorder) OnCommit(op string, wid int, lo, hi uint64) {
+	c.mu.Lock()
+	c.wids = append(c.wids, wid)
+	c.mu.Unlock()
+}
+
+// TestOverwriteKeepsNewest fills a ring far past its size without readers
+// and checks the writer never blocks and the newest entries are kept, in
+// order.
+func TestOverwriteKeepsNewest(t *testing.T) {
+	defer parallel()()
+	const size, n = 4, 10
+	rb := MustNewRingBuffer(size, WithOverwrite())
+	slots := make([]int, rb.Slots())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < n; i++ {
+			id, _ := rb.ReserveWrite(0)
+			slots[rb.BufferIndex(id)] = i
+			rb.CommitWrite(0, id)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("writer blocked on a full ring")
+	}
+	if got := rb.Stats().Overwritten; got != n-size {
+		t.Fatalf("%d overwritten, want %d", got, n-size)
+	}
+	for want := n - size; want < n; want++ {
+		id, _ := rb.ReserveRead(0)
+		if v := slots[rb.BufferIndex(id)]; v != want {
+			t.Fatalf("read %d, want %d", v, want)
+		}
+		rb.CommitRead(0, id)
+	}
+	if _, ok := rb.TryReserveRead(0); ok {
+		t.Fatal("dropped entry read")
+	}
+}
+
+func TestOverwriteDoesNotWaitForReaders(t *testing.T) {
+	defer parallel()()
+	obs := &commitRecorder{}
+	rb := MustNewRingBuffer(2, WithOverwrite(), WithObserver(obs))
+	for i := 0; i < 2; i++ {
+		id, _ := rb.ReserveWrite(0)
+		rb.CommitWrite(0, id)
+	}
+	held, _ := rb.ReserveRead(1)
+
+	done := make(chan bool)
+	go func() {
+		_, ok := rb.TryReserveWrite(0)
+		done <- ok
+	}()
+	select {
+	case ok := <-done:
+		if ok {
+			t.Fatal("reserved the slot of a held entry")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("TryReserveWrite waits for a reader")
+	}
+	if got := rb.Stats().Overwritten; got != 1 {
+		t.Fatalf("%d overwritten, want 1", got)
+	}
+
+	rb.CommitRead(1, held)
+	if got := rb.Stats().ReadCommit; got != 2 {
+		t.Fatalf("read commit %d, want 2 past the dropped entry", got)
+	}
+	if _, ok := rb.TryReserveWrite(0); !ok {
+		t.Fatal("no room after the held entry was committed")
+	}
+	for _, wid := range obs.wids {
+		if wid < 0 {
+			t.Fatal("a drop was reported as a commit")
+		}
+	}
+}
+
+func TestOverwriteConcurrent(t *testing.T) {
+	defer parallel()()
+	const writers, n = 4, 3000
+	rb := MustNewRingBuffer(4, WithOverwrite())
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				id, err := rb.ReserveWrite(w)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				rb.CommitWrite(w, id)
+			}
+		}(w)
+	}
+	var read atomic.Uint64
+	stop := make(chan struct{})
+	readers := sync.WaitGroup{}
+	for r := 0; r < 2; r++ {
+		readers.Add(1)
+		go func(r int) {
+			defer readers.Done()
+			for {
+				id, ok := rb.TryReserveRead(writers + r)
+				if !ok {
+					select {
+					case <-stop:
+						return
+					default:
+						time.Sleep(time.Microsecond)
+						continue
+					}
+				}
+				read.Add(1)
+				rb.CommitRead(writers+r, id)
+			}
+		}(r)
+	}
+	wg.Wait()
+	time.Sleep(10 * time.Millisecond)
+	close(stop)
+	readers.Wait()
+	for {
+		id, ok := rb.TryReserveRead(0)
+		if !ok {
+			break
+		}
+		read.Add(1)
+		rb.CommitRead(0, id)
+	}
+	s := rb.Stats()
+	if got := read.Load() + s.Overwritten; got != writers*n {
+		t.Fatalf("%d read + %d overwritten, want %d", read.Load(), s.Overwritten, writers*n)
+	}
+	if s.ReadCommit != s.WriteCommit {
+		t.Fatalf("read commit %d, write commit %d", s.ReadCommit, s.WriteCommit)
+	}
+}
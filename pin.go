@@ -0,0 +1,82 @@
+package ringbuffer
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// pins tracks read ids whose slots must not be reused yet.
+type pins struct {
+	mu    sync.Mutex
+	held  map[uint64]*time.Timer // pinned id -> expiry timer
+	count atomic.Int32           // len(held), hot path guard
+}
+
+// Pin keeps the slot of read id from being reused after CommitRead, until
+// Unpin or until d has passed, whichever is first. Aggregation consumers can
+// so keep referencing slot data briefly without copying it, while the read
+// commit, and other readers, go on. Writers that reach the slot wait.
+// id must be reserved for read and not committed yet; pinning it again
+// extends the deadline.
+// It is goroutine-safe.
+func (rb *RingBuffer) Pin(id uint64, d time.Duration) error {
+	if err := rb.checkCommit(id, &rb.rReserve, &rb.rCommit); err != nil {
+		return err
+	}
+	p := &rb.pins
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.held == nil {
+		p.held = make(map[uint64]*time.Timer)
+	}
+	if t, ok := p.held[id]; ok {
+		t.Reset(d)
+		return nil
+	}
+	p.held[id] = time.AfterFunc(d, func() { rb.Unpin(id) })
+	p.count.Add(1)
+	return nil
+}
+
+// Unpin releases the pin of read id, letting writers reuse its slot.
+// Unpinning an id that is not pinned, e.g. after its deadline, does nothing.
+// It is goroutine-safe.
+func (rb *RingBuffer) Unpin(id uint64) {
+	p := &rb.pins
+	p.mu.Lock()
+	t, ok := p.held[id]
+	if ok {
+		t.Stop()
+		delete(p.held, id)
+		p.count.Add(-1)
+	}
+	p.mu.Unlock()
+	if ok {
+		rb.waitWriteR.wakeAll()
+	}
+}
+
+// Pinned reports the number of pinned slots.
+// It is goroutine-safe.
+func (rb *RingBuffer) Pinned() int {
+	return int(rb.pins.count.Load())
+}
+
+// readFloor returns the lowest read id whose slot is still in use: the read
+// commit, or the lowest pinned id below it.
+func (rb *RingBuffer) readFloor() uint64 {
+	floor := rb.readCommit()
+	if rb.pins.count.Load() == 0 {
+		return floor
+	}
+	p := &rb.pins
+	p.mu.Lock()
+	for id := range p.held {
+		if id < floor {
+			floor = id
+		}
+	}
+	p.mu.Unlock()
+	return floor
+}
@@ -0,0 +1,58 @@
+package ringbuffer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPin(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(2)
+	fill(t, rb)
+	if err := rb.Pin(0, time.Second); err == nil {
+		t.Fatal("Pin of an id not reserved for read succeeded")
+	}
+	r, _ := rb.ReserveRead(1)
+	if err := rb.Pin(r, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	rb.CommitRead(1, r)
+	if rb.Pinned() != 1 {
+		t.Fatalf("%d pinned, want 1", rb.Pinned())
+	}
+	if _, ok := rb.TryReserveWrite(0); ok {
+		t.Fatal("writer reused a pinned slot")
+	}
+	rb.Unpin(r)
+	id, ok := rb.TryReserveWrite(0)
+	if !ok {
+		t.Fatal("writer waits after Unpin")
+	}
+	rb.CommitWrite(0, id)
+	rb.Unpin(r) //not pinned anymore, does nothing
+	if rb.Pinned() != 0 {
+		t.Fatalf("%d pinned after Unpin", rb.Pinned())
+	}
+}
+
+// TestPinDeadline checks an expired pin lets a waiting writer go on.
+func TestPinDeadline(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(2)
+	fill(t, rb)
+	r, _ := rb.ReserveRead(1)
+	rb.Pin(r, 20*time.Millisecond)
+	rb.CommitRead(1, r)
+	start := time.Now()
+	id, err := rb.ReserveWrite(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rb.CommitWrite(0, id)
+	if waited := time.Since(start); waited < 10*time.Millisecond {
+		t.Fatalf("writer reused the pinned slot after %v", waited)
+	}
+	if rb.Pinned() != 0 {
+		t.Fatalf("%d pinned after the deadline", rb.Pinned())
+	}
+}
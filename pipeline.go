@@ -0,0 +1,84 @@
+package ringbuffer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Pipeline runs the producers, stages and consumers of a ring pipeline as
+// one unit, like an errgroup: the first error cancels the rest and Run
+// returns it once every stage has stopped.
+type Pipeline struct {
+	stages   []pipelineStage
+	onCancel []func()
+}
+
+type pipelineStage struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// Stage adds fn to run on its own goroutine. It should return when ctx is
+// done; the error it returns, if any, stops the pipeline.
+// It is not goroutine-safe and must be called before Run.
+func (p *Pipeline) Stage(name string, fn func(ctx context.Context) error) {
+	p.stages = append(p.stages, pipelineStage{name: name, fn: fn})
+}
+
+// OnCancel adds fn to call once the pipeline is cancelled, e.g. to publish
+// stop markers that wake stages parked on an empty ring.
+// It is not goroutine-safe and must be called before Run.
+func (p *Pipeline) OnCancel(fn func()) {
+	p.onCancel = append(p.onCancel, fn)
+}
+
+// Run starts every stage and waits for all of them to return.
+// When a stage fails or ctx is done, the context of every stage is
+// cancelled and the OnCancel functions are called. Run returns the first
+// stage error, wrapped with the stage name, or ctx.Err() if ctx ended the
+// pipeline, or nil once every stage returned nil on its own.
+func (p *Pipeline) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		err      error
+		stopOnce sync.Once
+	)
+	stop := func() {
+		stopOnce.Do(func() {
+			cancel()
+			for _, fn := range p.onCancel {
+				fn()
+			}
+		})
+	}
+	wg.Add(len(p.stages))
+	for _, s := range p.stages {
+		s := s
+		go func() {
+			defer wg.Done()
+			if e := s.fn(ctx); e != nil {
+				errOnce.Do(func() { err = fmt.Errorf("RingBuffer: stage %s: %w", s.name, e) })
+				stop()
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		stop()
+		<-done
+	}
+	errOnce.Do(func() { err = ctx.Err() })
+	return err
+}
@@ -0,0 +1,73 @@
+package ringbuffer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestPipelineStageError checks a failing stage cancels the others,
+// including a producer parked on a full ring woken by OnCancel.
+func TestPipelineStageError(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(2)
+	boom := errors.New("boom")
+	var p Pipeline
+	p.Stage("producer", func(ctx context.Context) error {
+		for {
+			id, err := rb.ReserveWrite(0)
+			if err != nil {
+				return nil //closed by OnCancel
+			}
+			rb.CommitWrite(0, id)
+		}
+	})
+	p.Stage("consumer", func(ctx context.Context) error {
+		id, _ := rb.ReserveRead(1)
+		rb.CommitRead(1, id)
+		return boom
+	})
+	p.Stage("watcher", func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+	p.OnCancel(func() { rb.Close() })
+	err := p.Run(context.Background())
+	if !errors.Is(err, boom) || !strings.Contains(err.Error(), "consumer") {
+		t.Fatalf("Run: %v, want boom from the consumer", err)
+	}
+	if !rb.Closed() {
+		t.Fatal("OnCancel not called")
+	}
+}
+
+func TestPipelineDone(t *testing.T) {
+	defer parallel()()
+	var p Pipeline
+	cancelled := false
+	p.Stage("a", func(context.Context) error { return nil })
+	p.Stage("b", func(context.Context) error { return nil })
+	p.OnCancel(func() { cancelled = true })
+	if err := p.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if cancelled {
+		t.Fatal("OnCancel called for a pipeline that ended on its own")
+	}
+}
+
+func TestPipelineContext(t *testing.T) {
+	defer parallel()()
+	var p Pipeline
+	p.Stage("a", func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := p.Run(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Run: %v, want the ctx error", err)
+	}
+}
@@ -0,0 +1,74 @@
+package ringbuffer
+
+// Pool is a fixed size pool of *T on a ring, a lower variance alternative to
+// sync.Pool where GC driven eviction is undesirable: it is filled up front
+// and never drops or allocates objects, Get waits for one to be Put back
+// instead.
+type Pool[T any] struct {
+	ring *TypedRingBuffer[*T]
+}
+
+// NewPool returns a Pool of size objects made by fill. opts configure its
+// ring, e.g. WithWaitStrategy, or WithSingleWriter for a Pool that a
+// single goroutine Puts to. It fails where NewTypedRingBuffer does.
+func NewPool[T any](size int, fill func() *T, opts ...Option) (*Pool[T], error) {
+	ring, err := NewTypedRingBuffer[*T](size, opts...)
+	if err != nil {
+		return nil, err
+	}
+	p := &Pool[T]{ring: ring}
+	for i := 0; i < size; i++ {
+		p.Put(fill())
+	}
+	return p, nil
+}
+
+// MustNewPool is NewPool panicking on error.
+func MustNewPool[T any](size int, fill func() *T, opts ...Option) *Pool[T] {
+	p, err := NewPool(size, fill, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// Get takes an object from the pool, waiting while every object is in use.
+// It returns ErrClosed once the pool is closed.
+// It is goroutine-safe.
+func (p *Pool[T]) Get() (*T, error) {
+	return p.ring.Get()
+}
+
+// TryGet takes an object from the pool, or returns false at once if every
+// object is in use.
+// It is goroutine-safe.
+func (p *Pool[T]) TryGet() (*T, bool) {
+	id, ok := p.ring.rb.TryReserveRead(-1)
+	if !ok {
+		return nil, false
+	}
+	x := *p.ring.Slot(id)
+	p.ring.CommitRead(-1, id)
+	return x, true
+}
+
+// Put returns x to the pool, and reports whether the pool kept it. x should
+// be reset by the caller. Objects beyond the size of the pool, which were
+// not taken from it, and objects put after Close are dropped.
+// It is goroutine-safe.
+func (p *Pool[T]) Put(x *T) bool {
+	id, ok := p.ring.rb.TryReserveWrite(-1)
+	if !ok {
+		return false
+	}
+	*p.ring.Slot(id) = x
+	p.ring.CommitWrite(-1, id)
+	return true
+}
+
+// Close closes the pool: Get and TryGet fail and Put drops objects, which
+// are left to the GC with the ones still in the pool.
+// It is goroutine-safe.
+func (p *Pool[T]) Close() error {
+	return p.ring.rb.Close()
+}
@@ -0,0 +1,74 @@
+package ringbuffer
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestPool(t *testing.T) {
+	defer parallel()()
+	made := 0
+	p := MustNewPool(2, func() *int { made++; return new(int) })
+	if made != 2 {
+		t.Fatalf("%d objects made, want 2", made)
+	}
+	a, err := p.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := p.Get()
+	if a == b {
+		t.Fatal("same object taken twice")
+	}
+	if _, ok := p.TryGet(); ok {
+		t.Fatal("TryGet on an empty pool succeeded")
+	}
+	if !p.Put(a) {
+		t.Fatal("Put of a taken object dropped")
+	}
+	if x, ok := p.TryGet(); !ok || x != a {
+		t.Fatalf("TryGet: %p, %v, want %p", x, ok, a)
+	}
+	p.Put(a)
+	p.Put(b)
+	if p.Put(new(int)) {
+		t.Fatal("Put beyond the pool size kept")
+	}
+}
+
+// TestPoolWaits checks Get waits for a Put while every object is in use.
+func TestPoolWaits(t *testing.T) {
+	defer parallel()()
+	p := MustNewPool(1, func() *int { return new(int) })
+	x, _ := p.Get()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		y, err := p.Get()
+		if err != nil || y != x {
+			t.Errorf("Get: %p, %v, want %p", y, err, x)
+		}
+	}()
+	p.Put(x)
+	wg.Wait()
+}
+
+func TestPoolClose(t *testing.T) {
+	defer parallel()()
+	p := MustNewPool(2, func() *int { return new(int) })
+	x, _ := p.Get()
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if y, err := p.Get(); y != nil || !errors.Is(err, ErrClosed) {
+		t.Fatalf("Get after Close: %p, %v", y, err)
+	}
+	if _, ok := p.TryGet(); ok {
+		t.Fatal("TryGet after Close succeeded")
+	}
+	if p.Put(x) {
+		t.Fatal("Put after Close kept the object")
+	}
+}
@@ -0,0 +1,186 @@
+package ringbuffer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrRunning reports a Start of a processor that was already started.
+var ErrRunning = errors.New("RingBuffer: processor already started")
+
+// EventHandler handles item id of a batch; endOfBatch is true for the last
+// item of the batch, e.g. to flush a writer. item is valid until the
+// handler returns.
+type EventHandler[T any] func(id uint64, item *T, endOfBatch bool)
+
+// BatchEventProcessor owns the read loop of a consumer: it reserves every
+// item available at once, hands them to its EventHandler and commits them,
+// like the batch event processor of the Disruptor. Several processors with
+// distinct wids may share a ring, each item goes to one of them.
+type BatchEventProcessor[T any] struct {
+	ring     *TypedRingBuffer[T]
+	wid      int
+	handler  EventHandler[T]
+	minBatch int          // 0 unless WithAdaptiveBatch
+	maxBatch int          // at most Size
+	batch    atomic.Int64 // items reserved at most per batch
+
+	mu     sync.Mutex
+	cancel context.CancelFunc // nil until Start
+	done   chan struct{}      // closed once the loop returned
+	err    error              // why the loop returned
+}
+
+// ProcessorOption configures a BatchEventProcessor.
+type ProcessorOption func(*processorConfig)
+
+type processorConfig struct {
+	minBatch, maxBatch int
+}
+
+// WithAdaptiveBatch makes a processor size its batches by the depth of the
+// ring, between lo and hi items: the batch limit doubles while items are
+// left behind after a batch, trading latency for throughput when the ring
+// is deep, and halves once the ring is shallow, so items are handed over
+// promptly. Without it a batch takes every available item, up to Size.
+func WithAdaptiveBatch(lo, hi int) ProcessorOption {
+	return func(c *processorConfig) {
+		if lo < 1 {
+			lo = 1
+		}
+		if hi < lo {
+			hi = lo
+		}
+		c.minBatch, c.maxBatch = lo, hi
+	}
+}
+
+// NewBatchEventProcessor returns a processor calling handler with the items
+// of ring, reading as wid. It does nothing before Start.
+func NewBatchEventProcessor[T any](ring *TypedRingBuffer[T], wid int, handler EventHandler[T], opts ...ProcessorOption) *BatchEventProcessor[T] {
+	var c processorConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+	p := &BatchEventProcessor[T]{ring: ring, wid: wid, handler: handler, done: make(chan struct{})}
+	p.minBatch, p.maxBatch = c.minBatch, c.maxBatch
+	if p.maxBatch > ring.rb.Size() {
+		p.maxBatch = ring.rb.Size()
+	}
+	if p.minBatch > p.maxBatch {
+		p.minBatch = p.maxBatch
+	}
+	p.batch.Store(int64(ring.rb.Size()))
+	if p.minBatch > 0 {
+		p.batch.Store(int64(p.minBatch))
+	}
+	return p
+}
+
+// Start runs the read loop on a new goroutine, until Halt or the ring is
+// closed. A processor starts once, later calls return ErrRunning.
+// It is goroutine-safe.
+func (p *BatchEventProcessor[T]) Start() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cancel != nil {
+		return ErrRunning
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	p.ring.rb.Go("processor", p.wid, func() {
+		defer close(p.done)
+		p.err = p.run(ctx)
+	})
+	return nil
+}
+
+// Halt stops the read loop after the batch in hand, and waits for it.
+// Items of the batch are all handled and committed.
+// It is goroutine-safe.
+func (p *BatchEventProcessor[T]) Halt() {
+	p.mu.Lock()
+	cancel := p.cancel
+	p.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-p.done
+}
+
+// Done returns a channel closed once the read loop returned, after Halt or
+// once the ring is closed.
+func (p *BatchEventProcessor[T]) Done() <-chan struct{} {
+	return p.done
+}
+
+// Err returns why the read loop returned: ErrClosed if the ring was closed,
+// context.Canceled after Halt. It is nil while the loop runs.
+// It is goroutine-safe.
+func (p *BatchEventProcessor[T]) Err() error {
+	select {
+	case <-p.done:
+		return p.err
+	default:
+		return nil
+	}
+}
+
+// BatchSize returns the most items the processor reserves per batch. It
+// varies under WithAdaptiveBatch.
+// It is goroutine-safe.
+func (p *BatchEventProcessor[T]) BatchSize() int {
+	return int(p.batch.Load())
+}
+
+// adapt sizes the next batch by the items left in the ring after one,
+// see WithAdaptiveBatch.
+func (p *BatchEventProcessor[T]) adapt() {
+	if p.minBatch == 0 {
+		return
+	}
+	n := p.batch.Load()
+	switch left := int64(p.ring.rb.ApproxLen()); {
+	case left >= n && n < int64(p.maxBatch):
+		n *= 2
+		if n > int64(p.maxBatch) {
+			n = int64(p.maxBatch)
+		}
+	case left < n/4 && n > int64(p.minBatch):
+		n /= 2
+		if n < int64(p.minBatch) {
+			n = int64(p.minBatch)
+		}
+	}
+	p.batch.Store(n)
+}
+
+func (p *BatchEventProcessor[T]) run(ctx context.Context) error {
+	rb := p.ring.rb
+	batch := make([]uint64, 0, rb.Size())
+	for {
+		id, err := rb.ReserveReadContext(ctx, p.wid)
+		if err != nil {
+			return err
+		}
+		limit := p.BatchSize()
+		batch = append(batch[:0], id)
+		for len(batch) < limit {
+			id, ok := rb.TryReserveRead(p.wid)
+			if !ok {
+				break
+			}
+			batch = append(batch, id)
+		}
+		for i, id := range batch {
+			p.handler(id, p.ring.Slot(id), i == len(batch)-1)
+		}
+		for _, id := range batch {
+			p.ring.CommitRead(p.wid, id)
+		}
+		p.adapt()
+	}
+}
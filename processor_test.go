@@ -0,0 +1,142 @@
+package ringbuffer
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"testing"
+)
+
+// TestBatchEventProcessor runs two processors on one ring and checks each
+// item is handled once, and every batch ends with endOfBatch.
+func TestBatchEventProcessor(t *testing.T) {
+	defer parallel()()
+	const writers, n = 4, 2000
+	ring := MustNewTypedRingBuffer[int](16, WithDrainOnClose())
+	seen := make([]atomic.Int32, writers*n)
+	var procs []*BatchEventProcessor[int]
+	for wid := 0; wid < 2; wid++ {
+		open := false //a batch is being handled
+		procs = append(procs, NewBatchEventProcessor(ring, wid, func(id uint64, v *int, end bool) {
+			seen[*v].Add(1)
+			open = !end
+		}))
+		p := procs[wid]
+		if err := p.Start(); err != nil {
+			t.Fatal(err)
+		}
+		defer func() {
+			if open {
+				t.Errorf("processor %d: last batch did not end with endOfBatch", p.wid)
+			}
+		}()
+	}
+	if err := procs[0].Start(); !errors.Is(err, ErrRunning) {
+		t.Fatalf("second Start: %v", err)
+	}
+	done := make(chan struct{})
+	for w := 0; w < writers; w++ {
+		go func(w int) {
+			for i := 0; i < n; i++ {
+				ring.Put(w*n + i)
+			}
+			done <- struct{}{}
+		}(w)
+	}
+	for w := 0; w < writers; w++ {
+		<-done
+	}
+	ring.RingBuffer().Close()
+	for _, p := range procs {
+		<-p.Done()
+		if !errors.Is(p.Err(), ErrClosed) {
+			t.Fatalf("processor %d: Err() = %v after Close", p.wid, p.Err())
+		}
+	}
+	for v := range seen {
+		if c := seen[v].Load(); c != 1 {
+			t.Fatalf("item %d handled %d times", v, c)
+		}
+	}
+}
+
+func TestBatchEventProcessorHalt(t *testing.T) {
+	defer parallel()()
+	ring := MustNewTypedRingBuffer[int](4)
+	var handled atomic.Int32
+	p := NewBatchEventProcessor(ring, 0, func(id uint64, v *int, end bool) { handled.Add(1) })
+	p.Halt() //not started, no-op
+	p.Start()
+	ring.Put(1)
+	ring.Put(2)
+	for handled.Load() < 2 {
+		runtime.Gosched()
+	}
+	if p.Err() != nil {
+		t.Fatalf("Err() = %v while running", p.Err())
+	}
+	p.Halt()
+	if !errors.Is(p.Err(), context.Canceled) {
+		t.Fatalf("Err() = %v after Halt", p.Err())
+	}
+	if s := ring.RingBuffer().Stats(); s.ReadCommit != 2 {
+		t.Fatalf("read commit %d after Halt, want 2", s.ReadCommit)
+	}
+}
+
+// TestAdaptiveBatch pins that batches grow up to hi while the ring is deep
+// and shrink back to lo once it is shallow.
+func TestAdaptiveBatch(t *testing.T) {
+	defer parallel()()
+	ring := MustNewTypedRingBuffer[int](16)
+	release := make(chan struct{})
+	var handled, peak atomic.Int32
+	var p *BatchEventProcessor[int]
+	p = NewBatchEventProcessor(ring, 0, func(id uint64, v *int, end bool) {
+		if id == 0 {
+			<-release
+		}
+		if b := int32(p.BatchSize()); b > peak.Load() {
+			peak.Store(b)
+		}
+		handled.Add(1)
+	}, WithAdaptiveBatch(2, 8))
+	if got := p.BatchSize(); got != 2 {
+		t.Fatalf("BatchSize() = %d before Start, want lo", got)
+	}
+	p.Start()
+	defer p.Halt()
+	for i := 0; i < 16; i++ {
+		ring.Put(i)
+	}
+	close(release)
+	waitHandled := func(n int32) {
+		for handled.Load() < n {
+			runtime.Gosched()
+		}
+	}
+	waitHandled(16)
+	if got := peak.Load(); got != 8 {
+		t.Fatalf("batch limit peaked at %d on a deep ring, want hi 8", got)
+	}
+	for i := 0; i < 4; i++ {
+		ring.Put(i)
+		waitHandled(int32(17 + i))
+	}
+	if got := p.BatchSize(); got != 2 {
+		t.Fatalf("BatchSize() = %d on a shallow ring, want lo 2", got)
+	}
+}
+
+func TestAdaptiveBatchBounds(t *testing.T) {
+	defer parallel()()
+	ring := MustNewTypedRingBuffer[int](4)
+	h := func(uint64, *int, bool) {}
+	if p := NewBatchEventProcessor(ring, 0, h, WithAdaptiveBatch(0, 100)); p.minBatch != 1 || p.maxBatch != 4 {
+		t.Fatalf("WithAdaptiveBatch(0, 100) on size 4: [%d, %d]", p.minBatch, p.maxBatch)
+	}
+	if p := NewBatchEventProcessor(ring, 0, h); p.BatchSize() != 4 {
+		t.Fatalf("BatchSize() = %d without WithAdaptiveBatch, want Size", p.BatchSize())
+	}
+}
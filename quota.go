@@ -0,0 +1,180 @@
+package ringbuffer
+
+import (
+	"context"
+	"math"
+	"sync"
+	"sync/atomic"
+)
+
+// Writer is a producer registered with a ring, with its own share of the
+// ring capacity.
+// Items published through a Writer count against its quota until they are
+// consumed, so one noisy tenant can't fill a ring shared by several
+// subsystems.
+type Writer struct {
+	rb    *RingBuffer
+	wid   int
+	limit int64        // max items in the ring, 0 means no limit
+	used  atomic.Int64 // reserved and not consumed yet
+	chunk uint64       // ids claimed at once, 0 unless WithChunk
+
+	mu   sync.Mutex
+	next uint64 // next id of the chunk
+	end  uint64 // end of the chunk, exclusive
+}
+
+// WriterOption configures a Writer.
+type WriterOption func(*Writer)
+
+// WithQuota limits the items of a Writer in the ring to fraction of the
+// ring size, at least one. Quota is given back by read commits, which
+// Subscribers don't make, so it can't be used on a ring created
+// WithBroadcast.
+func WithQuota(fraction float64) WriterOption {
+	return func(w *Writer) {
+		w.limit = int64(math.Ceil(fraction * float64(w.rb.size)))
+		if w.limit < 1 {
+			w.limit = 1
+		}
+	}
+}
+
+// quotas tracks the Writer owning each slot.
+type quotas struct {
+	mu     sync.Mutex
+	owners atomic.Value // []slotOwner, set by the first NewWriter
+	wait   *waitList    // writers waiting for their quota
+}
+
+type slotOwner struct {
+	id uint64 // owned id, a stale id means no owner
+	w  *Writer
+}
+
+// NewWriter registers a producer using wid for its operations. It panics
+// on WithQuota for a ring created WithBroadcast.
+// It is goroutine-safe.
+func (rb *RingBuffer) NewWriter(wid int, opts ...WriterOption) *Writer {
+	w := &Writer{rb: rb, wid: wid}
+	for _, opt := range opts {
+		opt(w)
+	}
+	if w.limit > 0 && rb.broadcast != nil {
+		panic("RingBuffer: WithQuota on a ring created WithBroadcast")
+	}
+	q := &rb.quotas
+	q.mu.Lock()
+	if q.owners.Load() == nil {
+		q.wait = newWaitList()
+		owners := make([]slotOwner, rb.slots)
+		for i := range owners {
+			owners[i].id = math.MaxUint64
+		}
+		q.owners.Store(owners)
+	}
+	q.mu.Unlock()
+	if w.chunk > 0 {
+		rb.chunked.add(w)
+	}
+	return w
+}
+
+// slotOwner returns the Writer that published id, if any.
+// The caller must hold id before its read commit.
+func (rb *RingBuffer) slotOwner(id uint64) *Writer {
+	owners, _ := rb.quotas.owners.Load().([]slotOwner)
+	if owners == nil {
+		return nil
+	}
+	o := owners[rb.BufferIndex(id)]
+	if o.id != id {
+		return nil
+	}
+	return o.w
+}
+
+// slotOwners appends the Writers that published ids [lo, hi] to owners.
+// The caller must hold the ids before their read commit.
+func (rb *RingBuffer) slotOwners(owners []*Writer, lo, hi uint64) []*Writer {
+	if rb.quotas.owners.Load() == nil {
+		return owners
+	}
+	for id := lo; id <= hi; id++ {
+		if w := rb.slotOwner(id); w != nil {
+			owners = append(owners, w)
+		}
+	}
+	return owners
+}
+
+// release gives back the quota of a consumed item.
+func (w *Writer) release() {
+	w.used.Add(-1)
+	w.rb.quotas.wait.wake(math.MaxUint64)
+}
+
+// acquire takes one item of quota, waiting while it is used up.
+func (w *Writer) acquire() {
+	if w.limit == 0 {
+		w.used.Add(1)
+		return
+	}
+	ready := func() bool { return w.used.Load() < w.limit }
+	for try := 1; ; try++ {
+		u := w.used.Load()
+		if u < w.limit && w.used.CompareAndSwap(u, u+1) {
+			return
+		}
+		if w.rb.strategy().Spin(context.Background(), try, w.rb.yield) {
+			continue
+		}
+		w.rb.quotas.wait.wait(0, ready)
+	}
+}
+
+// ReserveWrite returns next avable id for write.
+// It will wait if the Writer used up its quota or the ring is full.
+// It returns ErrClosed once the ring is closed.
+// It is goroutine-safe.
+func (w *Writer) ReserveWrite() (uint64, error) {
+	w.acquire()
+	var id uint64
+	var err error
+	if w.chunk > 0 {
+		id, err = w.reserveChunked()
+	} else {
+		id, err = w.rb.ReserveWrite(w.wid)
+	}
+	if err != nil {
+		w.release()
+		return 0, err
+	}
+	owners := w.rb.quotas.owners.Load().([]slotOwner)
+	owners[w.rb.BufferIndex(id)] = slotOwner{id: id, w: w}
+	return id, nil
+}
+
+// CommitWrite commits write id reserved by w.
+// It is goroutine-safe.
+func (w *Writer) CommitWrite(id uint64) error {
+	return w.rb.CommitWrite(w.wid, id)
+}
+
+// AbortWrite aborts write id reserved by w and gives back its quota.
+// It is goroutine-safe.
+func (w *Writer) AbortWrite(id uint64) error {
+	owners := w.rb.quotas.owners.Load().([]slotOwner)
+	owners[w.rb.BufferIndex(id)].id = math.MaxUint64
+	if err := w.rb.AbortWrite(w.wid, id); err != nil {
+		return err
+	}
+	w.release()
+	return nil
+}
+
+// Used returns the items of w reserved and not consumed yet.
+// It is goroutine-safe.
+func (w *Writer) Used() int {
+	return int(w.used.Load())
+}
@@ -0,0 +1,48 @@
+package ringbuffer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuotaReleasedOnRead(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(8)
+	noisy := rb.NewWriter(0, WithQuota(0.25))
+	quiet := rb.NewWriter(1, WithQuota(0.5))
+	for i := 0; i < 2; i++ {
+		id, _ := noisy.ReserveWrite()
+		noisy.CommitWrite(id)
+	}
+	reserved := make(chan struct{})
+	go func() {
+		id, _ := noisy.ReserveWrite()
+		noisy.CommitWrite(id)
+		close(reserved)
+	}()
+	select {
+	case <-reserved:
+		t.Fatal("Writer went past its quota")
+	case <-time.After(20 * time.Millisecond):
+	}
+	id, _ := quiet.ReserveWrite() //the quiet Writer still has room
+	quiet.CommitWrite(id)
+	id, _ = rb.ReserveRead(2)
+	rb.CommitRead(2, id)
+	<-reserved
+	if got := noisy.Used(); got != 2 {
+		t.Fatalf("noisy Writer uses %d, want 2", got)
+	}
+}
+
+func TestQuotaBroadcastRejected(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(8, WithBroadcast())
+	rb.NewWriter(0) //no quota is fine
+	defer func() {
+		if recover() == nil {
+			t.Fatal("WithQuota accepted on a broadcast ring")
+		}
+	}()
+	rb.NewWriter(1, WithQuota(0.5))
+}
@@ -0,0 +1,132 @@
+package ringbuffer
+
+import (
+	"sync"
+	"testing"
+)
+
+// The payloads below are plain memory, written and read without atomics:
+// run with -race to check the happens-before edges documented on
+// RingBuffer.
+
+type racePayload struct {
+	id  uint64
+	buf [4]uint64
+}
+
+func (p *racePayload) fill(id uint64) {
+	p.id = id
+	for i := range p.buf {
+		p.buf[i] = id + uint64(i)
+	}
+}
+
+func (p *racePayload) check(t *testing.T, id uint64) {
+	if p.id != id {
+		t.Errorf("slot of id %d holds id %d", id, p.id)
+		return
+	}
+	for i, v := range p.buf {
+		if v != id+uint64(i) {
+			t.Errorf("slot of id %d: word %d is %d", id, i, v)
+		}
+	}
+}
+
+func raceStress(t *testing.T, opts ...Option) {
+	const writers, readers, n = 4, 4, 2000
+	rb := MustNewRingBuffer(8, append(opts, WithDrainOnClose())...)
+	slots := make([]racePayload, rb.Slots())
+	var wg, rg sync.WaitGroup
+	wg.Add(writers)
+	for w := 0; w < writers; w++ {
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				id, err := rb.ReserveWrite(w)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				slots[rb.BufferIndex(id)].fill(id)
+				rb.CommitWrite(w, id)
+			}
+		}(w)
+	}
+	rg.Add(readers)
+	for r := 0; r < readers; r++ {
+		go func(r int) {
+			defer rg.Done()
+			for {
+				id, err := rb.ReserveRead(r)
+				if err != nil {
+					return
+				}
+				slots[rb.BufferIndex(id)].check(t, id)
+				rb.CommitRead(r, id)
+			}
+		}(r)
+	}
+	wg.Wait()
+	rb.Close()
+	rg.Wait()
+}
+
+func TestRaceStress(t *testing.T) {
+	defer parallel()()
+	raceStress(t)
+}
+
+func TestRaceStressRelaxedRead(t *testing.T) {
+	defer parallel()()
+	raceStress(t, WithRelaxedReadCommit())
+}
+
+func TestRaceStressReservationCap(t *testing.T) {
+	defer parallel()()
+	raceStress(t, WithReservationCap())
+}
+
+func TestRaceStressBatch(t *testing.T) {
+	defer parallel()()
+	const writers, readers, n = 3, 3, 1000
+	rb := MustNewRingBuffer(16, WithDrainOnClose())
+	slots := make([]racePayload, rb.Slots())
+	var wg, rg sync.WaitGroup
+	wg.Add(writers)
+	for w := 0; w < writers; w++ {
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				lo, hi, err := rb.ReserveWriteN(w, i%5+1)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				for id := lo; id <= hi; id++ {
+					slots[rb.BufferIndex(id)].fill(id)
+				}
+				rb.CommitWriteN(w, lo, hi)
+			}
+		}(w)
+	}
+	rg.Add(readers)
+	for r := 0; r < readers; r++ {
+		go func(r int) {
+			defer rg.Done()
+			for {
+				lo, hi, err := rb.ReserveReadN(r, 4)
+				if err != nil {
+					return
+				}
+				for id := lo; id <= hi; id++ {
+					slots[rb.BufferIndex(id)].check(t, id)
+				}
+				rb.CommitReadN(r, lo, hi)
+			}
+		}(r)
+	}
+	wg.Wait()
+	rb.Close()
+	rg.Wait()
+}
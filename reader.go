@@ -0,0 +1,132 @@
+package ringbuffer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+)
+
+// Reader is a consumer registered with a ring, tracking the ids it holds:
+// reserved for read and not committed yet. A bounded Reader can't hold more
+// than its limit, which bounds the slots a slow consumer pins, and InFlight
+// tells what to recover if it fails.
+type Reader struct {
+	rb    *RingBuffer
+	wid   int
+	limit int // max ids held, 0 means no limit
+	wait  *waitList
+
+	mu      sync.Mutex
+	held    map[uint64]struct{}
+	pending int // ReserveReads past acquire without an id yet
+}
+
+// ReaderOption configures a Reader.
+type ReaderOption func(*Reader)
+
+// WithMaxInFlight limits the ids a Reader holds at once to n, at least one.
+func WithMaxInFlight(n int) ReaderOption {
+	return func(r *Reader) {
+		if n < 1 {
+			n = 1
+		}
+		r.limit = n
+	}
+}
+
+// NewReader registers a consumer using wid for its operations.
+// It is goroutine-safe.
+func (rb *RingBuffer) NewReader(wid int, opts ...ReaderOption) *Reader {
+	r := &Reader{rb: rb, wid: wid, wait: newWaitList(), held: make(map[uint64]struct{})}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// tryAcquire counts one more id held by r, as pending until it is known,
+// if r is below its limit.
+func (r *Reader) tryAcquire() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.limit > 0 && len(r.held)+r.pending >= r.limit {
+		return false
+	}
+	r.pending++
+	return true
+}
+
+// acquire waits until r may hold one more id, and counts it as pending.
+func (r *Reader) acquire() {
+	ready := func() bool {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		return len(r.held)+r.pending < r.limit
+	}
+	for try := 1; !r.tryAcquire(); try++ {
+		if r.rb.strategy().Spin(context.Background(), try, r.rb.yield) {
+			continue
+		}
+		r.wait.wait(0, ready)
+	}
+}
+
+// acquired turns the pending id of acquire into id, or drops it if the
+// reservation failed.
+func (r *Reader) acquired(id uint64, err error) {
+	r.mu.Lock()
+	r.pending--
+	if err == nil {
+		r.held[id] = struct{}{}
+	}
+	r.mu.Unlock()
+	if err != nil {
+		r.wait.wake(math.MaxUint64)
+	}
+}
+
+// ReserveRead returns next avable id for read.
+// It will wait if r holds its limit of ids, or the ring is empty.
+// It returns ErrClosed once the ring is closed, see WithDrainOnClose.
+// It is goroutine-safe.
+func (r *Reader) ReserveRead() (uint64, error) {
+	r.acquire()
+	id, err := r.rb.ReserveRead(r.wid)
+	r.acquired(id, err)
+	return id, err
+}
+
+// CommitRead commits read id held by r.
+// Committing an id r doesn't hold is a misuse, see WithMisusePolicy.
+// It is goroutine-safe.
+func (r *Reader) CommitRead(id uint64) error {
+	r.mu.Lock()
+	_, ok := r.held[id]
+	r.mu.Unlock()
+	if !ok {
+		return r.rb.misuse(fmt.Errorf("%w %d by this Reader", ErrNotReserved, id))
+	}
+	if err := r.rb.CommitRead(r.wid, id); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	delete(r.held, id)
+	r.mu.Unlock()
+	r.wait.wake(math.MaxUint64)
+	return nil
+}
+
+// InFlight returns the ids r holds, in order.
+// It is goroutine-safe.
+func (r *Reader) InFlight() []uint64 {
+	r.mu.Lock()
+	ids := make([]uint64, 0, len(r.held))
+	for id := range r.held {
+		ids = append(ids, id)
+	}
+	r.mu.Unlock()
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
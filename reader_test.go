@@ -0,0 +1,63 @@
+package ringbuffer
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestReaderMaxInFlight(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(8)
+	for i := 0; i < 4; i++ {
+		id, _ := rb.ReserveWrite(0)
+		rb.CommitWrite(0, id)
+	}
+	r := rb.NewReader(1, WithMaxInFlight(2))
+	a, _ := r.ReserveRead()
+	b, _ := r.ReserveRead()
+	if got := r.InFlight(); len(got) != 2 || got[0] != a || got[1] != b {
+		t.Fatalf("InFlight %v, want [%d %d]", got, a, b)
+	}
+	next := make(chan uint64)
+	go func() {
+		id, _ := r.ReserveRead()
+		next <- id
+	}()
+	select {
+	case id := <-next:
+		t.Fatalf("reserved %d over the limit", id)
+	case <-time.After(20 * time.Millisecond):
+	}
+	if err := r.CommitRead(a); err != nil {
+		t.Fatal(err)
+	}
+	c := <-next
+	if got := r.InFlight(); len(got) != 2 || got[0] != b || got[1] != c {
+		t.Fatalf("InFlight %v, want [%d %d]", got, b, c)
+	}
+	if err := r.CommitRead(a); !errors.Is(err, ErrNotReserved) {
+		t.Fatalf("CommitRead of an id the Reader doesn't hold: %v", err)
+	}
+	r.CommitRead(b)
+	r.CommitRead(c)
+	if got := r.InFlight(); len(got) != 0 {
+		t.Fatalf("InFlight %v after committing everything", got)
+	}
+}
+
+func TestReaderClosed(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(4)
+	r := rb.NewReader(1, WithMaxInFlight(1))
+	rb.Close()
+	if _, err := r.ReserveRead(); !errors.Is(err, ErrClosed) {
+		t.Fatalf("ReserveRead after Close: %v", err)
+	}
+	if got := r.InFlight(); len(got) != 0 {
+		t.Fatalf("failed reservation left %v in flight", got)
+	}
+	if _, err := r.ReserveRead(); !errors.Is(err, ErrClosed) {
+		t.Fatal("the failed reservation still counts against the limit")
+	}
+}
@@ -0,0 +1,72 @@
+package ringbuffer
+
+import (
+	"sort"
+	"sync"
+)
+
+// Reclaimer frees objects referenced by ring items once every reader is
+// past them (quiescent-state-based reclamation keyed by ring sequences).
+// There is no per-object reference counting: an object retired at sequence
+// s is freed once the read commit passes s.
+type Reclaimer struct {
+	rb      *RingBuffer
+	mu      sync.Mutex
+	pending []retired // ordered by due
+}
+
+type retired struct {
+	due  uint64 // free once rCommit >= due
+	free func()
+}
+
+// NewReclaimer returns a Reclaimer for objects referenced by items of rb.
+func NewReclaimer(rb *RingBuffer) *Reclaimer {
+	return &Reclaimer{rb: rb}
+}
+
+// RetireAt schedules free to run once every reader is past id.
+// It is goroutine-safe.
+func (r *Reclaimer) RetireAt(id uint64, free func()) {
+	r.retire(id+1, free)
+}
+
+// Retire schedules free to run once every item reserved so far has been
+// consumed. Call it after unlinking an object that items may reference.
+// It is goroutine-safe.
+func (r *Reclaimer) Retire(free func()) {
+	r.retire(r.rb.wReserve.Load(), free)
+}
+
+func (r *Reclaimer) retire(due uint64, free func()) {
+	r.mu.Lock()
+	i := sort.Search(len(r.pending), func(i int) bool { return r.pending[i].due > due })
+	r.pending = append(r.pending, retired{})
+	copy(r.pending[i+1:], r.pending[i:])
+	r.pending[i] = retired{due: due, free: free}
+	r.mu.Unlock()
+}
+
+// Reclaim runs the frees that are due and returns how many ran.
+// It is goroutine-safe; frees run on the calling goroutine.
+func (r *Reclaimer) Reclaim() int {
+	rc := r.rb.rCommit.Load()
+	r.mu.Lock()
+	n := sort.Search(len(r.pending), func(i int) bool { return r.pending[i].due > rc })
+	due := make([]retired, n)
+	copy(due, r.pending[:n])
+	r.pending = append(r.pending[:0], r.pending[n:]...)
+	r.mu.Unlock()
+	for _, d := range due {
+		d.free()
+	}
+	return n
+}
+
+// Pending returns the number of retired objects not freed yet.
+// It is goroutine-safe.
+func (r *Reclaimer) Pending() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.pending)
+}
@@ -0,0 +1,40 @@
+package ringbuffer
+
+import "testing"
+
+func TestReclaimer(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(4)
+	r := NewReclaimer(rb)
+	var freed []string
+	retire := func(name string) func() {
+		return func() { freed = append(freed, name) }
+	}
+	a, _ := rb.ReserveWrite(0)
+	rb.CommitWrite(0, a)
+	b, _ := rb.ReserveWrite(0)
+	rb.CommitWrite(0, b)
+	r.RetireAt(b, retire("b"))
+	r.RetireAt(a, retire("a"))
+	r.Retire(retire("all")) //due once a and b are consumed
+	if n := r.Reclaim(); n != 0 || r.Pending() != 3 {
+		t.Fatalf("reclaimed %d before any read, %d pending", n, r.Pending())
+	}
+
+	id, _ := rb.ReserveRead(1)
+	if n := r.Reclaim(); n != 0 {
+		t.Fatalf("reclaimed %d before the read commit", n)
+	}
+	rb.CommitRead(1, id)
+	if n := r.Reclaim(); n != 1 || len(freed) != 1 || freed[0] != "a" {
+		t.Fatalf("reclaimed %d, freed %v, want [a]", n, freed)
+	}
+	id, _ = rb.ReserveRead(1)
+	rb.CommitRead(1, id)
+	if n := r.Reclaim(); n != 2 || r.Pending() != 0 {
+		t.Fatalf("reclaimed %d, %d pending, want 2 and 0", n, r.Pending())
+	}
+	if len(freed) != 3 || freed[1] != "b" || freed[2] != "all" {
+		t.Fatalf("freed %v, want [a b all]", freed)
+	}
+}
@@ -0,0 +1,85 @@
+package ringbuffer
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Ordering is the read ordering mode of a ring, selected at construction
+// with WithOrdering.
+//
+// In both modes ReserveRead hands out ids in write id order, each id to one
+// reader only, and a slot is reused only once its own reader committed it;
+// Stats.ReadCommit, the end of the ids all read, only moves forward over
+// contiguous ids. A single reader thus consumes in FIFO order either way.
+// The modes differ in when CommitRead returns to readers finishing out of
+// order.
+type Ordering int
+
+const (
+	// OrderStrict makes CommitRead of id wait until every earlier id is
+	// committed: once it returns, every item up to id is consumed. One
+	// slow item holds up a convoy of finished readers. It is the default.
+	OrderStrict Ordering = iota
+	// OrderRelaxed makes CommitRead only mark the slot of id as done and
+	// return; whichever reader completes the lowest pending id moves the
+	// read commit over every contiguous done slot. Items complete out of
+	// order across readers, and a returned CommitRead says nothing of
+	// earlier ids.
+	OrderRelaxed
+)
+
+func (o Ordering) String() string {
+	switch o {
+	case OrderStrict:
+		return "strict"
+	case OrderRelaxed:
+		return "relaxed"
+	}
+	return "Ordering(" + strconv.Itoa(int(o)) + ")"
+}
+
+// WithOrdering selects the read ordering mode o, OrderStrict by default.
+func WithOrdering(o Ordering) Option {
+	return func(rb *RingBuffer) {
+		rb.ordering = o
+	}
+}
+
+// WithRelaxedReadCommit lets readers commit out of order.
+// It is WithOrdering(OrderRelaxed).
+func WithRelaxedReadCommit() Option {
+	return WithOrdering(OrderRelaxed)
+}
+
+// Ordering returns the read ordering mode of rb.
+func (rb *RingBuffer) Ordering() Ordering {
+	return rb.ordering
+}
+
+// initOrdering validates WithOrdering.
+func (rb *RingBuffer) initOrdering() error {
+	switch rb.ordering {
+	case OrderStrict, OrderRelaxed:
+	default:
+		return fmt.Errorf("RingBuffer: unknown %v", rb.ordering)
+	}
+	rb.relaxedRead = rb.ordering == OrderRelaxed
+	return nil
+}
+
+// commitReadRelaxed is CommitRead under WithRelaxedReadCommit.
+// readDone holds id+1 once read id is done, which never matches the later
+// ids sharing the slot.
+func (rb *RingBuffer) commitReadRelaxed(id uint64) error {
+	if rb.readDone[rb.BufferIndex(id)].Swap(id+1) == id+1 {
+		return rb.misuse(fmt.Errorf("%w %d", ErrCommitted, id))
+	}
+	for {
+		r := rb.rCommit.Load()
+		if rb.readDone[rb.BufferIndex(r)].Load() != r+1 {
+			return nil
+		}
+		rb.tryCommitRead(r) //may lose to another reader advancing r, check again either way
+	}
+}
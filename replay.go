@@ -0,0 +1,103 @@
+package ringbuffer
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// ReplaySpills recovers the spill files a SpillBuffer left in dir, e.g. a
+// process that crashed: it publishes the records Get had not taken into
+// ring in order, oldest file first, waiting for free slots, and removes
+// each file once replayed. dir must be one given to NewSpillBuffer and not
+// shared with live SpillBuffers, of this process or another: call it at
+// startup before creating SpillBuffers in dir, with ring consumed or large
+// enough. It returns the number of records replayed.
+// A record torn by the crash, at the end of a file, is dropped.
+func ReplaySpills[T any](dir string, ser Serializer[T], ring *TypedRingBuffer[T]) (int, error) {
+	if dir == "" {
+		return 0, errors.New("RingBuffer: ReplaySpills needs the dir of the SpillBuffers")
+	}
+	names, err := filepath.Glob(filepath.Join(dir, "ringbuffer-spill-*"))
+	if err != nil {
+		return 0, err
+	}
+	type file struct {
+		name string
+		info os.FileInfo
+	}
+	files := make([]file, 0, len(names))
+	for _, name := range names {
+		info, err := os.Stat(name)
+		if err != nil {
+			return 0, err
+		}
+		files = append(files, file{name, info})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].info.ModTime().Before(files[j].info.ModTime()) })
+	total := 0
+	for _, f := range files {
+		n, err := replaySpill(f.name, ser, ring)
+		total += n
+		if err != nil {
+			return total, err
+		}
+		if err := os.Remove(f.name); err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// replaySpill publishes the records of spill file name into ring.
+func replaySpill[T any](name string, ser Serializer[T], ring *TypedRingBuffer[T]) (int, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	var hdr [spillHeader]byte
+	if _, err := io.ReadFull(f, hdr[:]); err != nil {
+		return 0, eofOK(err)
+	}
+	consumed := int64(binary.LittleEndian.Uint64(hdr[:]))
+	if _, err := f.Seek(consumed, io.SeekStart); err != nil {
+		return 0, err
+	}
+	r := bufio.NewReader(f)
+	var buf []byte
+	n := 0
+	for {
+		if _, err := io.ReadFull(r, hdr[:4]); err != nil {
+			return n, eofOK(err)
+		}
+		size := int(binary.LittleEndian.Uint32(hdr[:4]))
+		if cap(buf) < size {
+			buf = make([]byte, size)
+		}
+		buf = buf[:size]
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return n, eofOK(err)
+		}
+		var v T
+		if err := ser.Unmarshal(buf, &v); err != nil {
+			return n, err
+		}
+		if err := ring.Put(v); err != nil {
+			return n, err
+		}
+		n++
+	}
+}
+
+// eofOK maps the end of a spill file, clean or torn, to nil.
+func eofOK(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil
+	}
+	return err
+}
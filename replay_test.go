@@ -0,0 +1,79 @@
+package ringbuffer
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// TestReplaySpills leaves a SpillBuffer behind as a crash would and checks
+// only the records Get had not taken are replayed, in order.
+func TestReplaySpills(t *testing.T) {
+	defer parallel()()
+	dir := t.TempDir()
+	s, err := NewSpillBuffer[string](2, dir, StringSerializer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		s.Put(strconv.Itoa(i))
+	}
+	for i := 0; i < 4; i++ {
+		if v, err := s.Get(0); err != nil || v != strconv.Itoa(i) {
+			t.Fatalf("Get() = %q, %v, want %d", v, err, i)
+		}
+	}
+	s.file.Close() //crash: the file is left behind
+
+	ring := MustNewTypedRingBuffer[string](16)
+	n, err := ReplaySpills(dir, StringSerializer{}, ring)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 6 {
+		t.Fatalf("replayed %d records, want the 6 not taken", n)
+	}
+	for i := 4; i < 10; i++ {
+		if v, _ := ring.Get(); v != strconv.Itoa(i) {
+			t.Fatalf("replayed %q, want %d", v, i)
+		}
+	}
+	if names, _ := filepath.Glob(filepath.Join(dir, "*")); len(names) != 0 {
+		t.Fatalf("files left after replay: %v", names)
+	}
+}
+
+// TestReplaySpillsDrained checks a file emptied by Get replays nothing.
+func TestReplaySpillsDrained(t *testing.T) {
+	defer parallel()()
+	dir := t.TempDir()
+	s, _ := NewSpillBuffer[string](1, dir, StringSerializer{})
+	for i := 0; i < 5; i++ {
+		s.Put(strconv.Itoa(i))
+	}
+	for i := 0; i < 5; i++ {
+		s.Get(0)
+	}
+	s.file.Close()
+	n, err := ReplaySpills(dir, StringSerializer{}, MustNewTypedRingBuffer[string](4))
+	if err != nil || n != 0 {
+		t.Fatalf("ReplaySpills() = %d, %v on a drained file", n, err)
+	}
+}
+
+func TestReplaySpillsNeedsDir(t *testing.T) {
+	defer parallel()()
+	f, err := os.CreateTemp("", "ringbuffer-spill-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+	if _, err := ReplaySpills("", StringSerializer{}, MustNewTypedRingBuffer[string](4)); err == nil {
+		t.Fatal("ReplaySpills of the shared temp dir accepted")
+	}
+	if _, err := os.Stat(f.Name()); err != nil {
+		t.Fatalf("a spill file in the temp dir was touched: %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+package ringbuffer
+
+import (
+	"context"
+	"time"
+)
+
+// WithReservationCap bounds outstanding write reservations to the ring size.
+// By default ReserveWrite takes an id first and then waits for its slot, so
+// any number of writers may hold ids far beyond the buffer while parked.
+// With the cap, a writer only takes an id once its slot is free (a ticket
+// gate), so wReserve never runs more than Size() ahead of the read commit.
+func WithReservationCap() Option {
+	return func(rb *RingBuffer) {
+		rb.reserveCap = true
+	}
+}
+
+// reserveWriteCapped is ReserveWrite under WithReservationCap.
+func (rb *RingBuffer) reserveWriteCapped(wid int) (uint64, error) {
+	var t trace
+	if rb.debug.Load() {
+		t = rb.trace(OpReserveWrite, wid)
+	}
+	var waitStart time.Time
+
+	for try := 1; ; try++ {
+		if rb.debug.Load() {
+			t.try(rb.wReserve.Load(), try)
+		}
+
+		c := rb.wCommit.Load()
+		id, ok, err := rb.tryReserveWriteFull(wid)
+		if ok {
+			if rb.debug.Load() {
+				t.done(id, try)
+			}
+			rb.waitEnd(OpReserveWrite, wid, waitStart)
+			return id, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		if rb.closed.Load() != 0 {
+			return 0, ErrClosed
+		}
+		if try == 1 {
+			rb.writeWaited(rb.wReserve.Load())
+			rb.observeFull(wid)
+		}
+		waitStart = rb.waitBegin(waitStart)
+
+		if rb.strategy().Spin(context.Background(), try, rb.yield) {
+			continue
+		}
+
+		//buffer full, wait for the slot of the next id to free
+		next := rb.wReserve.Load()
+		rb.waitWriteR.wait(rb.writeNeed(next), func() bool {
+			return rb.canWrite(rb.wReserve.Load()) || rb.closed.Load() != 0 ||
+				rb.canOverwrite(c)
+		})
+		rb.observeWakeup(OpReserveWrite, wid)
+	}
+}
+
+// TryReserveWrite reserves the next write id like ReserveWrite, but fails
+// fast with false instead of waiting when the ring is full, so the caller
+// can drop, spill or retry later. It fails once the ring is closed, or
+// without credit under WithCredits. The OnFull policy applies, see
+// WithOnFull.
+// It is goroutine-safe.
+func (rb *RingBuffer) TryReserveWrite(wid int) (id uint64, ok bool) {
+	if rb.instr != nil {
+		defer rb.instrumentedTry(OpReserveWrite, wid, time.Now(), &id, &ok)
+	}
+	if rb.contractChecks && rb.singleWriter {
+		checkOwner(&rb.writerOwner, "writer")
+	}
+	if rb.gate != nil {
+		select {
+		case <-rb.gate:
+		default:
+			return 0, false
+		}
+	}
+	if rb.credits != nil {
+		if !rb.credits.tryTake(1) {
+			return 0, false
+		}
+		id, ok, _ = rb.tryReserveWriteFull(wid)
+		if !ok {
+			rb.credits.refund(1)
+		}
+		return id, ok
+	}
+	id, ok, _ = rb.tryReserveWriteFull(wid)
+	return id, ok
+}
+
+// TryReserveRead reserves the next read id like ReserveRead, but fails fast
+// with false instead of waiting when the ring is empty. Once the ring is
+// closed, it fails unless WithDrainOnClose is set.
+// It is goroutine-safe.
+func (rb *RingBuffer) TryReserveRead(wid int) (id uint64, ok bool) {
+	if rb.instr != nil {
+		defer rb.instrumentedTry(OpReserveRead, wid, time.Now(), &id, &ok)
+	}
+	if rb.contractChecks && rb.singleReader {
+		checkOwner(&rb.readerOwner, "reader")
+	}
+	if rb.readersClosed() {
+		return 0, false
+	}
+	return rb.tryReserveRead(wid)
+}
+
+// tryReserveWrite takes the next write id only if its slot is free and the
+// ring is open.
+func (rb *RingBuffer) tryReserveWrite() (uint64, bool) {
+	for {
+		if rb.closed.Load() != 0 {
+			return 0, false
+		}
+		w := rb.wReserve.Load()
+		if !rb.canWrite(w) {
+			return 0, false
+		}
+		if !rb.claimWrite(w, 1) {
+			continue
+		}
+		if rb.closed.Load() != 0 { //closed meanwhile, drain readers may not wait for w
+			rb.abortClosed(-1, w, w)
+			return 0, false
+		}
+		return w, true
+	}
+}
+
+// tryReserveRead takes the next read id only if it is published, skipping
+// tombstones and other markers.
+func (rb *RingBuffer) tryReserveRead(wid int) (uint64, bool) {
+	for {
+		r := rb.rReserve.Load()
+		if !rb.canRead(r) {
+			return 0, false
+		}
+		if !rb.claimRead(r, 1) {
+			continue
+		}
+		if rb.audit != nil {
+			rb.audit.reserved(r)
+		}
+		if !rb.skipTombstone(wid, r) {
+			return r, true
+		}
+	}
+}
@@ -1,57 +1,157 @@
 package ringbuffer
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math"
 	"runtime"
+	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
+	"unsafe"
 )
 
-func NewRingBuffer(size int) *RingBuffer {
+// NewRingBuffer returns a ring of size slots configured by opts.
+// Any size in [1, MaxSize] is valid; size 1 and 2 make handy mailbox and
+// rendezvous primitives, where every write waits for the previous read.
+// It returns ErrInvalidSize for other sizes, and an error if GOMAXPROCS is
+// too low, see RingBuffer.
+func NewRingBuffer(size int, opts ...Option) (*RingBuffer, error) {
 	p := &RingBuffer{}
-	p.init(size)
-	return p
+	for _, opt := range opts {
+		opt(p)
+	}
+	if err := p.init(size); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// MustNewRingBuffer is NewRingBuffer panicking on error, for tests and
+// package level rings.
+func MustNewRingBuffer(size int, opts ...Option) *RingBuffer {
+	rb, err := NewRingBuffer(size, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return rb
 }
 
-//BufferId is the id of a buffer
+// BufferId is the id of a buffer
 type BufferId uint64
 
 // RingBuffer is goroutine-safe cycle buffer.
 // It is designed as busy share buffer with lots of readers and writers.
 // RingBuffer must runs under parallelism mode(runtime.GOMAXPROCS >= 4).
 // It enables enable real-parallel R/W on busy shared buffers.
+//
+// Slot data needs no locking of its own, the cursors order it:
+//   - CommitWrite of id happens before ReserveRead returns id, so a reader
+//     sees everything the writer stored in the slot before committing.
+//   - CommitRead of id happens before ReserveWrite returns the next id of
+//     the same slot, so a writer never overwrites a slot still being read.
+//   - Close happens before the ErrClosed it causes.
+//
+// Every cursor and counter shared between goroutines is a sync/atomic
+// typed value, so these edges are visible to the race detector.
 // see:
-//   http://ifeve.com/ringbuffer
-//   http://mechanitis.blogspot.com/2011/06/dissecting-disruptor-whats-so-special.html
+//
+//	http://ifeve.com/ringbuffer
+//	http://mechanitis.blogspot.com/2011/06/dissecting-disruptor-whats-so-special.html
 type RingBuffer struct {
-	debug      bool
-	totalWait  int64
-	size       int        // buffer size, readonly
-	waitReadR  *sync.Cond // waitlist that are wating read
-	waitWriteR *sync.Cond // waitlist that are wating write
-	waitReadC  *sync.Cond // waitlist that are wating read commit
-	waitWriteC *sync.Cond // waitlist that are wating write commit
-	rReserve   uint64     // Read reserve, mutable
-	rCommit    uint64     // Read commit, mutable
-	wReserve   uint64     // Write reserve, mutable
-	wCommit    uint64     // Write commit, mutable
+	name       string            // readonly
+	labels     map[string]string // readonly
+	debug      atomic.Bool
+	sink       DebugSink     // receives debug events, stdout JSON lines if nil
+	size       int           // buffer size, readonly
+	slots      int           // size, or rounded for BufferIndex, readonly
+	waitReadR  *waitList     // waitlist that are wating read
+	waitWriteR *waitList     // waitlist that are wating write
+	waitReadC  *waitList     // waitlist that are wating read commit
+	waitWriteC *waitList     // waitlist that are wating write commit
+	rReserve   atomic.Uint64 // Read reserve, mutable
+	rCommit    atomic.Uint64 // Read commit, mutable
+	wReserve   atomic.Uint64 // Write reserve, mutable
+	wCommit    atomic.Uint64 // Write commit, mutable
+
+	waitStrategy   atomic.Value  // strategyBox, strategy before parking
+	idlePeriod     time.Duration // readers switch to idleStrategy after it, readonly
+	idleStrategy   WaitStrategy  // readonly
+	lastWrite      atomic.Int64  // UnixNano of last write commit when idlePeriod > 0
+	timeIndex      *timeIndex    // publish times of sampled sequences, optional
+	watermarks     *byteWatermarks
+	bytesWritten   atomic.Uint64 // payload bytes published
+	bytesRead      atomic.Uint64 // payload bytes consumed
+	withMeta       bool
+	meta           []SlotMeta // per slot metadata, optional
+	misusePolicy   MisusePolicy
+	reserveCap     bool // write reservations never run ahead of free slots
+	tombs          tombstones
+	onBarrier      func(wid int, id uint64)
+	waitBarrier    *waitList // waitlist that are waiting readers to pass a barrier
+	quotas         quotas
+	chunked        chunkedWriters // Writers created WithChunk
+	audit          *audit         // read id audit, optional
+	ordering       Ordering       // see WithOrdering
+	relaxedRead    bool           // ordering is OrderRelaxed
+	pins           pins
+	broadcast      *broadcast // Subscribers, nil unless WithBroadcast
+	migrateMu      sync.Mutex
+	successor      atomic.Value  // *RingBuffer that replaced this one, see Migrate
+	gate           chan struct{} // closed once Migrate moved the old items in, nil if not migrated to
+	divMagic       uint64        // BufferIndex magic multiplier, magicmod build only
+	slo            *slo          // reserve wait SLO, optional
+	singleWriter   bool
+	singleReader   bool
+	contractChecks bool
+	writerOwner    atomic.Int64 // goroutine id owning the single writer side, 0 if none yet
+	readerOwner    atomic.Int64 // goroutine id owning the single reader side, 0 if none yet
+	admission      AdmissionPolicy
+	admitAbove     float64                  // occupancy from which admission is consulted
+	credits        *credits                 // nil unless WithCredits
+	slotGroup      int                      // see WithSlotAlignment
+	releaser       any                      // func(*T) for a TypedRingBuffer of T, see WithReleaser
+	shed           atomic.Uint64            // publishes refused by admission
+	onFull         func(wid int) FullPolicy // nil means FullBlock
+	overwrite      bool                     // onFull may return FullDropOldest
+	overwritten    atomic.Uint64            // entries dropped unread under WithOverwrite
+	fallbacks      atomic.Uint64            // items handed to a PutOr fallback
+	distances      *WaitDistances
+	runtimeStats   *runtimeSampler // nil unless WithRuntimeStats
+	expvarName     string          // see WithExpvar
+	instr          Instrumentation // nil unless WithInstrumentation
+	observer       Observer        // nil unless WithObserver
+	coord          *Coordinator    // nil unless coordinated, see NewCoordinator
+	coordIndex     int             // index of the ring in coord
+	publishTimes   []atomic.Int64  // per slot UnixNano of the last publish, with instr only
+	waits          waitCounters
+	yield          func() // called by spinning wait strategies
+	closed         atomic.Int32
+	drainOnClose   bool
+	lowLatency     bool
+	divShift       uint
+	divMask        uint64
+	divPow2        bool            // BufferIndex is a mask, readonly
+	pow2Slots      bool            // WithPowerOfTwoSlots, readonly
+	readDone       []atomic.Uint64 // per slot id+1 of the last done read, relaxed read commit and overwrite only
+	writeDone      []atomic.Uint64 // per slot id+1 of the last done write
 }
 
+// Debug enables or disables emitting an Event for every state transition to
+// the debug sink, see WithDebugSink.
+// It is goroutine-safe.
 func (rb *RingBuffer) Debug(enable bool) {
-	rb.debug = enable
+	rb.debug.Store(enable)
 }
 
-func (rb *RingBuffer) log(name string) func() {
-	start := time.Now()
-	//fmt.Printf("%s %s start\n", start, name)
-	deferFun := func() {
-		end := time.Now()
-		cost := end.Sub(start)
-		totalCost := atomic.AddInt64(&rb.totalWait, int64(cost))
-		fmt.Printf("%s %s end, cost=%s totalCost=%s\n", start, name, cost, time.Duration(totalCost))
+// WithDebug enables debug events from the start, see Debug.
+func WithDebug() Option {
+	return func(rb *RingBuffer) {
+		rb.debug.Store(true)
 	}
-	return deferFun
 }
 
 // Init ringbuffer with size.
@@ -62,162 +162,621 @@ func (rb *RingBuffer) init(size int) error {
 	if runtime.GOMAXPROCS(0) < need_cpus {
 		return fmt.Errorf("RingBuffer: requires parallelism(runtime.GOMAXPROCS >= %d)", need_cpus)
 	}
-	if size <= 0 {
-		return fmt.Errorf("RingBuffer: invalid size %d", size)
+	if size <= 0 || size > MaxSize {
+		return fmt.Errorf("%w %d", ErrInvalidSize, size)
+	}
+	if err := rb.checkSlotAlignment(); err != nil {
+		return err
+	}
+	if err := rb.initOrdering(); err != nil {
+		return err
+	}
+	if rb.idlePeriod > 0 {
+		if rb.idleStrategy == nil {
+			return errors.New("RingBuffer: WithIdleWaitStrategy needs a WaitStrategy")
+		}
+		rb.lastWrite.Store(time.Now().UnixNano()) //idle from construction, not from 1970
 	}
 	rb.size = size
-	rb.waitReadR = sync.NewCond(new(sync.Mutex))
-	rb.waitWriteR = sync.NewCond(new(sync.Mutex))
-	rb.waitReadC = sync.NewCond(new(sync.Mutex))
-	rb.waitWriteC = sync.NewCond(new(sync.Mutex))
+	rb.initIndex()
+	size = rb.slots
+	if err := checkSlots(size, rb.slotState()); err != nil {
+		return err
+	}
+	rb.waitReadR = newWaitList()
+	rb.waitWriteR = newWaitList()
+	rb.waitReadC = newWaitList()
+	rb.waitWriteC = newWaitList()
+	rb.waitBarrier = newWaitList()
+	if rb.waitStrategy.Load() == nil {
+		rb.waitStrategy.Store(strategyBox{BlockingWaitStrategy{}})
+	}
+	if rb.yield == nil {
+		rb.yield = runtime.Gosched
+	}
+	if rb.withMeta {
+		rb.meta = make([]SlotMeta, size)
+	}
+	rb.writeDone = make([]atomic.Uint64, size)
+	if rb.relaxedRead || rb.overwrite {
+		rb.readDone = make([]atomic.Uint64, size)
+	}
+	if rb.instr != nil {
+		rb.publishTimes = make([]atomic.Int64, size)
+	}
+	if rb.lowLatency {
+		rb.prefault()
+	}
+	return rb.publishExpvar()
+}
+
+// slotState returns the bytes of ring state kept per slot.
+func (rb *RingBuffer) slotState() uintptr {
+	n := unsafe.Sizeof(atomic.Uint64{}) //writeDone
+	if rb.relaxedRead || rb.overwrite {
+		n += unsafe.Sizeof(atomic.Uint64{})
+	}
+	if rb.withMeta {
+		n += unsafe.Sizeof(SlotMeta{})
+	}
+	if rb.instr != nil {
+		n += unsafe.Sizeof(atomic.Int64{})
+	}
+	return n
+}
+
+// checkSlots returns ErrInvalidSize unless n slots of elem bytes each fit
+// in one allocation.
+func checkSlots(n int, elem uintptr) error {
+	if elem > 0 && uint64(n) > math.MaxInt/uint64(elem) {
+		return fmt.Errorf("%w %d: slots of %d bytes don't fit in memory", ErrInvalidSize, n, elem)
+	}
 	return nil
 }
 
-// Size return size of ringbuffer
+// Size return size of ringbuffer, the items it holds at most.
 func (rb *RingBuffer) Size() int {
 	return rb.size
 }
 
-// BufferIndex returns logic index of buffer by id
-func (rb *RingBuffer) BufferIndex(id uint64) int {
-	return int(id % uint64(rb.size))
+// Slots returns the number of slots of the buffer, the range of
+// BufferIndex. It is Size unless WithPowerOfTwoSlots rounded it up, so
+// slot storage indexed by BufferIndex needs Slots entries.
+func (rb *RingBuffer) Slots() int {
+	return rb.slots
+}
+
+// WithPowerOfTwoSlots rounds the slots up to a power of two, so BufferIndex
+// is a mask instead of a modulo. Size still reports the capacity, but
+// BufferIndex then ranges over Slots, which slot storage must be sized by.
+func WithPowerOfTwoSlots() Option {
+	return func(rb *RingBuffer) {
+		rb.pow2Slots = true
+	}
+}
+
+// ApproxLen returns the number of published items not consumed yet, from
+// two plain atomic loads and no retry, so metrics scrapers never contend
+// with readers and writers.
+// The cursors are loaded at slightly different times, so the result may be
+// off by the commits that happened in between: it is never negative, but
+// may briefly exceed Size().
+// It is goroutine-safe.
+func (rb *RingBuffer) ApproxLen() int {
+	r := rb.rCommit.Load() // first, so that w >= r
+	w := rb.wCommit.Load()
+	return int(w - r)
+}
+
+// Len returns the number of published items not consumed yet, as of a
+// single instant. It retries until the write cursor is stable around the
+// read of the read cursor, so it may spin briefly on a busy ring.
+// It is goroutine-safe.
+func (rb *RingBuffer) Len() int {
+	for {
+		w := rb.wCommit.Load()
+		r := rb.rCommit.Load()
+		if rb.wCommit.Load() == w {
+			return int(w - r)
+		}
+	}
 }
 
+// NextWriteSequence reports the id the next ReserveWrite would return, and
+// whether it would wait for a free slot, without reserving anything.
+// It is a snapshot: concurrent writers may take the id first.
+// It is goroutine-safe.
+func (rb *RingBuffer) NextWriteSequence() (id uint64, wouldBlock bool) {
+	id = rb.wReserve.Load()
+	return id, !rb.canWrite(id)
+}
+
+// NextReadSequence reports the id the next ReserveRead would return, and
+// whether it would wait for a write, without reserving anything.
+// It is a snapshot: concurrent readers may take the id first.
+// It is goroutine-safe.
+func (rb *RingBuffer) NextReadSequence() (id uint64, wouldBlock bool) {
+	id = rb.rReserve.Load()
+	return id, !rb.canRead(id)
+}
+
+// Name returns the ring name given by WithName.
+func (rb *RingBuffer) Name() string {
+	return rb.name
+}
+
+// Labels returns a copy of the ring labels given by WithLabels.
+func (rb *RingBuffer) Labels() map[string]string {
+	if rb.labels == nil {
+		return nil
+	}
+	m := make(map[string]string, len(rb.labels))
+	for k, v := range rb.labels {
+		m[k] = v
+	}
+	return m
+}
+
+// ident returns "name{k=v,...} " for logs, or "" for an anonymous ring.
+func (rb *RingBuffer) ident() string {
+	if k := rb.key(); k != "" {
+		return k + " "
+	}
+	return ""
+}
+
+// key returns "name{k=v,...}", labels sorted by key, or "" for an
+// anonymous ring.
+func (rb *RingBuffer) key() string {
+	if rb.name == "" && len(rb.labels) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(rb.labels))
+	for k := range rb.labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString(rb.name)
+	if len(keys) > 0 {
+		b.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(k + "=" + rb.labels[k])
+		}
+		b.WriteByte('}')
+	}
+	return b.String()
+}
+
+// Show returns the cursors of rb as a line for logs. Programmatic
+// monitoring should use Stats.
 func (rb *RingBuffer) Show() string {
-	return fmt.Sprintf("%s rR=%d rC=%d wR=%d wC=%d",
+	return fmt.Sprintf("%s %srR=%d rC=%d wR=%d wC=%d",
 		time.Now().Format("2006-01-02T15:04:05.999999999"),
-		atomic.LoadUint64(&rb.rReserve),
-		atomic.LoadUint64(&rb.rCommit),
-		atomic.LoadUint64(&rb.wReserve),
-		atomic.LoadUint64(&rb.wCommit),
+		rb.ident(),
+		rb.rReserve.Load(),
+		rb.rCommit.Load(),
+		rb.wReserve.Load(),
+		rb.wCommit.Load(),
 	)
 }
 
 // ReserveWrite returns next avable id for write.
-// It will wait if ringbuffer is full.
+// It will wait if ringbuffer is full, or for a Grant under WithCredits.
+// It returns ErrClosed once the ring is closed.
 // It is goroutine-safe.
-func (rb *RingBuffer) ReserveWrite(wid int) (id uint64) {
-	id = atomic.AddUint64(&rb.wReserve, 1) - 1
+func (rb *RingBuffer) ReserveWrite(wid int) (id uint64, err error) {
+	if rb.instr != nil {
+		defer rb.instrumented(OpReserveWrite, wid, time.Now(), &id, &err)
+	}
+	if rb.contractChecks && rb.singleWriter {
+		checkOwner(&rb.writerOwner, "writer")
+	}
+	if rb.gate != nil {
+		<-rb.gate
+	}
+	return rb.reserveWrite(wid)
+}
 
-	if rb.debug {
-		fn := rb.log("ReserveWrite")
-		defer fn()
+func (rb *RingBuffer) reserveWrite(wid int) (id uint64, err error) {
+	if rb.closed.Load() != 0 {
+		return 0, ErrClosed
+	}
+	if rb.credits != nil {
+		if err := rb.credits.take(context.Background(), rb, 1); err != nil {
+			return 0, err
+		}
+		defer func() {
+			if err != nil {
+				rb.credits.refund(1)
+			}
+		}()
+	}
+	if rb.reserveCap || rb.onFull != nil { //full policies apply before an id is taken
+		return rb.reserveWriteCapped(wid)
+	}
+	id = rb.takeWrite(1)
+	if rb.closed.Load() != 0 { //closed meanwhile, drain readers may not wait for id
+		return 0, rb.abortClosed(wid, id, id)
 	}
+	if err := rb.awaitWrite(wid, id); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
 
+// awaitWrite waits until the slot of write id, taken by wid, is free. It
+// aborts id and returns ErrClosed if the ring is closed meanwhile.
+func (rb *RingBuffer) awaitWrite(wid int, id uint64) error {
+	var waitStart time.Time
 	try := 0
+	var t trace
+	if rb.debug.Load() {
+		t = rb.trace(OpReserveWrite, wid)
+		defer func() { t.done(id, try) }()
+	}
+
 	for {
 		try++
-		if rb.debug {
-			fmt.Printf("ReserveWrite try=%d wid=%d %s\n", try, wid, rb.Show())
+		if rb.debug.Load() {
+			t.try(id, try)
 		}
 
-		dataStart := atomic.LoadUint64(&rb.rCommit)
-		maxW := dataStart + uint64(rb.size)
-		if id < maxW { //no conflict, reserve ok
+		if rb.canWrite(id) { //no conflict, reserve ok
 			break
 		}
+		if rb.closed.Load() != 0 {
+			return rb.abortClosed(wid, id, id)
+		}
+		if try == 1 {
+			rb.writeWaited(id)
+			rb.observeFull(wid)
+		}
+		waitStart = rb.waitBegin(waitStart)
+
+		if rb.strategy().Spin(context.Background(), try, rb.yield) {
+			continue
+		}
 
 		//buffer full, wait as writer in order to awake by another reader
-		rb.waitWriteR.L.Lock()
-		rb.waitWriteR.Wait()
-		rb.waitWriteR.L.Unlock()
+		rb.waitWriteR.wait(rb.writeNeed(id), func() bool {
+			return rb.canWrite(id) || rb.closed.Load() != 0
+		})
+		rb.observeWakeup(OpReserveWrite, wid)
 	}
-
-	return
+	rb.waitEnd(OpReserveWrite, wid, waitStart)
+	return nil
 }
 
 // CommitWrite commit writer event for id.
-// It will wait if previous writer id havn't commit.
+// It never waits: the slot of id is flagged available, and whichever writer
+// completes the lowest pending id moves the write commit over every
+// contiguous available slot, so readers see id once every earlier id is
+// committed too.
 // It will awake on reader wait list after commit OK.
+// Committing an id that is not reserved or already committed is a misuse,
+// see WithMisusePolicy.
 // It is goroutine-safe.
-func (rb *RingBuffer) CommitWrite(wid int, id uint64) {
-	newId := id + 1
-
-	if rb.debug {
-		fn := rb.log("CommitWrite")
-		defer fn()
+func (rb *RingBuffer) CommitWrite(wid int, id uint64) (err error) {
+	if rb.instr != nil {
+		defer rb.instrumented(OpCommitWrite, wid, time.Now(), &id, &err)
 	}
-
-	try := 0
-	for {
-		try++
-		if rb.debug {
-			fmt.Printf("CommitWrite try=%d wid=%d %s\n", try, wid, rb.Show())
-		}
-
-		if atomic.CompareAndSwapUint64(&rb.wCommit, id, newId) { //commit OK
-			rb.waitReadR.Broadcast()  //wakeup reader
-			rb.waitWriteC.Broadcast() //wakeup write committer
-			break
-		}
-
-		//commit fail, wait as reader in order to wakeup by another writer
-		rb.waitWriteC.L.Lock()
-		rb.waitWriteC.Wait()
-		rb.waitWriteC.L.Unlock()
+	if rb.observer != nil {
+		defer rb.observeCommit(OpCommitWrite, wid, id, id, &err)
+	}
+	if err := rb.checkCommit(id, &rb.wReserve, &rb.wCommit); err != nil {
+		return err
 	}
+	if rb.debug.Load() {
+		t := rb.trace(OpCommitWrite, wid)
+		defer t.done(id, 1)
+	}
+	if rb.instr != nil {
+		rb.stampPublish(id, id)
+	}
+	return rb.commitWriteDone(id, id)
 }
 
 // ReserveRead returns next avable id for read.
 // It will wait if ringbuffer is empty.
+// It returns ErrClosed once the ring is closed, see WithDrainOnClose.
 // It is goroutine-safe.
-func (rb *RingBuffer) ReserveRead(wid int) (id uint64) {
-	id = atomic.AddUint64(&rb.rReserve, 1) - 1
+func (rb *RingBuffer) ReserveRead(wid int) (id uint64, err error) {
+	if rb.instr != nil {
+		defer rb.instrumented(OpReserveRead, wid, time.Now(), &id, &err)
+	}
+	if rb.contractChecks && rb.singleReader {
+		checkOwner(&rb.readerOwner, "reader")
+	}
+	for {
+		id, err := rb.reserveRead(wid)
+		if err != nil {
+			return 0, err
+		}
+		if !rb.skipTombstone(wid, id) {
+			return id, nil
+		}
+	}
+}
 
-	if rb.debug {
-		fn := rb.log("ReserveRead")
-		defer fn()
+func (rb *RingBuffer) reserveRead(wid int) (id uint64, err error) {
+	if rb.readersClosed() {
+		return 0, ErrClosed
 	}
+	id = rb.takeRead(1)
 
+	var waitStart time.Time
 	try := 0
+	var t trace
+	if rb.debug.Load() {
+		t = rb.trace(OpReserveRead, wid)
+		defer func() { t.done(id, try) }()
+	}
+
 	for {
 		try++
-		if rb.debug {
-			fmt.Printf("ReserveRead try=%d wid=%d %s\n", try, wid, rb.Show())
+		if rb.debug.Load() {
+			t.try(id, try)
 		}
 
-		w := atomic.LoadUint64(&rb.wCommit)
-		if id < w { //no conflict, reserve ok
+		if rb.canRead(id) { //no conflict, reserve ok
 			break
 		}
+		if rb.readClosed(id) {
+			return 0, ErrClosed
+		}
+		if try == 1 {
+			rb.readWaited(id)
+			rb.observeEmpty(wid)
+		}
+		waitStart = rb.waitBegin(waitStart)
+
+		if rb.readStrategy().Spin(context.Background(), try, rb.yield) {
+			continue
+		}
 
 		//buffer empty, wait as reader in order to wakeup by another writer
-		rb.waitReadR.L.Lock()
-		rb.waitReadR.Wait()
-		rb.waitReadR.L.Unlock()
+		rb.waitReadR.wait(id+1, func() bool { return rb.canRead(id) || rb.readClosed(id) })
+		rb.observeWakeup(OpReserveRead, wid)
 	}
+	rb.waitEnd(OpReserveRead, wid, waitStart)
 
-	return
+	if rb.audit != nil {
+		rb.audit.reserved(id)
+	}
+	return id, nil
 }
 
 // CommitRead commit reader event for id.
 // It will wait if previous reader id havn't commit.
 // It will awake on writer wait list after commit OK.
+// Committing an id that is not reserved or already committed is a misuse,
+// see WithMisusePolicy.
 // It is goroutine-safe.
-func (rb *RingBuffer) CommitRead(wid int, id uint64) {
-	newId := id + 1
-
-	if rb.debug {
-		fn := rb.log("CommitRead")
-		defer fn()
+func (rb *RingBuffer) CommitRead(wid int, id uint64) (err error) {
+	if rb.instr != nil {
+		defer rb.instrumented(OpCommitRead, wid, time.Now(), &id, &err)
+	}
+	if rb.observer != nil {
+		defer rb.observeCommit(OpCommitRead, wid, id, id, &err)
+	}
+	if err := rb.checkCommit(id, &rb.rReserve, &rb.rCommit); err != nil {
+		return err
+	}
+	if rb.relaxedRead {
+		return rb.commitReadRelaxed(id)
 	}
 
+	var waitStart time.Time
 	try := 0
+	var t trace
+	if rb.debug.Load() {
+		t = rb.trace(OpCommitRead, wid)
+		defer func() { t.done(id, try) }()
+	}
+
 	for {
 		try++
-		if rb.debug {
-			fmt.Printf("CommitRead try=%d wid=%d %s\n", try, wid, rb.Show())
+		if rb.debug.Load() {
+			t.try(id, try)
 		}
 
-		if atomic.CompareAndSwapUint64(&rb.rCommit, id, newId) {
-			rb.waitWriteR.Broadcast() //wakeup writer
-			rb.waitReadC.Broadcast()  //wakeup read committer
+		if rb.tryCommitRead(id) {
 			break
 		}
+		waitStart = rb.waitBegin(waitStart)
+
+		if rb.strategy().Spin(context.Background(), try, rb.yield) {
+			continue
+		}
 
 		//commit fail, wait as writer in order to wakeup by another reader
-		rb.waitReadC.L.Lock()
-		rb.waitReadC.Wait()
-		rb.waitReadC.L.Unlock()
+		rb.waitReadC.wait(id, func() bool { return rb.rCommit.Load() == id })
+		rb.observeWakeup(OpCommitRead, wid)
+	}
+	rb.waitEnd(OpCommitRead, wid, waitStart)
+	return nil
+}
+
+// commitWriteDone flags the slots of write ids [lo, hi] available and moves
+// the write commit over them if they are next in line.
+// writeDone holds id+1 once write id is done, which never matches the later
+// ids sharing the slot.
+func (rb *RingBuffer) commitWriteDone(lo, hi uint64) error {
+	for id := lo; id <= hi; id++ {
+		if rb.writeDone[rb.BufferIndex(id)].Swap(id+1) == id+1 {
+			return rb.misuse(fmt.Errorf("%w %d", ErrCommitted, id))
+		}
+	}
+	rb.advanceWrite()
+	return nil
+}
+
+// advanceWrite moves the write commit over every contiguous available slot
+// and aborted id.
+// A writer flags its slot before loading the write commit, and one moving
+// the commit loads the flags after, so either sees the other's progress.
+func (rb *RingBuffer) advanceWrite() {
+	for {
+		c := rb.wCommit.Load()
+		n := c
+		for n-c < uint64(rb.size) && rb.writeDone[rb.BufferIndex(n)].Load() == n+1 {
+			n++
+		}
+		if n == c {
+			if rb.tombs.count.Load() > 0 && rb.advanceTombstones() {
+				continue
+			}
+			return
+		}
+		if rb.wCommit.CompareAndSwap(c, n) {
+			rb.published(c, n)
+		}
+	}
+}
+
+// published runs the bookkeeping of a write commit of ids [id, newId).
+func (rb *RingBuffer) published(id, newId uint64) {
+	if rb.idlePeriod > 0 {
+		rb.lastWrite.Store(time.Now().UnixNano())
+	}
+	if rb.timeIndex != nil {
+		rb.timeIndex.recordRange(id, newId)
+	}
+	rb.waitReadR.wake(newId)  //wakeup reader
+	rb.waitWriteC.wake(newId) //wakeup write committer
+	if rb.overwrite {
+		rb.waitWriteR.wake(math.MaxUint64) //wakeup writer, it may drop id now
+	}
+}
+
+// tryCommitRead commits read id if it is the next to commit.
+func (rb *RingBuffer) tryCommitRead(id uint64) bool {
+	return rb.tryCommitReadN(id, id)
+}
+
+// tryCommitReadN commits read ids [lo, hi] if lo is the next to commit.
+func (rb *RingBuffer) tryCommitReadN(lo, hi uint64) bool {
+	if !rb.casCommitRead(lo, hi) {
+		return false
+	}
+	if rb.overwrite {
+		rb.commitDropped()
+	}
+	return true
+}
+
+// casCommitRead moves the read commit from lo over hi, if it is at lo.
+func (rb *RingBuffer) casCommitRead(lo, hi uint64) bool {
+	newId := hi + 1
+	var buf [1]*Writer
+	owners := rb.slotOwners(buf[:0], lo, hi) //before the slots may be reused
+	if !rb.rCommit.CompareAndSwap(lo, newId) {
+		return false
+	}
+	for _, owner := range owners {
+		owner.release()
+	}
+	if rb.audit != nil {
+		for id := lo + 1; id <= newId; id++ {
+			rb.audit.committed(id)
+		}
+	}
+	rb.waitWriteR.wake(newId) //wakeup writer
+	rb.waitReadC.wake(newId)  //wakeup read committer
+	rb.waitBarrier.wake(newId)
+	return true
+}
+
+// TryCommitWrite commits write id like CommitWrite. As CommitWrite never
+// waits, it always succeeds unless id is misused.
+// It is goroutine-safe.
+func (rb *RingBuffer) TryCommitWrite(wid int, id uint64) (ok bool, err error) {
+	if rb.instr != nil || rb.observer != nil {
+		defer rb.committedTry(OpCommitWrite, wid, time.Now(), id, &ok, &err)
+	}
+	if err := rb.checkCommit(id, &rb.wReserve, &rb.wCommit); err != nil {
+		return false, err
+	}
+	if rb.instr != nil {
+		rb.stampPublish(id, id)
+	}
+	if err := rb.commitWriteDone(id, id); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// TryCommitRead commits read id like CommitRead, but fails fast with false
+// instead of waiting when a previous reader id hasn't committed yet.
+// The caller may do other work and try again.
+// It is goroutine-safe.
+func (rb *RingBuffer) TryCommitRead(wid int, id uint64) (ok bool, err error) {
+	if rb.instr != nil || rb.observer != nil {
+		defer rb.committedTry(OpCommitRead, wid, time.Now(), id, &ok, &err)
+	}
+	if err := rb.checkCommit(id, &rb.rReserve, &rb.rCommit); err != nil {
+		return false, err
+	}
+	if rb.relaxedRead {
+		return true, rb.commitReadRelaxed(id)
+	}
+	return rb.tryCommitRead(id), nil
+}
+
+// canWrite reports whether write id fits in the buffer.
+func (rb *RingBuffer) canWrite(id uint64) bool {
+	if rb.pins.count.Load() > 0 || rb.broadcast != nil {
+		return id < rb.readFloor()+uint64(rb.size)
+	}
+	return id < rb.rCommit.Load()+uint64(rb.size)
+}
+
+// writeNeed returns the read commit value that lets write id go on.
+func (rb *RingBuffer) writeNeed(id uint64) uint64 {
+	if id < uint64(rb.size) {
+		return 0
+	}
+	return id - uint64(rb.size) + 1
+}
+
+// readCommit returns the read commit, or under WithBroadcast the cursor of
+// the slowest Subscriber.
+func (rb *RingBuffer) readCommit() uint64 {
+	if rb.broadcast != nil {
+		return rb.broadcast.floor(rb)
+	}
+	return rb.rCommit.Load()
+}
+
+// canRead reports whether read id has been committed by a writer.
+func (rb *RingBuffer) canRead(id uint64) bool {
+	return id < rb.readLimit()
+}
+
+// readStrategy returns the wait strategy of an empty-ring reader.
+// Readers that have seen no write for idlePeriod use idleStrategy.
+func (rb *RingBuffer) readStrategy() WaitStrategy {
+	if rb.idlePeriod > 0 {
+		last := rb.lastWrite.Load()
+		if time.Now().UnixNano()-last >= int64(rb.idlePeriod) {
+			return rb.idleStrategy
+		}
+	}
+	return rb.strategy()
+}
+
+// waitWriteTurn waits until all writers before id have committed, so that
+// committing id will not block.
+func (rb *RingBuffer) waitWriteTurn(id uint64) {
+	ready := func() bool { return rb.wCommit.Load() == id }
+	for try := 1; !ready(); try++ {
+		if rb.strategy().Spin(context.Background(), try, rb.yield) {
+			continue
+		}
+		rb.waitWriteC.wait(id, ready)
 	}
 }
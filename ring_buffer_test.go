@@ -1 +1,575 @@
 package ringbuffer
+
+import (
+	"context"
+	"errors"
+	"math"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+// parallel sets GOMAXPROCS high enough for a RingBuffer and returns a func
+// restoring it.
+func parallel() func() {
+	old := runtime.GOMAXPROCS(4)
+	return func() { runtime.GOMAXPROCS(old) }
+}
+
+func TestTinyRingSequential(t *testing.T) {
+	defer parallel()()
+	for _, size := range []int{1, 2} {
+		rb := MustNewRingBuffer(size)
+		for round := 0; round < 5; round++ {
+			ids := make([]uint64, size)
+			for i := range ids {
+				ids[i], _ = rb.ReserveWrite(0)
+				rb.CommitWrite(0, ids[i])
+			}
+			if got := rb.Stats().WriteCommit - rb.Stats().ReadCommit; got != uint64(size) {
+				t.Fatalf("size %d: occupancy %d after filling", size, got)
+			}
+			for _, want := range ids {
+				id, _ := rb.ReserveRead(0)
+				if id != want {
+					t.Fatalf("size %d: read %d, want %d", size, id, want)
+				}
+				if idx := rb.BufferIndex(id); idx < 0 || idx >= size {
+					t.Fatalf("size %d: index %d out of range", size, idx)
+				}
+				rb.CommitRead(0, id)
+			}
+		}
+	}
+}
+
+func TestTinyRingMailbox(t *testing.T) {
+	defer parallel()()
+	for _, size := range []int{1, 2} {
+		const n = 10000
+		rb := MustNewRingBuffer(size)
+		slots := make([]int, size)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < n; i++ {
+				id, _ := rb.ReserveWrite(1)
+				slots[rb.BufferIndex(id)] = i
+				rb.CommitWrite(1, id)
+			}
+		}()
+		for i := 0; i < n; i++ {
+			id, _ := rb.ReserveRead(2)
+			if v := slots[rb.BufferIndex(id)]; v != i {
+				t.Fatalf("size %d: got %d, want %d", size, v, i)
+			}
+			rb.CommitRead(2, id)
+		}
+		<-done
+	}
+}
+
+// TestBufferIndexRange pins that slot storage of Size entries holds every
+// BufferIndex by default, and of Slots entries WithPowerOfTwoSlots.
+func TestBufferIndexRange(t *testing.T) {
+	defer parallel()()
+	for _, size := range []int{3, 5, 8, 100} {
+		for _, pow2 := range []bool{false, true} {
+			var opts []Option
+			if pow2 {
+				opts = append(opts, WithPowerOfTwoSlots())
+			}
+			rb := MustNewRingBuffer(size, opts...)
+			if rb.Size() != size {
+				t.Fatalf("size %d: Size() = %d", size, rb.Size())
+			}
+			slots := rb.Slots()
+			if !pow2 && slots != size || pow2 && (slots < size || slots&(slots-1) != 0) {
+				t.Fatalf("size %d, pow2 %v: Slots() = %d", size, pow2, slots)
+			}
+			seen := make([]bool, slots)
+			for id := uint64(0); id < uint64(3*slots); id++ {
+				idx := rb.BufferIndex(id)
+				if idx != int(id%uint64(slots)) {
+					t.Fatalf("size %d, pow2 %v: BufferIndex(%d) = %d", size, pow2, id, idx)
+				}
+				seen[idx] = true
+			}
+			for idx, ok := range seen {
+				if !ok {
+					t.Fatalf("size %d, pow2 %v: index %d never used", size, pow2, idx)
+				}
+			}
+		}
+	}
+}
+
+func TestTinyRingConcurrent(t *testing.T) {
+	defer parallel()()
+	strategies := []WaitStrategy{
+		BlockingWaitStrategy{},
+		YieldingWaitStrategy{Spins: 10},
+	}
+	for _, size := range []int{1, 2} {
+		for _, ws := range strategies {
+			const workers, n = 4, 2000
+			rb := MustNewRingBuffer(size, WithWaitStrategy(ws))
+			seen := make([]int32, workers*n)
+			var wg sync.WaitGroup
+			for w := 0; w < workers; w++ {
+				wg.Add(2)
+				go func(w int) {
+					defer wg.Done()
+					for i := 0; i < n; i++ {
+						id, _ := rb.ReserveWrite(w)
+						rb.CommitWrite(w, id)
+					}
+				}(w)
+				go func(w int) {
+					defer wg.Done()
+					for i := 0; i < n; i++ {
+						id, _ := rb.ReserveRead(w)
+						seen[id]++
+						rb.CommitRead(w, id)
+					}
+				}(w)
+			}
+			wg.Wait()
+			for id, c := range seen {
+				if c != 1 {
+					t.Fatalf("size %d %T: id %d read %d times", size, ws, id, c)
+				}
+			}
+		}
+	}
+}
+
+// BenchmarkDoubleBufferDrain drains frames of large slots.
+// Compare with -tags noprefetch to measure the prefetch hints.
+func BenchmarkDoubleBufferDrain(b *testing.B) {
+	defer parallel()()
+	type record struct {
+		payload [256]byte
+	}
+	const frameSize = 1024
+	d := MustNewDoubleBuffer[record](frameSize)
+	go func() {
+		var r record
+		for i := 0; i < b.N; i++ {
+			r.payload[0] = byte(i)
+			d.Put(r)
+		}
+		d.Close()
+	}()
+	sum := 0
+	b.SetBytes(int64(unsafe.Sizeof(record{})))
+	for {
+		_, ok := d.Drain(func(r record) {
+			for _, c := range r.payload {
+				sum += int(c)
+			}
+		})
+		if !ok {
+			break
+		}
+	}
+	_ = sum
+}
+
+func TestAbortWriteUnderLoad(t *testing.T) {
+	defer parallel()()
+	for _, size := range []int{1, 2, 16} {
+		const writers, readers, n = 4, 4, 3000
+		rb := MustNewRingBuffer(size)
+		type slot struct {
+			id   uint64
+			stop bool
+		}
+		slots := make([]slot, size)
+		var (
+			mu      sync.Mutex
+			aborted = map[uint64]bool{}
+			read    = map[uint64]int{}
+			wg      sync.WaitGroup
+		)
+		wg.Add(writers)
+		for w := 0; w < writers; w++ {
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < n; i++ {
+					id, _ := rb.ReserveWrite(w)
+					if (int(id)+w)%5 == 0 {
+						mu.Lock()
+						aborted[id] = true
+						mu.Unlock()
+						if err := rb.AbortWrite(w, id); err != nil {
+							t.Error(err)
+						}
+						continue
+					}
+					slots[rb.BufferIndex(id)] = slot{id: id}
+					rb.CommitWrite(w, id)
+				}
+			}(w)
+		}
+		var rg sync.WaitGroup
+		rg.Add(readers)
+		for r := 0; r < readers; r++ {
+			go func(r int) {
+				defer rg.Done()
+				for {
+					id, _ := rb.ReserveRead(r)
+					s := slots[rb.BufferIndex(id)]
+					rb.CommitRead(r, id)
+					if s.stop {
+						return
+					}
+					mu.Lock()
+					read[s.id]++
+					mu.Unlock()
+				}
+			}(r)
+		}
+		wg.Wait()
+		for r := 0; r < readers; r++ {
+			id, _ := rb.ReserveWrite(0)
+			slots[rb.BufferIndex(id)] = slot{stop: true}
+			rb.CommitWrite(0, id)
+		}
+		rg.Wait()
+
+		if len(read)+len(aborted) != writers*n {
+			t.Fatalf("size %d: read %d + aborted %d != %d", size, len(read), len(aborted), writers*n)
+		}
+		for id, c := range read {
+			if c != 1 || aborted[id] {
+				t.Fatalf("size %d: id %d read %d times, aborted %v", size, id, c, aborted[id])
+			}
+		}
+	}
+}
+
+// TestCancelUnderLoad cancels writers blocked on a full ring, by context
+// or timeout, while slow readers drain it, and checks that cancelled calls
+// leave no id behind.
+func TestCancelUnderLoad(t *testing.T) {
+	defer parallel()()
+	for _, opts := range [][]Option{nil, {WithReservationCap()}} {
+		const writers, readers, n = 8, 2, 500
+		rb := MustNewRingBuffer(4, opts...)
+		var (
+			mu        sync.Mutex
+			written   = map[uint64]bool{}
+			read      = map[uint64]int{}
+			cancelled int
+			wg        sync.WaitGroup
+		)
+		wg.Add(writers)
+		for w := 0; w < writers; w++ {
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < n; i++ {
+					var id uint64
+					var err error
+					if w%2 == 0 {
+						ctx, cancel := context.WithCancel(context.Background())
+						time.AfterFunc(time.Duration(i%3)*50*time.Microsecond, cancel)
+						id, err = rb.ReserveWriteContext(ctx, w)
+						cancel()
+					} else {
+						id, err = rb.ReserveWriteTimeout(w, time.Duration(i%3)*50*time.Microsecond)
+					}
+					mu.Lock()
+					if err != nil {
+						cancelled++
+					} else {
+						written[id] = true
+					}
+					mu.Unlock()
+					if err == nil {
+						rb.CommitWrite(w, id)
+					}
+				}
+			}(w)
+		}
+		done := make(chan struct{})
+		var rg sync.WaitGroup
+		rg.Add(readers)
+		for r := 0; r < readers; r++ {
+			go func(r int) {
+				defer rg.Done()
+				for {
+					id, err := rb.ReserveReadTimeout(writers+r, time.Millisecond)
+					if err != nil {
+						select {
+						case <-done:
+							return
+						default:
+							continue
+						}
+					}
+					mu.Lock()
+					read[id]++
+					mu.Unlock()
+					time.Sleep(10 * time.Microsecond)
+					rb.CommitRead(writers+r, id)
+				}
+			}(r)
+		}
+		wg.Wait()
+		for rb.Len() > 0 {
+			time.Sleep(time.Millisecond)
+		}
+		close(done)
+		rg.Wait()
+
+		if cancelled == 0 {
+			t.Fatal("no call was cancelled")
+		}
+		if len(written)+cancelled != writers*n {
+			t.Fatalf("written %d + cancelled %d != %d", len(written), cancelled, writers*n)
+		}
+		for id := range written {
+			if read[id] != 1 {
+				t.Fatalf("id %d read %d times", id, read[id])
+			}
+		}
+		if len(read) != len(written) {
+			t.Fatalf("read %d ids, written %d", len(read), len(written))
+		}
+		s := rb.Stats()
+		if s.WriteReserve != uint64(len(written)) || s.WriteCommit != s.WriteReserve || s.ReadCommit != s.WriteCommit {
+			t.Fatalf("cursors %+v after %d writes", s, len(written))
+		}
+	}
+}
+
+func TestNewRingBufferError(t *testing.T) {
+	defer parallel()()
+	for _, size := range []int{0, -1, MaxSize + 1} {
+		if rb, err := NewRingBuffer(size); rb != nil || !errors.Is(err, ErrInvalidSize) {
+			t.Fatalf("size %d: got %v, %v", size, rb, err)
+		}
+	}
+	if err := checkSlots(MaxSize, math.MaxInt/MaxSize+1); !errors.Is(err, ErrInvalidSize) {
+		t.Fatalf("slots overflowing an allocation: %v", err)
+	}
+	runtime.GOMAXPROCS(1)
+	if _, err := NewRingBuffer(8); err == nil {
+		t.Fatal("GOMAXPROCS 1: no error")
+	}
+}
+
+// TestConstructorErrors pins that the constructors built on NewRingBuffer
+// return its error instead of panicking, and their Must variants panic.
+func TestConstructorErrors(t *testing.T) {
+	defer parallel()()
+	handler := func(int) (int, error) { return 0, nil }
+	errs := map[string]error{}
+	_, errs["NewTypedRingBuffer"] = NewTypedRingBuffer[int](0)
+	_, errs["NewSPSCRingBuffer"] = NewSPSCRingBuffer(0)
+	_, errs["NewByteRingBuffer"] = NewByteRingBuffer(0)
+	_, errs["NewPool"] = NewPool(0, func() *int { return new(int) })
+	_, errs["NewChain"] = NewChain[int](4, 0)
+	_, errs["NewSharded"] = NewSharded(2, 0, func(int, int) {})
+	_, errs["NewCaller"] = NewCaller(0, 1, handler)
+	_, errs["NewDoubleBuffer"] = NewDoubleBuffer[int](0)
+	for name, err := range errs {
+		if !errors.Is(err, ErrInvalidSize) {
+			t.Errorf("%s: %v, want ErrInvalidSize", name, err)
+		}
+	}
+	if _, err := NewTypedRingBuffer[int](4, WithReleaser(func(*string) {})); err == nil {
+		t.Error("NewTypedRingBuffer: mismatched releaser accepted")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("MustNewTypedRingBuffer(0) did not panic")
+		}
+	}()
+	MustNewTypedRingBuffer[int](0)
+}
+
+// TestCommitWriteOutOfOrder pins that CommitWrite never waits for earlier
+// ids, and readers only see an id once every earlier one is committed.
+func TestCommitWriteOutOfOrder(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(4)
+	first, _ := rb.ReserveWrite(0)
+	second, _ := rb.ReserveWrite(1)
+	returned := make(chan error)
+	go func() { returned <- rb.CommitWrite(1, second) }()
+	select {
+	case err := <-returned:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("CommitWrite of id %d waits for id %d", second, first)
+	}
+	if _, ok := rb.TryReserveRead(0); ok {
+		t.Fatalf("id %d readable before id %d is committed", second, first)
+	}
+	rb.CommitWrite(0, first)
+	if got := rb.Stats().WriteCommit; got != second+1 {
+		t.Fatalf("write commit %d, want %d", got, second+1)
+	}
+	if err := rb.CommitWrite(1, second); !errors.Is(err, ErrCommitted) {
+		t.Fatalf("second CommitWrite: %v", err)
+	}
+}
+
+// TestCommitWriteConcurrent commits many ids out of order from writers
+// that hold several reservations and checks the reader sees every payload.
+func TestCommitWriteConcurrent(t *testing.T) {
+	defer parallel()()
+	const writers, n = 4, 3000
+	rb := MustNewRingBuffer(16)
+	slots := make([]uint64, rb.Slots())
+	var wg sync.WaitGroup
+	wg.Add(writers)
+	for w := 0; w < writers; w++ {
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				a, _ := rb.ReserveWrite(w)
+				//a second id must not wait for room: readers wait for a
+				if b, ok := rb.TryReserveWrite(w); ok && i+1 < n {
+					slots[rb.BufferIndex(b)] = b
+					rb.CommitWrite(w, b)
+					i++
+				} else if ok {
+					rb.AbortWrite(w, b)
+				}
+				slots[rb.BufferIndex(a)] = a
+				rb.CommitWrite(w, a)
+			}
+		}(w)
+	}
+	for i := 0; i < writers*n; i++ {
+		id, _ := rb.ReserveRead(0)
+		if v := slots[rb.BufferIndex(id)]; v != id {
+			t.Fatalf("id %d readable with payload %d", id, v)
+		}
+		rb.CommitRead(0, id)
+	}
+	wg.Wait()
+}
+
+func TestNameAndLabels(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(2, WithName("orders"), WithLabels("zone", "eu", "shard", "3"))
+	if rb.Name() != "orders" {
+		t.Fatalf("Name %q", rb.Name())
+	}
+	labels := rb.Labels()
+	if len(labels) != 2 || labels["zone"] != "eu" || labels["shard"] != "3" {
+		t.Fatalf("Labels %v", labels)
+	}
+	labels["zone"] = "us" //a copy
+	if rb.Labels()["zone"] != "eu" {
+		t.Fatal("Labels returned the ring map")
+	}
+	s := rb.Stats()
+	if s.Name != "orders" || s.Labels["shard"] != "3" {
+		t.Fatalf("Stats name %q, labels %v", s.Name, s.Labels)
+	}
+	if show := rb.Show(); !strings.Contains(show, " orders{shard=3,zone=eu} rR=0") {
+		t.Fatalf("Show %q lacks the sorted ring labels", show)
+	}
+
+	anon := MustNewRingBuffer(2)
+	if anon.Labels() != nil || strings.Contains(anon.Show(), "{") {
+		t.Fatalf("anonymous ring: labels %v, Show %q", anon.Labels(), anon.Show())
+	}
+}
+
+func TestLen(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(4)
+	for n := 1; n <= 3; n++ {
+		id, _ := rb.ReserveWrite(0)
+		rb.CommitWrite(0, id)
+		if rb.Len() != n || rb.ApproxLen() != n {
+			t.Fatalf("Len %d, ApproxLen %d, want %d", rb.Len(), rb.ApproxLen(), n)
+		}
+	}
+	r, _ := rb.ReserveRead(1)
+	if rb.Len() != 3 {
+		t.Fatalf("Len %d counts a reserved read, want 3 until its commit", rb.Len())
+	}
+	rb.CommitRead(1, r)
+	if rb.Len() != 2 || rb.ApproxLen() != 2 {
+		t.Fatalf("Len %d, ApproxLen %d after a read, want 2", rb.Len(), rb.ApproxLen())
+	}
+}
+
+// TestLenConcurrent scrapes the lengths of a busy ring, which must stay in
+// range.
+func TestLenConcurrent(t *testing.T) {
+	defer parallel()()
+	const n = 20000
+	rb := MustNewRingBuffer(8)
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < n; i++ {
+			id, _ := rb.ReserveWrite(0)
+			rb.CommitWrite(0, id)
+		}
+	}()
+	go func() {
+		defer close(done)
+		for i := 0; i < n; i++ {
+			id, _ := rb.ReserveRead(1)
+			rb.CommitRead(1, id)
+		}
+	}()
+	for {
+		select {
+		case <-done:
+			if rb.Len() != 0 {
+				t.Fatalf("Len %d after draining", rb.Len())
+			}
+			return
+		default:
+		}
+		if l := rb.Len(); l < 0 || l > rb.Slots() {
+			t.Fatalf("Len %d out of [0, %d]", l, rb.Slots())
+		}
+		if l := rb.ApproxLen(); l < 0 {
+			t.Fatalf("ApproxLen %d", l)
+		}
+	}
+}
+
+func TestNextSequence(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(2)
+	if id, wait := rb.NextReadSequence(); id != 0 || !wait {
+		t.Fatalf("NextReadSequence on an empty ring: %d, %v", id, wait)
+	}
+	for i := uint64(0); i < uint64(rb.Slots()); i++ {
+		if id, wait := rb.NextWriteSequence(); id != i || wait {
+			t.Fatalf("NextWriteSequence: %d, %v, want %d, false", id, wait, i)
+		}
+		id, _ := rb.ReserveWrite(0)
+		rb.CommitWrite(0, id)
+	}
+	if id, wait := rb.NextWriteSequence(); id != uint64(rb.Slots()) || !wait {
+		t.Fatalf("NextWriteSequence on a full ring: %d, %v", id, wait)
+	}
+	if id, wait := rb.NextReadSequence(); id != 0 || wait {
+		t.Fatalf("NextReadSequence: %d, %v, want 0, false", id, wait)
+	}
+	r, _ := rb.ReserveRead(1)
+	if id, _ := rb.NextReadSequence(); id != r+1 {
+		t.Fatalf("NextReadSequence %d after reserving %d", id, r)
+	}
+	rb.CommitRead(1, r)
+	if _, wait := rb.NextWriteSequence(); wait {
+		t.Fatal("NextWriteSequence would wait after a read commit")
+	}
+}
@@ -0,0 +1,294 @@
+// Package ringbuffertest provides a scriptable ring for testing code built
+// on ringbuffer: MockRing can be made full, closed, slow or failing at
+// will, so backpressure and error paths are unit tested without real
+// concurrency.
+package ringbuffertest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"ringbuffer"
+)
+
+// Ring is the method set of *ringbuffer.RingBuffer that MockRing scripts:
+// ringbuffer.Ring with the Try and Context variants.
+type Ring interface {
+	ringbuffer.Ring
+	TryReserveWrite(wid int) (uint64, bool)
+	ReserveWriteContext(ctx context.Context, wid int) (uint64, error)
+	TryReserveRead(wid int) (uint64, bool)
+	ReserveReadContext(ctx context.Context, wid int) (uint64, error)
+}
+
+var (
+	_ Ring = (*ringbuffer.RingBuffer)(nil)
+	_ Ring = (*MockRing)(nil)
+)
+
+// Op names a MockRing operation to script. The Try and Context variants
+// share the Op of their plain operation.
+type Op int
+
+const (
+	ReserveWrite Op = iota
+	CommitWrite
+	ReserveRead
+	CommitRead
+	numOps
+)
+
+// MockRing is a ring of size ids behaving like a RingBuffer, whose
+// behavior is scripted by the test: Full holds writers back as if the ring
+// was full, Close closes it, Delay slows an operation down and Fail makes
+// it return an error.
+// It is goroutine-safe.
+type MockRing struct {
+	size int
+
+	mu       sync.Mutex
+	cond     *sync.Cond // signaled on every state change
+	full     bool
+	closed   bool
+	delay    [numOps]time.Duration
+	errs     [numOps][]error // errors returned by the next calls
+	calls    [numOps]int
+	wReserve uint64
+	wCommit  uint64
+	rReserve uint64
+	rCommit  uint64
+	done     map[uint64]bool // write ids committed above wCommit
+}
+
+// NewMockRing returns an open, empty MockRing of size ids.
+func NewMockRing(size int) *MockRing {
+	m := &MockRing{size: size, done: make(map[uint64]bool)}
+	m.cond = sync.NewCond(&m.mu)
+	return m
+}
+
+// Full makes the ring look full, or not: TryReserveWrite fails and the
+// other write reservations wait, until Full(false) or Close.
+func (m *MockRing) Full(full bool) {
+	m.mu.Lock()
+	m.full = full
+	m.mu.Unlock()
+	m.cond.Broadcast()
+}
+
+// Delay makes every later call of op wait d first.
+func (m *MockRing) Delay(op Op, d time.Duration) {
+	m.mu.Lock()
+	m.delay[op] = d
+	m.mu.Unlock()
+}
+
+// Fail makes the next len(errs) calls of op return errs in turn, without
+// doing anything else. Try variants report false instead.
+func (m *MockRing) Fail(op Op, errs ...error) {
+	m.mu.Lock()
+	m.errs[op] = append(m.errs[op], errs...)
+	m.mu.Unlock()
+}
+
+// Calls returns the number of calls of op so far.
+func (m *MockRing) Calls(op Op) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls[op]
+}
+
+// Close closes the ring: later calls return ringbuffer.ErrClosed, and
+// waiting ones give up with it. Closing twice returns ErrClosed.
+func (m *MockRing) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return ringbuffer.ErrClosed
+	}
+	m.closed = true
+	m.cond.Broadcast()
+	return nil
+}
+
+// Size returns the size of the ring.
+func (m *MockRing) Size() int {
+	return m.size
+}
+
+// BufferIndex returns the slot of id, in [0, Size()).
+func (m *MockRing) BufferIndex(id uint64) int {
+	return int(id % uint64(m.size))
+}
+
+// begin counts a call of op and waits its delay, or until ctx is done. It
+// returns the scripted error of the call, if any, with m locked.
+func (m *MockRing) begin(ctx context.Context, op Op) error {
+	m.mu.Lock()
+	m.calls[op]++
+	d := m.delay[op]
+	m.mu.Unlock()
+	if d > 0 {
+		t := time.NewTimer(d)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			m.mu.Lock()
+			return ctx.Err()
+		}
+	}
+	m.mu.Lock()
+	if errs := m.errs[op]; len(errs) > 0 {
+		m.errs[op] = errs[1:]
+		return errs[0]
+	}
+	return nil
+}
+
+// wait waits with m locked until ready, Close or ctx is done.
+func (m *MockRing) wait(ctx context.Context, ready func() bool) error {
+	if ctx.Done() != nil {
+		stop := make(chan struct{})
+		defer close(stop)
+		go func() {
+			select {
+			case <-ctx.Done():
+				m.mu.Lock()
+				m.cond.Broadcast()
+				m.mu.Unlock()
+			case <-stop:
+			}
+		}()
+	}
+	for !ready() {
+		if m.closed {
+			return ringbuffer.ErrClosed
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		m.cond.Wait()
+	}
+	return nil
+}
+
+func (m *MockRing) canWrite() bool {
+	return !m.full && m.wReserve < m.rCommit+uint64(m.size)
+}
+
+func (m *MockRing) canRead() bool {
+	return m.rReserve < m.wCommit
+}
+
+func (m *MockRing) reserveWrite(ctx context.Context, wait bool) (uint64, error) {
+	err := m.begin(ctx, ReserveWrite)
+	defer m.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	if m.closed {
+		return 0, ringbuffer.ErrClosed
+	}
+	if !wait && !m.canWrite() {
+		return 0, errNotReady
+	}
+	if err := m.wait(ctx, m.canWrite); err != nil {
+		return 0, err
+	}
+	m.wReserve++
+	return m.wReserve - 1, nil
+}
+
+func (m *MockRing) reserveRead(ctx context.Context, wait bool) (uint64, error) {
+	err := m.begin(ctx, ReserveRead)
+	defer m.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	if !wait && !m.canRead() {
+		return 0, errNotReady
+	}
+	if err := m.wait(ctx, m.canRead); err != nil {
+		return 0, err
+	}
+	m.rReserve++
+	return m.rReserve - 1, nil
+}
+
+// errNotReady fails a Try call that would wait.
+var errNotReady = errors.New("ringbuffertest: not ready")
+
+// ReserveWrite reserves the next write id, see RingBuffer.ReserveWrite.
+func (m *MockRing) ReserveWrite(wid int) (uint64, error) {
+	return m.reserveWrite(context.Background(), true)
+}
+
+// TryReserveWrite reserves the next write id unless it would wait, see
+// RingBuffer.TryReserveWrite.
+func (m *MockRing) TryReserveWrite(wid int) (uint64, bool) {
+	id, err := m.reserveWrite(context.Background(), false)
+	return id, err == nil
+}
+
+// ReserveWriteContext reserves the next write id, or gives up once ctx is
+// done, see RingBuffer.ReserveWriteContext.
+func (m *MockRing) ReserveWriteContext(ctx context.Context, wid int) (uint64, error) {
+	return m.reserveWrite(ctx, true)
+}
+
+// CommitWrite publishes write id, see RingBuffer.CommitWrite.
+func (m *MockRing) CommitWrite(wid int, id uint64) error {
+	err := m.begin(context.Background(), CommitWrite)
+	defer m.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if id >= m.wReserve || id < m.wCommit || m.done[id] {
+		return ringbuffer.ErrNotReserved
+	}
+	m.done[id] = true
+	for m.done[m.wCommit] {
+		delete(m.done, m.wCommit)
+		m.wCommit++
+	}
+	m.cond.Broadcast()
+	return nil
+}
+
+// ReserveRead reserves the next read id, see RingBuffer.ReserveRead. The
+// ids written before Close are read first.
+func (m *MockRing) ReserveRead(wid int) (uint64, error) {
+	return m.reserveRead(context.Background(), true)
+}
+
+// TryReserveRead reserves the next read id unless it would wait, see
+// RingBuffer.TryReserveRead.
+func (m *MockRing) TryReserveRead(wid int) (uint64, bool) {
+	id, err := m.reserveRead(context.Background(), false)
+	return id, err == nil
+}
+
+// ReserveReadContext reserves the next read id, or gives up once ctx is
+// done, see RingBuffer.ReserveReadContext.
+func (m *MockRing) ReserveReadContext(ctx context.Context, wid int) (uint64, error) {
+	return m.reserveRead(ctx, true)
+}
+
+// CommitRead releases read id, see RingBuffer.CommitRead. Reads must be
+// committed in order.
+func (m *MockRing) CommitRead(wid int, id uint64) error {
+	err := m.begin(context.Background(), CommitRead)
+	defer m.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if id != m.rCommit || id >= m.rReserve {
+		return ringbuffer.ErrNotReserved
+	}
+	m.rCommit++
+	m.cond.Broadcast()
+	return nil
+}
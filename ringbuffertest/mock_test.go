@@ -0,0 +1,101 @@
+package ringbuffertest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ringbuffer"
+)
+
+func TestMockRing(t *testing.T) {
+	m := NewMockRing(2)
+	for i := uint64(0); i < 2; i++ {
+		id, err := m.ReserveWrite(0)
+		if err != nil || id != i {
+			t.Fatalf("ReserveWrite: %d, %v, want %d", id, err, i)
+		}
+		m.CommitWrite(0, id)
+	}
+	if _, ok := m.TryReserveWrite(0); ok {
+		t.Fatal("TryReserveWrite on a full ring succeeded")
+	}
+	id, err := m.ReserveRead(1)
+	if err != nil || id != 0 {
+		t.Fatalf("ReserveRead: %d, %v", id, err)
+	}
+	if err := m.CommitRead(1, 1); !errors.Is(err, ringbuffer.ErrNotReserved) {
+		t.Fatalf("CommitRead out of order: %v", err)
+	}
+	m.CommitRead(1, id)
+	if _, ok := m.TryReserveWrite(0); !ok {
+		t.Fatal("TryReserveWrite failed after a read")
+	}
+	if m.Calls(ReserveWrite) != 4 || m.Calls(CommitRead) != 2 {
+		t.Fatalf("calls: %d ReserveWrite, %d CommitRead", m.Calls(ReserveWrite), m.Calls(CommitRead))
+	}
+}
+
+func TestMockRingFull(t *testing.T) {
+	m := NewMockRing(4)
+	m.Full(true)
+	if _, ok := m.TryReserveWrite(0); ok {
+		t.Fatal("TryReserveWrite succeeded on a ring made full")
+	}
+	done := make(chan error)
+	go func() {
+		_, err := m.ReserveWrite(0)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		t.Fatalf("writer did not wait: %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	m.Full(false)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	m.Full(true)
+	go func() {
+		_, err := m.ReserveWrite(0)
+		done <- err
+	}()
+	m.Close()
+	if err := <-done; !errors.Is(err, ringbuffer.ErrClosed) {
+		t.Fatalf("waiting writer at Close: %v", err)
+	}
+	if err := m.Close(); !errors.Is(err, ringbuffer.ErrClosed) {
+		t.Fatalf("second Close: %v", err)
+	}
+}
+
+func TestMockRingFailAndDelay(t *testing.T) {
+	m := NewMockRing(4)
+	boom := errors.New("boom")
+	m.Fail(ReserveWrite, boom, ringbuffer.ErrFull)
+	if _, err := m.ReserveWrite(0); err != boom {
+		t.Fatalf("first call: %v, want boom", err)
+	}
+	if _, err := m.ReserveWrite(0); err != ringbuffer.ErrFull {
+		t.Fatalf("second call: %v, want ErrFull", err)
+	}
+	if _, err := m.ReserveWrite(0); err != nil {
+		t.Fatalf("third call: %v, want the script exhausted", err)
+	}
+
+	m.Delay(ReserveRead, time.Hour)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := m.ReserveReadContext(ctx, 1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("delayed ReserveReadContext: %v", err)
+	}
+	m.Delay(ReserveRead, 0)
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := m.ReserveReadContext(ctx, 1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ReserveReadContext on an empty ring: %v", err)
+	}
+}
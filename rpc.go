@@ -0,0 +1,147 @@
+package ringbuffer
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"unsafe"
+)
+
+// ErrHandlerPanic is wrapped by the error Call returns for a request whose
+// handler panicked.
+var ErrHandlerPanic = errors.New("RingBuffer: Caller handler panicked")
+
+// Caller is an in-process request/response primitive between goroutine
+// pools.
+// Call publishes a request into a ring; a server goroutine handles it and
+// puts the result back into the same slot, correlated by its sequence id.
+// The caller takes the result and then commits the read, so the slot is
+// not reused before the result is collected.
+// Read commits are in sequence order: a caller returns only after every
+// earlier caller collected its result.
+// A panicking handler is recovered, and its call fails with ErrHandlerPanic.
+type Caller[Req, Resp any] struct {
+	rb      *RingBuffer
+	slots   []call[Req, Resp]
+	handler func(Req) (Resp, error)
+	wg      sync.WaitGroup
+	servers int
+
+	mu     sync.RWMutex // orders Call against Close
+	closed bool
+}
+
+type call[Req, Resp any] struct {
+	req  Req
+	resp Resp
+	err  error
+	stop bool
+	done chan struct{}
+}
+
+// NewCaller starts servers goroutines serving calls with handler, with up to
+// size calls in flight. It fails where NewRingBuffer does.
+func NewCaller[Req, Resp any](size, servers int, handler func(Req) (Resp, error), opts ...Option) (*Caller[Req, Resp], error) {
+	rb, err := NewRingBuffer(size, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSlots(rb.Slots(), unsafe.Sizeof(call[Req, Resp]{})); err != nil {
+		return nil, err
+	}
+	c := &Caller[Req, Resp]{
+		rb:      rb,
+		handler: handler,
+		servers: servers,
+	}
+	c.slots = make([]call[Req, Resp], c.rb.Slots())
+	for i := range c.slots {
+		c.slots[i].done = make(chan struct{}, 1)
+	}
+	c.wg.Add(servers)
+	for i := 0; i < servers; i++ {
+		i := i
+		c.rb.Go("server", i, func() { c.serve(i) })
+	}
+	return c, nil
+}
+
+// MustNewCaller is NewCaller panicking on error.
+func MustNewCaller[Req, Resp any](size, servers int, handler func(Req) (Resp, error), opts ...Option) *Caller[Req, Resp] {
+	c, err := NewCaller(size, servers, handler, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// Call sends req to a server and waits for its response.
+// It returns ErrClosed after Close.
+// It is goroutine-safe.
+func (c *Caller[Req, Resp]) Call(req Req) (Resp, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.closed {
+		var zero Resp
+		return zero, ErrClosed
+	}
+	return c.call(req, false)
+}
+
+func (c *Caller[Req, Resp]) call(req Req, stop bool) (Resp, error) {
+	id, _ := c.rb.ReserveWrite(-1) //the ring of a Caller is never closed
+	sl := &c.slots[c.rb.BufferIndex(id)]
+	sl.req, sl.stop = req, stop
+	c.rb.CommitWrite(-1, id)
+
+	<-sl.done
+	var zero Req
+	var zeroResp Resp
+	resp, err := sl.resp, sl.err
+	sl.req, sl.resp, sl.err = zero, zeroResp, nil
+	c.rb.CommitRead(-1, id)
+	return resp, err
+}
+
+// Close stops the servers after every pending call is served.
+// Call returns ErrClosed after Close.
+func (c *Caller[Req, Resp]) Close() {
+	c.mu.Lock()
+	if !c.closed {
+		c.closed = true
+		var zero Req
+		for i := 0; i < c.servers; i++ {
+			c.call(zero, true)
+		}
+	}
+	c.mu.Unlock()
+	c.wg.Wait()
+}
+
+func (c *Caller[Req, Resp]) serve(wid int) {
+	defer c.wg.Done()
+	for {
+		id, _ := c.rb.ReserveRead(wid)
+		sl := &c.slots[c.rb.BufferIndex(id)]
+		stop := sl.stop
+		if !stop {
+			sl.resp, sl.err = c.handle(sl.req)
+		}
+		sl.done <- struct{}{} //piggyback the result, the caller commits
+		if stop {
+			return
+		}
+	}
+}
+
+// handle runs the handler on req, returning a panic as an error, so the
+// caller is not left waiting and the server keeps serving.
+func (c *Caller[Req, Resp]) handle(req Req) (resp Resp, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			var zero Resp
+			resp, err = zero, fmt.Errorf("%w: %v", ErrHandlerPanic, v)
+		}
+	}()
+	return c.handler(req)
+}
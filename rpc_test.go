@@ -0,0 +1,31 @@
+package ringbuffer
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestCallerHandlerPanic checks a panicking handler fails its call only,
+// and the servers keep serving.
+func TestCallerHandlerPanic(t *testing.T) {
+	defer parallel()()
+	c := MustNewCaller(4, 1, func(n int) (int, error) {
+		if n < 0 {
+			panic("negative")
+		}
+		return n * 2, nil
+	})
+	defer c.Close()
+	if _, err := c.Call(-1); !errors.Is(err, ErrHandlerPanic) {
+		t.Fatalf("Call with a panicking handler: %v, want ErrHandlerPanic", err)
+	}
+	for n := 0; n < 8; n++ {
+		got, err := c.Call(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != n*2 {
+			t.Fatalf("Call(%d) = %d, want %d", n, got, n*2)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package ringbuffer
+
+import (
+	"math"
+	"runtime/metrics"
+	"time"
+)
+
+// RuntimeStats are Go runtime metrics sampled with the Stats of a ring, see
+// WithRuntimeStats. They are cumulative since the process started, so any
+// number of consumers can sample them independently; diff GCCycles of two
+// Stats for a window. Pauses and latencies are bucketed by the runtime, so
+// they are approximate.
+type RuntimeStats struct {
+	Goroutines      uint64
+	GCCycles        uint64        // completed since the process started
+	GCPauseP99      time.Duration // stop the world pauses for GC
+	GCPauseMax      time.Duration
+	SchedLatencyP99 time.Duration // time runnable goroutines waited to run
+	SchedLatencyMax time.Duration
+}
+
+// WithRuntimeStats makes Stats sample runtime metrics, GC pauses, goroutine
+// count and scheduler latency, into Stats.Runtime, to correlate ring stalls
+// with GC or scheduler events when chasing tail latency. The metrics are
+// process wide; sampling them costs a few microseconds per Stats.
+func WithRuntimeStats() Option {
+	return func(rb *RingBuffer) {
+		rb.runtimeStats = newRuntimeSampler()
+	}
+}
+
+// runtimeSampler reads runtime metrics. It keeps no state between reads.
+type runtimeSampler struct {
+	names []string // pause, goroutines, cycles, latencies
+}
+
+// runtime/metrics names, the pause one renamed in Go 1.22.
+var (
+	gcPauseMetrics = []string{"/sched/pauses/total/gc:seconds", "/gc/pauses:seconds"}
+	otherMetrics   = []string{"/sched/goroutines:goroutines", "/gc/cycles/total:gc-cycles", "/sched/latencies:seconds"}
+)
+
+func newRuntimeSampler() *runtimeSampler {
+	supported := make(map[string]bool)
+	for _, d := range metrics.All() {
+		supported[d.Name] = true
+	}
+	pause := gcPauseMetrics[len(gcPauseMetrics)-1]
+	for _, name := range gcPauseMetrics {
+		if supported[name] {
+			pause = name
+			break
+		}
+	}
+	return &runtimeSampler{names: append([]string{pause}, otherMetrics...)}
+}
+
+// read samples the metrics into rs.
+// It is goroutine-safe.
+func (s *runtimeSampler) read(rs *RuntimeStats) {
+	samples := make([]metrics.Sample, len(s.names))
+	for i, name := range s.names {
+		samples[i].Name = name
+	}
+	metrics.Read(samples)
+	if v := samples[1].Value; v.Kind() == metrics.KindUint64 {
+		rs.Goroutines = v.Uint64()
+	}
+	if v := samples[2].Value; v.Kind() == metrics.KindUint64 {
+		rs.GCCycles = v.Uint64()
+	}
+	rs.GCPauseP99, rs.GCPauseMax = histogram(samples[0].Value)
+	rs.SchedLatencyP99, rs.SchedLatencyMax = histogram(samples[3].Value)
+}
+
+// histogram returns the 99th percentile and max of v.
+func histogram(v metrics.Value) (p99, max time.Duration) {
+	if v.Kind() != metrics.KindFloat64Histogram {
+		return 0, 0
+	}
+	h := v.Float64Histogram()
+	total := uint64(0)
+	for _, c := range h.Counts {
+		total += c
+	}
+	if total == 0 {
+		return 0, 0
+	}
+	rank := uint64(math.Ceil(float64(total) * 0.99))
+	seen := uint64(0)
+	for b, c := range h.Counts {
+		if c == 0 {
+			continue
+		}
+		seen += c
+		bound := bucketBound(h.Buckets, b)
+		if seen >= rank && p99 == 0 {
+			p99 = bound
+		}
+		max = bound
+	}
+	return p99, max
+}
+
+// bucketBound returns the upper bound of bucket b as a duration, its lower
+// bound for the last, unbounded bucket.
+func bucketBound(buckets []float64, b int) time.Duration {
+	hi := buckets[b+1]
+	if math.IsInf(hi, 1) {
+		hi = buckets[b]
+	}
+	return time.Duration(hi * float64(time.Second))
+}
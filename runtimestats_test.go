@@ -0,0 +1,32 @@
+package ringbuffer
+
+import (
+	"runtime"
+	"testing"
+)
+
+// TestRuntimeStats checks every Stats reports the cumulative metrics, not
+// what happened since the Stats of another consumer.
+func TestRuntimeStats(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(4, WithRuntimeStats())
+	if MustNewRingBuffer(4).Stats().Runtime != nil {
+		t.Fatal("runtime stats sampled without WithRuntimeStats")
+	}
+	runtime.GC()
+	a := rb.Stats().Runtime
+	b := rb.Stats().Runtime
+	if a.GCCycles == 0 || b.GCCycles < a.GCCycles {
+		t.Fatalf("GC cycles %d then %d after runtime.GC", a.GCCycles, b.GCCycles)
+	}
+	if b.Goroutines == 0 {
+		t.Fatal("no goroutines reported")
+	}
+	if b.GCPauseMax == 0 || b.GCPauseP99 > b.GCPauseMax {
+		t.Fatalf("GC pause p99 %v, max %v", b.GCPauseP99, b.GCPauseMax)
+	}
+	runtime.GC()
+	if c := rb.Stats().Runtime; c.GCCycles <= b.GCCycles {
+		t.Fatalf("GC cycles %d after another runtime.GC, was %d", c.GCCycles, b.GCCycles)
+	}
+}
@@ -0,0 +1,85 @@
+package ringbuffer
+
+import (
+	"sync"
+	"time"
+)
+
+// Sample is a Stats snapshot taken by a Sampler.
+type Sample struct {
+	Time time.Time
+	Stats
+}
+
+// Sampler snapshots the Stats of a ring at fixed intervals into a small
+// history, so a debug endpoint can show depth and lag trends without
+// external monitoring.
+type Sampler struct {
+	rb   *RingBuffer
+	stop chan struct{}
+	once sync.Once
+	done chan struct{}
+
+	mu      sync.Mutex
+	history []Sample // ring of the last samples
+	next    int      // index of the next sample in history
+	full    bool
+}
+
+// NewSampler starts sampling the Stats of rb every interval, keeping the
+// last n samples, at least one.
+func NewSampler(rb *RingBuffer, interval time.Duration, n int) *Sampler {
+	if n < 1 {
+		n = 1
+	}
+	s := &Sampler{
+		rb:      rb,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+		history: make([]Sample, n),
+	}
+	rb.Go("sampler", 0, func() {
+		defer close(s.done)
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case now := <-t.C:
+				s.add(Sample{Time: now, Stats: rb.Stats()})
+			case <-s.stop:
+				return
+			}
+		}
+	})
+	return s
+}
+
+func (s *Sampler) add(sample Sample) {
+	s.mu.Lock()
+	s.history[s.next] = sample
+	s.next++
+	if s.next == len(s.history) {
+		s.next, s.full = 0, true
+	}
+	s.mu.Unlock()
+}
+
+// Samples returns the samples kept, oldest first.
+// It is goroutine-safe.
+func (s *Sampler) Samples() []Sample {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.full {
+		return append([]Sample(nil), s.history[:s.next]...)
+	}
+	out := make([]Sample, 0, len(s.history))
+	out = append(out, s.history[s.next:]...)
+	return append(out, s.history[:s.next]...)
+}
+
+// Stop stops sampling; Samples stays available.
+// It is goroutine-safe.
+func (s *Sampler) Stop() {
+	s.once.Do(func() { close(s.stop) })
+	<-s.done
+}
@@ -0,0 +1,39 @@
+package ringbuffer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSampler(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(4)
+	id, _ := rb.ReserveWrite(0)
+	rb.CommitWrite(0, id)
+	s := NewSampler(rb, time.Millisecond, 3)
+	for deadline := time.Now().Add(time.Second); len(s.Samples()) < 3; {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d samples after a second", len(s.Samples()))
+		}
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(5 * time.Millisecond) //wrap the history
+	s.Stop()
+	got := s.Samples()
+	if len(got) != 3 {
+		t.Fatalf("%d samples kept, want 3", len(got))
+	}
+	for i, sample := range got {
+		if sample.WriteCommit != 1 {
+			t.Fatalf("sample %d: write commit %d, want 1", i, sample.WriteCommit)
+		}
+		if i > 0 && !sample.Time.After(got[i-1].Time) {
+			t.Fatalf("samples not oldest first: %v after %v", sample.Time, got[i-1].Time)
+		}
+	}
+	n := len(s.Samples())
+	time.Sleep(5 * time.Millisecond)
+	if last := s.Samples()[n-1].Time; last != got[n-1].Time {
+		t.Fatal("sampled after Stop")
+	}
+}
@@ -0,0 +1,57 @@
+package ringbuffer
+
+import (
+	"sync"
+	"time"
+)
+
+// Scheduler publishes timer events into a ring at fixed intervals, so
+// consumers get time based triggers in-band, ordered with data events,
+// instead of selecting on separate tickers.
+type Scheduler[T any] struct {
+	ring *TypedRingBuffer[T]
+	stop chan struct{}
+	once sync.Once
+	wg   sync.WaitGroup
+	n    int // timers started, names their goroutines
+	mu   sync.Mutex
+}
+
+// NewScheduler returns a Scheduler publishing into ring.
+func NewScheduler[T any](ring *TypedRingBuffer[T]) *Scheduler[T] {
+	return &Scheduler[T]{ring: ring, stop: make(chan struct{})}
+}
+
+// Every publishes event(now) every d until Stop or the ring is closed.
+// A tick is skipped, not queued, while the previous event still waits for
+// a free slot.
+// It is goroutine-safe.
+func (s *Scheduler[T]) Every(d time.Duration, event func(now time.Time) T) {
+	s.mu.Lock()
+	i := s.n
+	s.n++
+	s.wg.Add(1)
+	s.mu.Unlock()
+	s.ring.rb.Go("scheduler", i, func() {
+		defer s.wg.Done()
+		t := time.NewTicker(d)
+		defer t.Stop()
+		for {
+			select {
+			case now := <-t.C:
+				if s.ring.Put(event(now)) != nil {
+					return
+				}
+			case <-s.stop:
+				return
+			}
+		}
+	})
+}
+
+// Stop stops every timer and waits for pending events to be published.
+// It is goroutine-safe.
+func (s *Scheduler[T]) Stop() {
+	s.once.Do(func() { close(s.stop) })
+	s.wg.Wait()
+}
@@ -0,0 +1,62 @@
+package ringbuffer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduler(t *testing.T) {
+	defer parallel()()
+	ring := MustNewTypedRingBuffer[string](8)
+	s := NewScheduler(ring)
+	s.Every(2*time.Millisecond, func(time.Time) string { return "fast" })
+	s.Every(5*time.Millisecond, func(time.Time) string { return "slow" })
+	seen := map[string]int{}
+	for seen["slow"] < 2 {
+		v, err := ring.Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen[v]++
+	}
+	if seen["fast"] < 2 {
+		t.Fatalf("events %v, want more fast ticks than slow ones", seen)
+	}
+	done := make(chan struct{})
+	go func() {
+		for {
+			if _, err := ring.Get(); err != nil {
+				close(done)
+				return
+			}
+		}
+	}()
+	s.Stop()
+	n := ring.RingBuffer().Stats().WriteCommit
+	time.Sleep(10 * time.Millisecond)
+	if got := ring.RingBuffer().Stats().WriteCommit; got != n {
+		t.Fatalf("%d events published after Stop", got-n)
+	}
+	ring.RingBuffer().Close()
+	<-done
+}
+
+// TestSchedulerClosed checks timers stop once their ring is closed.
+func TestSchedulerClosed(t *testing.T) {
+	defer parallel()()
+	ring := MustNewTypedRingBuffer[int](2)
+	s := NewScheduler(ring)
+	s.Every(time.Millisecond, func(time.Time) int { return 1 })
+	ring.RingBuffer().Close()
+	stopped := make(chan struct{})
+	go func() {
+		s.wg.Wait() //the timer returns on its own
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("timer still running after the ring was closed")
+	}
+	s.Stop()
+}
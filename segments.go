@@ -0,0 +1,119 @@
+package ringbuffer
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Segments is slot storage for a ring of large payloads, allocated in
+// chunks of segSize slots on first use. Release frees the chunks no live
+// id maps to, so a ring sized for rare peaks only holds the memory of its
+// steady state.
+type Segments[T any] struct {
+	rb      *RingBuffer
+	segSize int
+	segs    []atomic.Value // of segment[T]
+	mu      sync.Mutex     // serializes allocation and Release
+}
+
+type segment[T any] struct {
+	slots []T
+}
+
+// NewSegments returns the storage of the slots of rb in chunks of segSize.
+func NewSegments[T any](rb *RingBuffer, segSize int) *Segments[T] {
+	if segSize <= 0 || segSize > rb.Slots() {
+		segSize = rb.Slots()
+	}
+	s := &Segments[T]{
+		rb:      rb,
+		segSize: segSize,
+		segs:    make([]atomic.Value, (rb.Slots()+segSize-1)/segSize),
+	}
+	for i := range s.segs {
+		s.segs[i].Store(segment[T]{})
+	}
+	return s
+}
+
+// Slot returns the slot of id, allocating its chunk if needed.
+// id must be reserved, for write or read, and not committed.
+// It is goroutine-safe.
+func (s *Segments[T]) Slot(id uint64) *T {
+	idx := s.rb.BufferIndex(id)
+	k, i := idx/s.segSize, idx%s.segSize
+	if seg := s.segs[k].Load().(segment[T]); seg.slots != nil {
+		return &seg.slots[i]
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seg := s.segs[k].Load().(segment[T])
+	if seg.slots == nil {
+		seg.slots = make([]T, s.chunkLen(k))
+		s.segs[k].Store(seg)
+	}
+	return &seg.slots[i]
+}
+
+// chunkLen returns the number of slots of chunk k; the last may be short.
+func (s *Segments[T]) chunkLen(k int) int {
+	if n := s.rb.Slots() - k*s.segSize; n < s.segSize {
+		return n
+	}
+	return s.segSize
+}
+
+// Release frees the chunks that hold no live slot, and returns how many it
+// freed. Call it when the ring idles or after a peak.
+// It is goroutine-safe.
+func (s *Segments[T]) Release() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	freed := 0
+	for k := range s.segs {
+		seg := s.segs[k].Load().(segment[T])
+		if seg.slots == nil || s.live(k) {
+			continue
+		}
+		s.segs[k].Store(segment[T]{})
+		//a writer that reserved meanwhile may hold the old chunk: check
+		//again and put it back if so
+		if s.live(k) {
+			s.segs[k].Store(seg)
+			continue
+		}
+		freed++
+	}
+	return freed
+}
+
+// live reports whether a reserved, uncommitted id maps to chunk k.
+func (s *Segments[T]) live(k int) bool {
+	lo := s.rb.readFloor()
+	hi := s.rb.wReserve.Load()
+	size := s.rb.Slots()
+	if hi <= lo {
+		return false
+	}
+	if hi-lo >= uint64(size) {
+		return true
+	}
+	//live indexes are [start, end), wrapping around the slots
+	start := s.rb.BufferIndex(lo)
+	end := start + int(hi-lo)
+	first, last := k*s.segSize, k*s.segSize+s.chunkLen(k)
+	overlaps := func(a, b int) bool { return a < last && first < b }
+	return overlaps(start, end) || (end > size && overlaps(0, end-size))
+}
+
+// Allocated returns the number of allocated chunks.
+// It is goroutine-safe.
+func (s *Segments[T]) Allocated() int {
+	n := 0
+	for k := range s.segs {
+		if s.segs[k].Load().(segment[T]).slots != nil {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,82 @@
+package ringbuffer
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSegmentsRelease(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(10)
+	s := NewSegments[int](rb, 4)
+	for i := 0; i < 6; i++ {
+		id, _ := rb.ReserveWrite(0)
+		*s.Slot(id) = i
+		rb.CommitWrite(0, id)
+	}
+	if got := s.Allocated(); got != 2 {
+		t.Fatalf("Allocated() = %d after 6 writes, want 2", got)
+	}
+	for i := 0; i < 5; i++ {
+		id, _ := rb.ReserveRead(0)
+		if v := *s.Slot(id); v != i {
+			t.Fatalf("got %d, want %d", v, i)
+		}
+		rb.CommitRead(0, id)
+	}
+	if got := s.Release(); got != 1 {
+		t.Fatalf("Release() = %d with id 5 unread, want 1", got)
+	}
+	id, _ := rb.ReserveRead(0)
+	if v := *s.Slot(id); v != 5 {
+		t.Fatalf("got %d after Release, want 5", v)
+	}
+	rb.CommitRead(0, id)
+	if got := s.Release(); got != 1 || s.Allocated() != 0 {
+		t.Fatalf("Release() = %d, Allocated() = %d on an empty ring", got, s.Allocated())
+	}
+}
+
+// TestSegmentsConcurrentRelease releases chunks while items flow and
+// checks no live slot is freed under a writer or reader.
+func TestSegmentsConcurrentRelease(t *testing.T) {
+	defer parallel()()
+	const writers, n = 4, 2000
+	rb := MustNewRingBuffer(16)
+	s := NewSegments[int](rb, 4)
+	var wg sync.WaitGroup
+	wg.Add(writers)
+	for w := 0; w < writers; w++ {
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				id, _ := rb.ReserveWrite(w)
+				*s.Slot(id) = int(id)
+				rb.CommitWrite(w, id)
+			}
+		}(w)
+	}
+	stop := make(chan struct{})
+	released := make(chan struct{})
+	go func() {
+		defer close(released)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				s.Release()
+			}
+		}
+	}()
+	for i := 0; i < writers*n; i++ {
+		id, _ := rb.ReserveRead(0)
+		if v := *s.Slot(id); v != int(id) {
+			t.Fatalf("id %d holds %d, its chunk was released", id, v)
+		}
+		rb.CommitRead(0, id)
+	}
+	wg.Wait()
+	close(stop)
+	<-released
+}
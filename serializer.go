@@ -0,0 +1,40 @@
+package ringbuffer
+
+// Serializer encodes values for rings whose records cross process or
+// restart boundaries, such as the disk segment of a SpillBuffer.
+// Implementations encode into and decode from caller provided buffers, so
+// the hot path needs no reflection and no allocation of its own.
+type Serializer[T any] interface {
+	// Marshal appends the encoding of *v to dst and returns the result.
+	Marshal(dst []byte, v *T) ([]byte, error)
+	// Unmarshal decodes src into *v. src is only valid during the call.
+	Unmarshal(src []byte, v *T) error
+}
+
+// BytesSerializer is the Serializer of byte slices.
+type BytesSerializer struct{}
+
+// Marshal implements Serializer.
+func (BytesSerializer) Marshal(dst []byte, v *[]byte) ([]byte, error) {
+	return append(dst, *v...), nil
+}
+
+// Unmarshal implements Serializer.
+func (BytesSerializer) Unmarshal(src []byte, v *[]byte) error {
+	*v = append((*v)[:0], src...)
+	return nil
+}
+
+// StringSerializer is the Serializer of strings.
+type StringSerializer struct{}
+
+// Marshal implements Serializer.
+func (StringSerializer) Marshal(dst []byte, v *string) ([]byte, error) {
+	return append(dst, *v...), nil
+}
+
+// Unmarshal implements Serializer.
+func (StringSerializer) Unmarshal(src []byte, v *string) error {
+	*v = string(src)
+	return nil
+}
@@ -0,0 +1,116 @@
+package ringbuffer
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// shadow runs every operation against both a RingBuffer and a mutex
+// protected reference queue, and reports any observable difference online.
+// The reference learns each item before the ring publishes it, so a reader
+// can always check what it got against it.
+type shadow[T comparable] struct {
+	rb    *RingBuffer
+	slots []T
+
+	mu      sync.Mutex
+	ref     map[uint64]T // reference queue, by id
+	read    map[uint64]bool
+	readMax uint64 // highest read id + 1
+	errs    []string
+}
+
+func newShadow[T comparable](size int, opts ...Option) *shadow[T] {
+	rb := MustNewRingBuffer(size, opts...)
+	return &shadow[T]{
+		rb:    rb,
+		slots: make([]T, rb.Slots()),
+		ref:   make(map[uint64]T),
+		read:  make(map[uint64]bool),
+	}
+}
+
+func (s *shadow[T]) failf(format string, args ...interface{}) {
+	s.mu.Lock()
+	s.errs = append(s.errs, fmt.Sprintf(format, args...))
+	s.mu.Unlock()
+}
+
+func (s *shadow[T]) put(wid int, v T) {
+	id, _ := s.rb.ReserveWrite(wid)
+	s.mu.Lock()
+	if _, ok := s.ref[id]; ok || s.read[id] {
+		s.errs = append(s.errs, fmt.Sprintf("write id %d handed out twice", id))
+	}
+	if id >= s.readMax+uint64(s.rb.Size()) {
+		s.errs = append(s.errs, fmt.Sprintf("write id %d reserved beyond the free slots", id))
+	}
+	s.ref[id] = v
+	s.mu.Unlock()
+	s.slots[s.rb.BufferIndex(id)] = v
+	s.rb.CommitWrite(wid, id)
+}
+
+func (s *shadow[T]) get(wid int) T {
+	id, _ := s.rb.ReserveRead(wid)
+	v := s.slots[s.rb.BufferIndex(id)]
+	s.mu.Lock()
+	want, ok := s.ref[id]
+	switch {
+	case s.read[id]:
+		s.errs = append(s.errs, fmt.Sprintf("read id %d handed out twice", id))
+	case !ok:
+		s.errs = append(s.errs, fmt.Sprintf("read id %d was never written", id))
+	case v != want:
+		s.errs = append(s.errs, fmt.Sprintf("read id %d: got %v, want %v", id, v, want))
+	}
+	delete(s.ref, id)
+	s.read[id] = true
+	if id+1 > s.readMax {
+		s.readMax = id + 1
+	}
+	s.mu.Unlock()
+	s.rb.CommitRead(wid, id)
+	return v
+}
+
+// check reports the differences seen so far, and that the ring and the
+// reference agree on the items left.
+func (s *shadow[T]) check(t *testing.T) {
+	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n := s.rb.Len(); n != len(s.ref) {
+		s.errs = append(s.errs, fmt.Sprintf("ring holds %d items, reference %d", n, len(s.ref)))
+	}
+	for _, e := range s.errs {
+		t.Error(e)
+	}
+}
+
+func TestShadowConcurrent(t *testing.T) {
+	defer parallel()()
+	for _, size := range []int{1, 3, 64} {
+		s := newShadow[int](size)
+		const workers, n = 4, 2000
+		var wg sync.WaitGroup
+		wg.Add(2 * workers)
+		for w := 0; w < workers; w++ {
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < n; i++ {
+					s.put(w, w*n+i)
+				}
+			}(w)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < n; i++ {
+					s.get(w)
+				}
+			}(w)
+		}
+		wg.Wait()
+		s.check(t)
+	}
+}
@@ -0,0 +1,131 @@
+package ringbuffer
+
+import (
+	"hash/fnv"
+	"sync"
+	"unsafe"
+)
+
+// Sharded delivers items in per-key order over a set of rings.
+// Keys are spread over shards by jump consistent hashing, and every shard
+// has exactly one consumer goroutine, so the items of a key are handled one
+// at a time in the order they were published.
+type Sharded[T any] struct {
+	shards  []*shard[T]
+	handler func(shard int, v T)
+	wg      sync.WaitGroup
+	once    sync.Once
+}
+
+type shard[T any] struct {
+	rb    *RingBuffer
+	slots []T
+}
+
+// NewSharded starts n shards of size slots each. handler is called from
+// the consumer goroutine of a shard for every item published to it.
+// Options apply to every shard ring, which is closed with drain, see
+// WithDrainOnClose. It fails where NewRingBuffer does, and if size slots
+// of T don't fit in memory.
+func NewSharded[T any](n, size int, handler func(shard int, v T), opts ...Option) (*Sharded[T], error) {
+	s := &Sharded[T]{
+		shards:  make([]*shard[T], n),
+		handler: handler,
+	}
+	opts = append(opts[:len(opts):len(opts)], WithDrainOnClose())
+	for i := range s.shards {
+		rb, err := NewRingBuffer(size, opts...)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkSlots(rb.Slots(), unsafe.Sizeof(*new(T))); err != nil {
+			return nil, err
+		}
+		s.shards[i] = &shard[T]{rb: rb, slots: make([]T, rb.Slots())}
+	}
+	s.wg.Add(n)
+	for i := range s.shards {
+		i := i
+		s.shards[i].rb.Go("shard", i, func() { s.consume(i) })
+	}
+	return s, nil
+}
+
+// MustNewSharded is NewSharded panicking on error.
+func MustNewSharded[T any](n, size int, handler func(shard int, v T), opts ...Option) *Sharded[T] {
+	s, err := NewSharded(n, size, handler, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Shard returns the shard of key.
+func (s *Sharded[T]) Shard(key uint64) int {
+	return jumpHash(key, len(s.shards))
+}
+
+// Publish queues v on the shard of key. It waits while that shard is full,
+// and returns ErrClosed once s is closed.
+// It is goroutine-safe.
+func (s *Sharded[T]) Publish(key uint64, v T) error {
+	return s.shards[s.Shard(key)].push(v)
+}
+
+// PublishString is Publish with a string key.
+func (s *Sharded[T]) PublishString(key string, v T) error {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return s.Publish(h.Sum64(), v)
+}
+
+// Close stops publishing, waits until every item published before is
+// handled and stops the shard consumers. Later Publish calls, and those
+// waiting for a full shard, return ErrClosed.
+// It is goroutine-safe.
+func (s *Sharded[T]) Close() {
+	s.once.Do(func() {
+		for _, sh := range s.shards {
+			sh.rb.Close()
+		}
+	})
+	s.wg.Wait()
+}
+
+func (sh *shard[T]) push(v T) error {
+	id, err := sh.rb.ReserveWrite(-1)
+	if err != nil {
+		return err
+	}
+	sh.slots[sh.rb.BufferIndex(id)] = v
+	return sh.rb.CommitWrite(-1, id)
+}
+
+func (s *Sharded[T]) consume(i int) {
+	defer s.wg.Done()
+	sh := s.shards[i]
+	var zero T
+	for {
+		id, err := sh.rb.ReserveRead(i)
+		if err != nil {
+			return //closed and drained
+		}
+		idx := sh.rb.BufferIndex(id)
+		v := sh.slots[idx]
+		sh.slots[idx] = zero
+		sh.rb.CommitRead(i, id)
+		s.handler(i, v)
+	}
+}
+
+// jumpHash maps key to a bucket in [0, n), moving only 1/n of the keys when
+// n grows (Lamping & Veach, "A Fast, Minimal Memory, Consistent Hash").
+func jumpHash(key uint64, n int) int {
+	var b, j int64 = -1, 0
+	for j < int64(n) {
+		b = j
+		key = key*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((key>>33)+1)))
+	}
+	return int(b)
+}
@@ -0,0 +1,124 @@
+package ringbuffer
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestShardedKeyOrder publishes sequences of many keys from several
+// goroutines and checks every key is handled in its shard, in order.
+func TestShardedKeyOrder(t *testing.T) {
+	defer parallel()()
+	const shards, keys, n = 4, 64, 200
+	type item struct{ key, seq uint64 }
+	var (
+		mu   sync.Mutex
+		next = make([]uint64, keys)
+	)
+	var s *Sharded[item]
+	s = MustNewSharded(shards, 8, func(shard int, v item) {
+		if want := s.Shard(v.key); shard != want {
+			t.Errorf("key %d handled by shard %d, want %d", v.key, shard, want)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if v.seq != next[v.key] {
+			t.Errorf("key %d: got seq %d, want %d", v.key, v.seq, next[v.key])
+		}
+		next[v.key] = v.seq + 1
+	})
+	var wg sync.WaitGroup
+	wg.Add(keys / 8)
+	for p := 0; p < keys/8; p++ {
+		go func(p int) {
+			defer wg.Done()
+			for seq := uint64(0); seq < n; seq++ {
+				for key := uint64(p * 8); key < uint64(p*8+8); key++ {
+					if err := s.Publish(key, item{key, seq}); err != nil {
+						t.Error(err)
+						return
+					}
+				}
+			}
+		}(p)
+	}
+	wg.Wait()
+	s.Close()
+	for key, got := range next {
+		if got != n {
+			t.Fatalf("key %d: %d items handled before Close returned, want %d", key, got, n)
+		}
+	}
+}
+
+// TestShardedPublishClosed checks Close fails a Publish waiting on a full
+// shard, and the ones after it, while the items published before are
+// still handled.
+func TestShardedPublishClosed(t *testing.T) {
+	defer parallel()()
+	release := make(chan struct{})
+	var handled atomic.Int64
+	s := MustNewSharded(1, 2, func(int, int) {
+		<-release
+		handled.Add(1)
+	})
+	var published int64
+	errc := make(chan error)
+	go func() {
+		for {
+			if err := s.Publish(0, 1); err != nil {
+				errc <- err
+				return
+			}
+			published++
+		}
+	}()
+	time.Sleep(20 * time.Millisecond) //the publisher waits on the full shard
+	closed := make(chan struct{})
+	go func() {
+		s.Close()
+		close(closed)
+	}()
+	select {
+	case err := <-errc:
+		if !errors.Is(err, ErrClosed) {
+			t.Fatalf("Publish waiting at Close: %v, want ErrClosed", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Publish waiting on a full shard hangs after Close")
+	}
+	close(release)
+	<-closed
+	if got := handled.Load(); got != published {
+		t.Fatalf("%d items handled, want the %d published", got, published)
+	}
+	if err := s.Publish(0, 1); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Publish after Close: %v, want ErrClosed", err)
+	}
+	if err := s.PublishString("k", 1); !errors.Is(err, ErrClosed) {
+		t.Fatalf("PublishString after Close: %v, want ErrClosed", err)
+	}
+}
+
+func TestJumpHash(t *testing.T) {
+	const keys = 10000
+	moved := 0
+	for key := uint64(0); key < keys; key++ {
+		a, b := jumpHash(key, 10), jumpHash(key, 11)
+		if a < 0 || a >= 10 || b < 0 || b >= 11 {
+			t.Fatalf("key %d: buckets %d, %d out of range", key, a, b)
+		}
+		if a != b {
+			if b != 10 {
+				t.Fatalf("key %d moved from %d to %d, not to the new bucket", key, a, b)
+			}
+			moved++
+		}
+	}
+	if moved > keys/11*2 {
+		t.Fatalf("%d of %d keys moved growing to 11 buckets", moved, keys)
+	}
+}
@@ -0,0 +1,44 @@
+package ringbuffer
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// WithSLO counts ReserveWrite and ReserveRead calls that wait longer than
+// threshold for a slot or an item, see SLOBreaches, and calls fn, if not
+// nil, with each of them. Alerting on breaches tells when backpressure
+// actually hurt, which raw depth doesn't.
+// fn runs on the reserving goroutine and must not block.
+func WithSLO(threshold time.Duration, fn func(op string, wid int, waited time.Duration)) Option {
+	return func(rb *RingBuffer) {
+		rb.slo = &slo{threshold: threshold, fn: fn}
+	}
+}
+
+type slo struct {
+	threshold time.Duration
+	fn        func(op string, wid int, waited time.Duration)
+	breaches  atomic.Uint64
+}
+
+// SLOBreaches returns the number of reserve waits longer than the WithSLO
+// threshold so far.
+// It is goroutine-safe.
+func (rb *RingBuffer) SLOBreaches() uint64 {
+	if rb.slo == nil {
+		return 0
+	}
+	return rb.slo.breaches.Load()
+}
+
+// sloDone accounts a reserve wait against the SLO, if one is tracked.
+func (rb *RingBuffer) sloDone(op string, wid int, waited time.Duration) {
+	if rb.slo == nil || waited <= rb.slo.threshold {
+		return
+	}
+	rb.slo.breaches.Add(1)
+	if rb.slo.fn != nil {
+		rb.slo.fn(op, wid, waited)
+	}
+}
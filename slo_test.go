@@ -0,0 +1,41 @@
+package ringbuffer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSLO(t *testing.T) {
+	defer parallel()()
+	type breach struct {
+		op     string
+		wid    int
+		waited time.Duration
+	}
+	breaches := make(chan breach, 4)
+	rb := MustNewRingBuffer(2, WithSLO(5*time.Millisecond, func(op string, wid int, waited time.Duration) {
+		breaches <- breach{op, wid, waited}
+	}))
+	id, _ := rb.ReserveWrite(0)
+	rb.CommitWrite(0, id)
+	r, _ := rb.ReserveRead(1) //no wait
+	rb.CommitRead(1, r)
+	if n := rb.SLOBreaches(); n != 0 {
+		t.Fatalf("%d breaches without waiting", n)
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		id, _ := rb.ReserveWrite(0)
+		rb.CommitWrite(0, id)
+	}()
+	r, _ = rb.ReserveRead(1)
+	rb.CommitRead(1, r)
+	if n := rb.Stats().SLOBreaches; n != 1 {
+		t.Fatalf("%d breaches after a long read wait, want 1", n)
+	}
+	b := <-breaches
+	if b.op != OpReserveRead || b.wid != 1 || b.waited <= 5*time.Millisecond {
+		t.Fatalf("breach %+v", b)
+	}
+}
@@ -0,0 +1,62 @@
+package ringbuffer
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LevelTrace is the slog level of the "try" debug events, below
+// slog.LevelDebug of the "done" ones, as there is one per check of a
+// waiting operation.
+const LevelTrace = slog.LevelDebug - 4
+
+// WithLogger sends debug events to l instead of JSON lines on stdout, see
+// SlogSink.
+func WithLogger(l *slog.Logger) Option {
+	return WithDebugSink(SlogSink(l))
+}
+
+type slogSink struct {
+	l *slog.Logger
+}
+
+// SlogSink returns a sink logging events to l as "ringbuffer" records, at
+// LevelTrace for PhaseTry and slog.LevelDebug for PhaseDone, with the
+// Event fields as attributes and the ring labels in a "labels" group.
+// Events below the level l is enabled for are dropped before they are
+// formatted.
+func SlogSink(l *slog.Logger) DebugSink {
+	return slogSink{l: l}
+}
+
+func (s slogSink) Emit(e Event) {
+	level := slog.LevelDebug
+	if e.Phase == PhaseTry {
+		level = LevelTrace
+	}
+	ctx := context.Background()
+	if !s.l.Enabled(ctx, level) {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("ring", e.Ring),
+		slog.String("op", e.Op),
+		slog.String("phase", e.Phase),
+		slog.Int("wid", e.Wid),
+		slog.Uint64("id", e.ID),
+		slog.Uint64("rR", e.RReserve),
+		slog.Uint64("rC", e.RCommit),
+		slog.Uint64("wR", e.WReserve),
+		slog.Uint64("wC", e.WCommit),
+		slog.Int("try", e.Try),
+		slog.Duration("waited", e.Waited),
+	}
+	if len(e.Labels) > 0 {
+		labels := make([]any, 0, len(e.Labels))
+		for k, v := range e.Labels {
+			labels = append(labels, slog.String(k, v))
+		}
+		attrs = append(attrs, slog.Group("labels", labels...))
+	}
+	s.l.LogAttrs(ctx, level, "ringbuffer", attrs...)
+}
@@ -0,0 +1,64 @@
+package ringbuffer
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+// logged returns the records written by a slog JSON handler.
+func logged(t *testing.T, out *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	dec := json.NewDecoder(out)
+	for dec.More() {
+		var r map[string]any
+		if err := dec.Decode(&r); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, r)
+	}
+	return records
+}
+
+func TestWithLogger(t *testing.T) {
+	defer parallel()()
+	var out bytes.Buffer
+	l := slog.New(slog.NewJSONHandler(&out, &slog.HandlerOptions{Level: LevelTrace}))
+	rb := MustNewRingBuffer(2, WithName("orders"), WithLabels("shard", "3"), WithLogger(l), WithDebug())
+	id, _ := rb.ReserveWrite(1)
+	rb.CommitWrite(1, id)
+	records := logged(t, &out)
+	if len(records) == 0 {
+		t.Fatal("no records logged")
+	}
+	for _, r := range records {
+		if r["msg"] != "ringbuffer" || r["ring"] != "orders" || r["wid"] != 1.0 {
+			t.Fatalf("record %v", r)
+		}
+		if labels, _ := r["labels"].(map[string]any); labels["shard"] != "3" {
+			t.Fatalf("record labels %v", r["labels"])
+		}
+		want := slog.LevelDebug.String()
+		if r["phase"] == PhaseTry {
+			want = LevelTrace.String()
+		}
+		if r["level"] != want {
+			t.Fatalf("%v record at level %v, want %s", r["phase"], r["level"], want)
+		}
+	}
+}
+
+// TestSlogSinkLevel checks events below the logger level are dropped.
+func TestSlogSinkLevel(t *testing.T) {
+	defer parallel()()
+	var out bytes.Buffer
+	l := slog.New(slog.NewJSONHandler(&out, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	rb := MustNewRingBuffer(2, WithDebugSink(SlogSink(l)), WithDebug())
+	id, _ := rb.ReserveWrite(1)
+	rb.CommitWrite(1, id)
+	if records := logged(t, &out); len(records) != 0 {
+		t.Fatalf("%d records logged above the debug events", len(records))
+	}
+}
@@ -0,0 +1,259 @@
+package ringbuffer
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// SpillBuffer is a ring of records that overflows to disk.
+// When the ring is full, records are encoded by a Serializer and appended
+// to a spill file instead of blocking, then moved back into the ring in
+// order as readers free slots: memory stays bounded while bursts of any
+// length are absorbed.
+type SpillBuffer[T any] struct {
+	rb    *RingBuffer
+	slots []T
+	ends  []int64 // per slot spill file offset past its record, 0 unless loaded from disk
+	ser   Serializer[T]
+
+	mu      sync.Mutex // serializes Put, refill and consumed
+	file    *os.File
+	wOff    int64 // spill file write offset
+	rOff    int64 // spill file read offset, of the next record to load
+	cOff    int64 // spill file consumed offset, persisted in the file header
+	spilled int   // records on disk
+	loaded  int   // records moved back from disk and not taken by Get yet
+	hdr     [8]byte
+	buf     []byte
+	closed  atomic.Int32
+}
+
+// spillHeader is the size of the spill file header, the little endian
+// offset past the last record taken by Get, so ReplaySpills only delivers
+// the records that were not. Records follow, each a little endian uint32
+// length and the serialized record.
+const spillHeader = 8
+
+// NewSpillBuffer returns a SpillBuffer of size in-memory records that spills
+// into a temporary file in dir (os.TempDir if empty), encoded by ser.
+func NewSpillBuffer[T any](size int, dir string, ser Serializer[T], opts ...Option) (*SpillBuffer[T], error) {
+	rb, err := NewRingBuffer(size, opts...)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.CreateTemp(dir, "ringbuffer-spill-*")
+	if err != nil {
+		return nil, err
+	}
+	s := &SpillBuffer[T]{
+		rb:    rb,
+		slots: make([]T, rb.Slots()),
+		ends:  make([]int64, rb.Slots()),
+		ser:   ser,
+		file:  f,
+	}
+	if err := s.reset(); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+	return s, nil
+}
+
+// Put publishes v. It never waits for the ring: records go to disk while
+// the ring is full or older records are still on disk.
+// Put calls are serialized. It returns ErrClosed after Close.
+// It is goroutine-safe.
+func (s *SpillBuffer[T]) Put(v T) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed.Load() != 0 {
+		return ErrClosed
+	}
+	if s.spilled == 0 {
+		if id, ok := s.rb.tryReserveWrite(); ok {
+			s.slots[s.rb.BufferIndex(id)] = v
+			s.rb.CommitWrite(-1, id)
+			return nil
+		}
+	}
+	b, err := s.ser.Marshal(s.buf[:0], &v)
+	if err != nil {
+		return err
+	}
+	s.buf = b
+	binary.LittleEndian.PutUint32(s.hdr[:4], uint32(len(b)))
+	if _, err := s.file.WriteAt(s.hdr[:4], s.wOff); err != nil {
+		return err
+	}
+	if _, err := s.file.WriteAt(b, s.wOff+4); err != nil {
+		return err
+	}
+	s.wOff += 4 + int64(len(b))
+	s.spilled++
+	return nil
+}
+
+// Get waits for the next record. It returns ErrClosed once the SpillBuffer
+// is closed and every record, in memory or on disk, is drained; other
+// errors come from moving spilled records back and leave the returned
+// record valid.
+// It is goroutine-safe.
+func (s *SpillBuffer[T]) Get(wid int) (T, error) {
+	var zero T
+	for try := 1; ; try++ {
+		//load closed first: no Put can publish after it reads set
+		closed := s.closed.Load() != 0
+		if id, ok := s.rb.tryReserveRead(wid); ok {
+			idx := s.rb.BufferIndex(id)
+			v, end := s.slots[idx], s.ends[idx]
+			s.slots[idx], s.ends[idx] = zero, 0
+			s.rb.CommitRead(wid, id)
+			if end != 0 {
+				if err := s.consumed(end); err != nil {
+					return v, err
+				}
+			}
+			return v, s.refill()
+		}
+		if closed {
+			if s.Spilled() == 0 {
+				return zero, ErrClosed
+			}
+			if err := s.refill(); err != nil {
+				return zero, err
+			}
+			continue
+		}
+		if s.rb.readStrategy().Spin(context.Background(), try, s.rb.yield) {
+			continue
+		}
+		r := s.rb.rReserve.Load()
+		s.rb.waitReadR.wait(r+1, func() bool {
+			return s.closed.Load() != 0 || s.rb.canRead(s.rb.rReserve.Load())
+		})
+	}
+}
+
+// refill moves spilled records back into free slots, and removes the
+// spill file of a closed SpillBuffer once all of them are.
+func (s *SpillBuffer[T]) refill() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	for s.spilled > 0 {
+		id, ok := s.rb.tryReserveWrite()
+		if !ok {
+			return nil
+		}
+		if err := s.load(id); err != nil {
+			s.rb.AbortWrite(-1, id)
+			return err
+		}
+		s.spilled--
+		s.rb.CommitWrite(-1, id)
+	}
+	if s.closed.Load() != 0 {
+		return s.remove()
+	}
+	return nil
+}
+
+// consumed persists that the records up to end were taken by Get, and
+// starts the file over once every record on it was.
+func (s *SpillBuffer[T]) consumed(end int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loaded--
+	if s.file == nil || s.closed.Load() != 0 {
+		return nil
+	}
+	if s.loaded == 0 && s.spilled == 0 {
+		return s.reset()
+	}
+	if end <= s.cOff { //taken out of order by concurrent Get calls
+		return nil
+	}
+	s.cOff = end
+	binary.LittleEndian.PutUint64(s.hdr[:], uint64(s.cOff))
+	_, err := s.file.WriteAt(s.hdr[:], 0)
+	return err
+}
+
+// reset empties the spill file.
+func (s *SpillBuffer[T]) reset() error {
+	s.rOff, s.wOff, s.cOff = spillHeader, spillHeader, spillHeader
+	if err := s.file.Truncate(0); err != nil {
+		return err
+	}
+	binary.LittleEndian.PutUint64(s.hdr[:], spillHeader)
+	_, err := s.file.WriteAt(s.hdr[:], 0)
+	return err
+}
+
+// load decodes the next spilled record into the slot of id.
+func (s *SpillBuffer[T]) load(id uint64) error {
+	if _, err := s.file.ReadAt(s.hdr[:4], s.rOff); err != nil {
+		return err
+	}
+	n := int(binary.LittleEndian.Uint32(s.hdr[:4]))
+	if cap(s.buf) < n {
+		s.buf = make([]byte, n)
+	}
+	s.buf = s.buf[:n]
+	if _, err := s.file.ReadAt(s.buf, s.rOff+4); err != nil {
+		return err
+	}
+	idx := s.rb.BufferIndex(id)
+	if err := s.ser.Unmarshal(s.buf, &s.slots[idx]); err != nil {
+		return err
+	}
+	s.rOff += 4 + int64(n)
+	s.ends[idx] = s.rOff
+	s.loaded++
+	return nil
+}
+
+// Spilled returns the number of records waiting on disk.
+// It is goroutine-safe.
+func (s *SpillBuffer[T]) Spilled() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.spilled
+}
+
+// Close stops Put: Get returns the records left in memory and on disk,
+// then ErrClosed. The spill file is removed once Get has moved every
+// record on it back, right away if there are none. The spill file of a
+// SpillBuffer that is never closed, e.g. after a crash, can be recovered
+// with ReplaySpills.
+// It is goroutine-safe.
+func (s *SpillBuffer[T]) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed.Load() != 0 {
+		return ErrClosed
+	}
+	s.closed.Store(1)
+	s.rb.waitReadR.wakeAll()
+	if s.spilled == 0 {
+		return s.remove()
+	}
+	return nil
+}
+
+// remove closes and removes the spill file.
+func (s *SpillBuffer[T]) remove() error {
+	name := s.file.Name()
+	err := s.file.Close()
+	s.file = nil
+	if err != nil {
+		return err
+	}
+	return os.Remove(name)
+}
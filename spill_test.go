@@ -0,0 +1,146 @@
+package ringbuffer
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// TestSpillBufferOrder puts far more records than the ring holds without a
+// reader, then reads them back in order from memory and disk.
+func TestSpillBufferOrder(t *testing.T) {
+	defer parallel()()
+	const n = 100
+	s, err := NewSpillBuffer[string](4, t.TempDir(), StringSerializer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	for i := 0; i < n; i++ {
+		if err := s.Put(strconv.Itoa(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := s.Spilled(); got != n-4 {
+		t.Fatalf("Spilled() = %d, want %d", got, n-4)
+	}
+	for i := 0; i < n; i++ {
+		v, err := s.Get(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != strconv.Itoa(i) {
+			t.Fatalf("got %q, want %d", v, i)
+		}
+	}
+	if got := s.Spilled(); got != 0 {
+		t.Fatalf("Spilled() = %d after draining", got)
+	}
+}
+
+func TestSpillBufferConcurrent(t *testing.T) {
+	defer parallel()()
+	const writers, n = 4, 500
+	s, err := NewSpillBuffer[string](8, t.TempDir(), StringSerializer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	var wg sync.WaitGroup
+	wg.Add(writers)
+	for w := 0; w < writers; w++ {
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				if err := s.Put(strconv.Itoa(w*n + i)); err != nil {
+					t.Error(err)
+				}
+			}
+		}(w)
+	}
+	next := make([]int, writers)
+	for i := 0; i < writers*n; i++ {
+		v, err := s.Get(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		k, _ := strconv.Atoi(v)
+		w := k / n
+		if k%n != next[w] {
+			t.Fatalf("writer %d: got record %d, want %d", w, k%n, next[w])
+		}
+		next[w]++
+	}
+	wg.Wait()
+}
+
+func TestSpillBufferClose(t *testing.T) {
+	defer parallel()()
+	s, err := NewSpillBuffer[string](2, t.TempDir(), StringSerializer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{"a", "b", "c"} {
+		s.Put(v)
+	}
+	name := s.file.Name()
+	got := make(chan error)
+	go func() {
+		for {
+			if _, err := s.Get(0); err != nil {
+				got <- err
+				return
+			}
+		}
+	}()
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-got; !errors.Is(err, ErrClosed) {
+		t.Fatalf("Get after Close: %v", err)
+	}
+	if err := s.Put("d"); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Put after Close: %v", err)
+	}
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Fatalf("spill file left after Close: %v", err)
+	}
+}
+
+// TestSpillBufferCloseDrains closes with records on disk: Get still
+// returns all of them in order, then ErrClosed.
+func TestSpillBufferCloseDrains(t *testing.T) {
+	defer parallel()()
+	s, err := NewSpillBuffer[string](2, t.TempDir(), StringSerializer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const n = 10
+	for i := 0; i < n; i++ {
+		s.Put(strconv.Itoa(i))
+	}
+	name := s.file.Name()
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(name); err != nil {
+		t.Fatalf("spill file removed with %d records on it: %v", s.Spilled(), err)
+	}
+	for i := 0; i < n; i++ {
+		v, err := s.Get(0)
+		if err != nil {
+			t.Fatalf("record %d: %v", i, err)
+		}
+		if v != strconv.Itoa(i) {
+			t.Fatalf("got %q, want %d", v, i)
+		}
+	}
+	if _, err := s.Get(0); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Get after draining: %v", err)
+	}
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Fatalf("spill file left after draining: %v", err)
+	}
+}
@@ -0,0 +1,228 @@
+package ringbuffer
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync/atomic"
+
+	"ringbuffer/internal/prefetch"
+)
+
+// SPSCRingBuffer is a ring for exactly one producer goroutine and one
+// consumer goroutine, e.g. an audio pipeline stage. It has the Reserve and
+// Commit methods of RingBuffer, but no CAS loop and no cond broadcast: each
+// side owns its cursors, publishes them with a plain atomic store, and keeps
+// a cached copy of the other side's cursor, reloaded only when the cached
+// one says the ring is full (or empty).
+// wid arguments are ignored.
+type SPSCRingBuffer struct {
+	index    *RingBuffer // options and BufferIndex only, readonly
+	size     int
+	strategy WaitStrategy
+	yield    func()
+	drain    bool
+	policy   MisusePolicy
+	closed   atomic.Bool
+
+	_         [prefetch.CacheLine]byte
+	wReserve  uint64        // producer only
+	readCache uint64        // producer's copy of rCommit
+	wCommit   atomic.Uint64 // published by the producer
+	wParked   atomic.Bool   // producer waits for a read commit
+	wWake     chan struct{}
+
+	_          [prefetch.CacheLine]byte
+	rReserve   uint64        // consumer only
+	writeCache uint64        // consumer's copy of wCommit
+	rCommit    atomic.Uint64 // published by the consumer
+	rParked    atomic.Bool   // consumer waits for a write commit
+	rWake      chan struct{}
+}
+
+// NewSPSCRingBuffer returns a single producer, single consumer ring of size
+// slots. Of the options, only WithWaitStrategy, WithYield, WithDrainOnClose
+// and WithMisusePolicy apply. It returns ErrInvalidSize for a size out of
+// [1, MaxSize].
+func NewSPSCRingBuffer(size int, opts ...Option) (*SPSCRingBuffer, error) {
+	cfg := &RingBuffer{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if size <= 0 || size > MaxSize {
+		return nil, fmt.Errorf("%w %d", ErrInvalidSize, size)
+	}
+	cfg.size = size
+	cfg.initIndex()
+	rb := &SPSCRingBuffer{
+		index:    cfg,
+		size:     size,
+		strategy: BlockingWaitStrategy{},
+		yield:    cfg.yield,
+		drain:    cfg.drainOnClose,
+		policy:   cfg.misusePolicy,
+		wWake:    make(chan struct{}, 1),
+		rWake:    make(chan struct{}, 1),
+	}
+	if b, ok := cfg.waitStrategy.Load().(strategyBox); ok {
+		rb.strategy = b.ws
+	}
+	if rb.yield == nil {
+		rb.yield = runtime.Gosched
+	}
+	return rb, nil
+}
+
+// MustNewSPSCRingBuffer is NewSPSCRingBuffer panicking on error.
+func MustNewSPSCRingBuffer(size int, opts ...Option) *SPSCRingBuffer {
+	rb, err := NewSPSCRingBuffer(size, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return rb
+}
+
+// Size return size of ringbuffer, the items it holds at most.
+func (rb *SPSCRingBuffer) Size() int {
+	return rb.size
+}
+
+// Slots returns the number of slots of the buffer, see RingBuffer.Slots.
+func (rb *SPSCRingBuffer) Slots() int {
+	return rb.index.slots
+}
+
+// BufferIndex returns logic index of buffer by id
+func (rb *SPSCRingBuffer) BufferIndex(id uint64) int {
+	return rb.index.BufferIndex(id)
+}
+
+// ReserveWrite returns next avable id for write.
+// It will wait if ringbuffer is full, and returns ErrClosed once the ring
+// is closed. It must only be called by the producer goroutine.
+func (rb *SPSCRingBuffer) ReserveWrite(wid int) (uint64, error) {
+	id := rb.wReserve
+	for try := 1; id >= rb.readCache+uint64(rb.size); try++ {
+		if rb.closed.Load() {
+			return 0, ErrClosed
+		}
+		if rb.readCache = rb.rCommit.Load(); id < rb.readCache+uint64(rb.size) {
+			break
+		}
+		if rb.strategy.Spin(context.Background(), try, rb.yield) {
+			continue
+		}
+		rb.park(&rb.wParked, rb.wWake, func() bool {
+			return id < rb.rCommit.Load()+uint64(rb.size) || rb.closed.Load()
+		})
+	}
+	if rb.closed.Load() {
+		return 0, ErrClosed
+	}
+	rb.wReserve++
+	return id, nil
+}
+
+// CommitWrite publishes write id. Ids must be committed in reservation
+// order. It must only be called by the producer goroutine.
+func (rb *SPSCRingBuffer) CommitWrite(wid int, id uint64) error {
+	if err := rb.checkCommit(id, rb.wReserve, &rb.wCommit); err != nil {
+		return err
+	}
+	rb.wCommit.Store(id + 1)
+	rb.unpark(&rb.rParked, rb.rWake)
+	return nil
+}
+
+// ReserveRead returns next avable id for read.
+// It will wait if ringbuffer is empty. It returns ErrClosed once the ring
+// is closed, after draining it under WithDrainOnClose. It must only be
+// called by the consumer goroutine.
+func (rb *SPSCRingBuffer) ReserveRead(wid int) (uint64, error) {
+	id := rb.rReserve
+	for try := 1; id >= rb.writeCache; try++ {
+		closed := rb.closed.Load() //before the reload: no commit follows it
+		if closed && !rb.drain {
+			return 0, ErrClosed
+		}
+		if rb.writeCache = rb.wCommit.Load(); id < rb.writeCache {
+			break
+		}
+		if closed {
+			return 0, ErrClosed
+		}
+		if rb.strategy.Spin(context.Background(), try, rb.yield) {
+			continue
+		}
+		rb.park(&rb.rParked, rb.rWake, func() bool {
+			return id < rb.wCommit.Load() || rb.closed.Load()
+		})
+	}
+	if rb.closed.Load() && !rb.drain {
+		return 0, ErrClosed
+	}
+	rb.rReserve++
+	return id, nil
+}
+
+// CommitRead releases read id to the producer. Ids must be committed in
+// reservation order. It must only be called by the consumer goroutine.
+func (rb *SPSCRingBuffer) CommitRead(wid int, id uint64) error {
+	if err := rb.checkCommit(id, rb.rReserve, &rb.rCommit); err != nil {
+		return err
+	}
+	rb.rCommit.Store(id + 1)
+	rb.unpark(&rb.wParked, rb.wWake)
+	return nil
+}
+
+// Close signals end of stream like RingBuffer.Close.
+// It is goroutine-safe.
+func (rb *SPSCRingBuffer) Close() error {
+	if !rb.closed.CompareAndSwap(false, true) {
+		return ErrClosed
+	}
+	rb.unpark(&rb.wParked, rb.wWake)
+	rb.unpark(&rb.rParked, rb.rWake)
+	return nil
+}
+
+// checkCommit validates that id is the next id to commit of one side.
+func (rb *SPSCRingBuffer) checkCommit(id, reserve uint64, commit *atomic.Uint64) error {
+	var err error
+	switch c := commit.Load(); {
+	case id >= reserve:
+		err = fmt.Errorf("%w %d", ErrNotReserved, id)
+	case id < c:
+		err = fmt.Errorf("%w %d", ErrCommitted, id)
+	case id > c:
+		err = fmt.Errorf("RingBuffer: commit of %d out of order, next is %d", id, c)
+	}
+	if err != nil && rb.policy == MisusePanic {
+		panic(err)
+	}
+	return err
+}
+
+// park blocks the calling side until unpark, unless ready.
+// The flag is raised before ready is checked, so the other side either
+// sees it and wakes us, or made ready true first; a stale token left in
+// wake only causes one more check.
+func (rb *SPSCRingBuffer) park(parked *atomic.Bool, wake chan struct{}, ready func() bool) {
+	parked.Store(true)
+	if !ready() {
+		<-wake
+	}
+	parked.Store(false)
+}
+
+// unpark wakes the other side if it is parked.
+func (rb *SPSCRingBuffer) unpark(parked *atomic.Bool, wake chan struct{}) {
+	if !parked.Load() {
+		return
+	}
+	select {
+	case wake <- struct{}{}:
+	default:
+	}
+}
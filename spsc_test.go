@@ -0,0 +1,96 @@
+package ringbuffer
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSPSCOrder(t *testing.T) {
+	defer parallel()()
+	for _, ws := range []WaitStrategy{BlockingWaitStrategy{}, YieldingWaitStrategy{Spins: 10}} {
+		for _, size := range []int{1, 3, 64} {
+			const n = 20000
+			rb := MustNewSPSCRingBuffer(size, WithWaitStrategy(ws))
+			slots := make([]int, rb.Slots())
+			go func() {
+				for i := 0; i < n; i++ {
+					id, _ := rb.ReserveWrite(0)
+					slots[rb.BufferIndex(id)] = i
+					rb.CommitWrite(0, id)
+				}
+			}()
+			for i := 0; i < n; i++ {
+				id, err := rb.ReserveRead(0)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if v := slots[rb.BufferIndex(id)]; v != i {
+					t.Fatalf("size %d %T: got %d, want %d", size, ws, v, i)
+				}
+				rb.CommitRead(0, id)
+			}
+		}
+	}
+}
+
+func TestSPSCClose(t *testing.T) {
+	defer parallel()()
+	for _, drain := range []bool{false, true} {
+		var opts []Option
+		if drain {
+			opts = append(opts, WithDrainOnClose())
+		}
+		rb := MustNewSPSCRingBuffer(4, opts...)
+		id, _ := rb.ReserveWrite(0)
+		rb.CommitWrite(0, id)
+		rb.Close()
+		if _, err := rb.ReserveWrite(0); !errors.Is(err, ErrClosed) {
+			t.Fatalf("drain %v: ReserveWrite after Close: %v", drain, err)
+		}
+		if drain {
+			got, err := rb.ReserveRead(0)
+			if err != nil || got != id {
+				t.Fatalf("drain: ReserveRead() = %d, %v, want %d", got, err, id)
+			}
+			rb.CommitRead(0, got)
+		}
+		if _, err := rb.ReserveRead(0); !errors.Is(err, ErrClosed) {
+			t.Fatalf("drain %v: ReserveRead of a drained ring: %v", drain, err)
+		}
+	}
+}
+
+// TestSPSCCloseWakes closes a ring under a parked consumer.
+func TestSPSCCloseWakes(t *testing.T) {
+	defer parallel()()
+	rb := MustNewSPSCRingBuffer(2)
+	done := make(chan error)
+	go func() {
+		_, err := rb.ReserveRead(0)
+		done <- err
+	}()
+	rb.Close()
+	if err := <-done; !errors.Is(err, ErrClosed) {
+		t.Fatalf("parked ReserveRead at Close: %v", err)
+	}
+}
+
+func TestSPSCMisuse(t *testing.T) {
+	defer parallel()()
+	rb := MustNewSPSCRingBuffer(4)
+	a, _ := rb.ReserveWrite(0)
+	b, _ := rb.ReserveWrite(0)
+	if err := rb.CommitWrite(0, b); err == nil {
+		t.Fatalf("CommitWrite of %d before %d accepted", b, a)
+	}
+	if err := rb.CommitWrite(0, b+1); !errors.Is(err, ErrNotReserved) {
+		t.Fatalf("CommitWrite of unreserved id: %v", err)
+	}
+	rb.CommitWrite(0, a)
+	if err := rb.CommitWrite(0, a); !errors.Is(err, ErrCommitted) {
+		t.Fatalf("second CommitWrite: %v", err)
+	}
+	if _, err := NewSPSCRingBuffer(0); !errors.Is(err, ErrInvalidSize) {
+		t.Fatalf("size 0: %v", err)
+	}
+}
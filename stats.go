@@ -0,0 +1,86 @@
+package ringbuffer
+
+// Stats is a snapshot of the counters of a ring.
+// Fields are loaded one by one, so they may be slightly inconsistent with
+// each other on a busy ring.
+type Stats struct {
+	Name         string
+	Labels       map[string]string
+	Size         int
+	ReadReserve  uint64
+	ReadCommit   uint64 // also the total of items read
+	WriteReserve uint64
+	WriteCommit  uint64 // also the total of items written
+
+	// Occupancy is items published and not consumed yet.
+	Occupancy uint64
+
+	// OutstandingWrites is write reservations ahead of the read commit,
+	// at most Size when ReservationCap is set.
+	OutstandingWrites uint64
+	ReservationCap    bool
+
+	// Payload accounting of CommitWriteBytes/CommitReadBytes.
+	BytesWritten  uint64
+	BytesRead     uint64
+	BytesInFlight int64 // published but not consumed
+
+	// SLOBreaches is reserve waits longer than the WithSLO threshold.
+	SLOBreaches uint64
+	// Shed is publishes refused by the WithAdmissionPolicy policy.
+	Shed uint64
+	// Overwritten is entries dropped unread under WithOverwrite.
+	Overwritten uint64
+	// Fallbacks is items handed to the fallback of PutOr.
+	Fallbacks uint64
+	// Tombstones is aborted writes and barriers not skipped by readers yet.
+	Tombstones int
+
+	// Waits of the calls that could not go on at once, per phase.
+	// CommitWrite never waits.
+	ReserveWriteWaits WaitStats
+	ReserveReadWaits  WaitStats
+	CommitReadWaits   WaitStats
+	// Runtime is sampled runtime metrics, nil unless WithRuntimeStats.
+	Runtime *RuntimeStats
+}
+
+// Stats returns a snapshot of ring counters.
+// It is goroutine-safe.
+func (rb *RingBuffer) Stats() Stats {
+	bytesRead := rb.bytesRead.Load() // before written, keeps in flight >= 0
+	s := Stats{
+		Name:         rb.name,
+		Labels:       rb.Labels(),
+		Size:         rb.size,
+		ReadReserve:  rb.rReserve.Load(),
+		ReadCommit:   rb.rCommit.Load(),
+		WriteReserve: rb.wReserve.Load(),
+		WriteCommit:  rb.wCommit.Load(),
+		BytesWritten: rb.bytesWritten.Load(),
+		BytesRead:    bytesRead,
+	}
+	s.BytesInFlight = int64(s.BytesWritten - s.BytesRead)
+	s.ReservationCap = rb.reserveCap
+	s.SLOBreaches = rb.SLOBreaches()
+	s.Shed = rb.shed.Load()
+	s.ReserveWriteWaits = rb.waits.reserveWrite.load()
+	s.ReserveReadWaits = rb.waits.reserveRead.load()
+	s.CommitReadWaits = rb.waits.commitRead.load()
+	s.Overwritten = rb.overwritten.Load()
+	s.Fallbacks = rb.fallbacks.Load()
+	if rb.tombs.count.Load() > 0 {
+		s.Tombstones = rb.Tombstones()
+	}
+	if rb.runtimeStats != nil {
+		s.Runtime = &RuntimeStats{}
+		rb.runtimeStats.read(s.Runtime)
+	}
+	if s.WriteCommit > s.ReadCommit {
+		s.Occupancy = s.WriteCommit - s.ReadCommit
+	}
+	if s.WriteReserve > s.ReadCommit {
+		s.OutstandingWrites = s.WriteReserve - s.ReadCommit
+	}
+	return s
+}
@@ -0,0 +1,162 @@
+// Package taskq is a worker task queue built on ringbuffer.
+//
+// Tasks are submitted into a ring and run by a fixed pool of workers.
+// A panicking task is recovered and counted, it doesn't take its worker
+// down. Shutdown stops accepting tasks and waits for queued ones to finish.
+package taskq
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"ringbuffer"
+)
+
+// ErrClosed is returned by Submit after Shutdown.
+var ErrClosed = errors.New("taskq: queue closed")
+
+// Queue is a task queue served by a pool of workers.
+type Queue struct {
+	rb      *ringbuffer.RingBuffer
+	tasks   []func()
+	workers int
+	onPanic func(v any)
+	wg      sync.WaitGroup
+
+	submitted atomic.Uint64
+	completed atomic.Uint64
+	panicked  atomic.Uint64
+}
+
+// Option configures a Queue.
+type Option func(*Queue)
+
+// WithPanicHandler calls fn with the value recovered from a panicking task.
+func WithPanicHandler(fn func(v any)) Option {
+	return func(q *Queue) {
+		q.onPanic = fn
+	}
+}
+
+// Stats is a snapshot of Queue counters.
+type Stats struct {
+	Submitted uint64
+	Completed uint64 // including panicked tasks
+	Panicked  uint64
+	Pending   uint64 // submitted but not completed
+}
+
+// New starts a Queue holding up to size pending tasks, served by workers
+// goroutines. It fails where ringbuffer.NewRingBuffer does.
+func New(size, workers int, opts ...Option) (*Queue, error) {
+	rb, err := ringbuffer.NewRingBuffer(size, ringbuffer.WithName("taskq"), ringbuffer.WithDrainOnClose())
+	if err != nil {
+		return nil, err
+	}
+	q := &Queue{
+		rb:      rb,
+		workers: workers,
+	}
+	q.tasks = make([]func(), q.rb.Slots())
+	for _, opt := range opts {
+		opt(q)
+	}
+	q.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		i := i
+		q.rb.Go("worker", i, func() { q.work(i) })
+	}
+	return q, nil
+}
+
+// MustNew is New panicking on error.
+func MustNew(size, workers int, opts ...Option) *Queue {
+	q, err := New(size, workers, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+// Submit queues task to run on a worker.
+// It waits while the queue is full, and returns ErrClosed after Shutdown.
+// It is goroutine-safe.
+func (q *Queue) Submit(task func()) error {
+	if task == nil {
+		return nil
+	}
+	id, err := q.rb.ReserveWrite(-1)
+	if err != nil {
+		if errors.Is(err, ringbuffer.ErrClosed) {
+			return ErrClosed
+		}
+		return err
+	}
+	q.tasks[q.rb.BufferIndex(id)] = task
+	q.submitted.Add(1)
+	return q.rb.CommitWrite(-1, id)
+}
+
+// Shutdown stops accepting tasks and waits until every queued task has run,
+// or until ctx is done. Submit calls waiting for room fail with ErrClosed.
+// It is goroutine-safe and may be called more than once.
+func (q *Queue) Shutdown(ctx context.Context) error {
+	q.rb.Close() //workers drain the queued tasks, then stop
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stats returns a snapshot of Queue counters.
+// It is goroutine-safe.
+func (q *Queue) Stats() Stats {
+	s := Stats{
+		Completed: q.completed.Load(),
+		Panicked:  q.panicked.Load(),
+		Submitted: q.submitted.Load(),
+	}
+	if s.Submitted > s.Completed {
+		s.Pending = s.Submitted - s.Completed
+	}
+	return s
+}
+
+func (q *Queue) work(wid int) {
+	defer q.wg.Done()
+	for {
+		id, err := q.rb.ReserveRead(wid)
+		if err != nil {
+			return
+		}
+		idx := q.rb.BufferIndex(id)
+		task := q.tasks[idx]
+		q.tasks[idx] = nil
+		q.rb.CommitRead(wid, id)
+		q.run(task)
+		q.completed.Add(1)
+	}
+}
+
+// run runs task, recovering a panic.
+func (q *Queue) run(task func()) {
+	defer func() {
+		if v := recover(); v != nil {
+			q.panicked.Add(1)
+			if q.onPanic != nil {
+				q.onPanic(v)
+			}
+		}
+	}()
+	task()
+}
@@ -0,0 +1,91 @@
+package taskq
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// parallel sets GOMAXPROCS high enough for a RingBuffer and returns a func
+// restoring it.
+func parallel() func() {
+	old := runtime.GOMAXPROCS(4)
+	return func() { runtime.GOMAXPROCS(old) }
+}
+
+func TestQueueRunsEveryTask(t *testing.T) {
+	defer parallel()()
+	const submitters, n = 4, 500
+	var panics atomic.Int32
+	q := MustNew(8, 3, WithPanicHandler(func(v any) { panics.Add(1) }))
+	var ran atomic.Int32
+	var wg sync.WaitGroup
+	wg.Add(submitters)
+	for i := 0; i < submitters; i++ {
+		go func() {
+			defer wg.Done()
+			for j := 0; j < n; j++ {
+				j := j
+				if err := q.Submit(func() {
+					ran.Add(1)
+					if j%100 == 0 {
+						panic("task")
+					}
+				}); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if err := q.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	s := q.Stats()
+	if ran.Load() != submitters*n || s.Completed != submitters*n || s.Pending != 0 {
+		t.Fatalf("ran %d, stats %+v", ran.Load(), s)
+	}
+	if s.Panicked != submitters*n/100 || panics.Load() != submitters*n/100 {
+		t.Fatalf("%d panics handled, stats %+v", panics.Load(), s)
+	}
+	if err := q.Submit(func() {}); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Submit after Shutdown: %v", err)
+	}
+	if err := q.Shutdown(context.Background()); err != nil {
+		t.Fatalf("second Shutdown: %v", err)
+	}
+}
+
+// TestShutdownStuck pins that Shutdown of a full queue whose workers are
+// stuck honors ctx, and fails the Submit calls waiting for room.
+func TestShutdownStuck(t *testing.T) {
+	defer parallel()()
+	release := make(chan struct{})
+	q := MustNew(2, 1)
+	for i := 0; i < 3; i++ { //one running, two queued
+		q.Submit(func() { <-release })
+	}
+	waiting := make(chan error)
+	go func() { waiting <- q.Submit(func() {}) }()
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := q.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown of a stuck queue: %v", err)
+	}
+	if err := <-waiting; !errors.Is(err, ErrClosed) {
+		t.Fatalf("Submit waiting at Shutdown: %v", err)
+	}
+	close(release)
+	if err := q.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if s := q.Stats(); s.Completed != 3 {
+		t.Fatalf("%d tasks completed, want the 3 queued before Shutdown", s.Completed)
+	}
+}
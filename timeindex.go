@@ -0,0 +1,115 @@
+package ringbuffer
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// timeIndex is a sparse map from sequences to publish times.
+// One sample is kept every `every` sequences, the newest `capacity` samples
+// are retained.
+type timeIndex struct {
+	mu      sync.Mutex
+	every   uint64
+	samples []timeSample // circular, ordered by seq from head
+	head    int
+	count   int
+}
+
+type timeSample struct {
+	seq  uint64
+	nano int64
+}
+
+// WithTimeIndex records the publish time of every every-th sequence, keeping
+// the newest capacity samples, so that SeekToTime can work.
+func WithTimeIndex(every uint64, capacity int) Option {
+	return func(rb *RingBuffer) {
+		if every == 0 || capacity <= 0 {
+			return
+		}
+		rb.timeIndex = &timeIndex{
+			every:   every,
+			samples: make([]timeSample, capacity),
+		}
+	}
+}
+
+// recordRange records the sampled ids of [lo, hi).
+func (x *timeIndex) recordRange(lo, hi uint64) {
+	first := (lo + x.every - 1) / x.every * x.every
+	for id := first; id < hi; id += x.every {
+		x.record(id)
+	}
+}
+
+func (x *timeIndex) record(id uint64) {
+	x.mu.Lock()
+	s := timeSample{seq: id, nano: time.Now().UnixNano()}
+	if x.count < len(x.samples) {
+		x.samples[(x.head+x.count)%len(x.samples)] = s
+		x.count++
+	} else {
+		x.samples[x.head] = s
+		x.head = (x.head + 1) % len(x.samples)
+	}
+	x.mu.Unlock()
+}
+
+// skip moves the samples of marker ids to the next published id that is
+// not a marker.
+func (x *timeIndex) skip(dead map[uint64]marker, published uint64) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	for i := 0; i < x.count; i++ {
+		s := &x.samples[(x.head+i)%len(x.samples)]
+		for s.seq+1 < published {
+			if _, ok := dead[s.seq]; !ok {
+				break
+			}
+			s.seq++
+		}
+	}
+}
+
+func (x *timeIndex) at(i int) timeSample {
+	return x.samples[(x.head+i)%len(x.samples)]
+}
+
+// seek returns the lowest sequence that may be published at or after t.
+func (x *timeIndex) seek(t time.Time, next uint64) uint64 {
+	nano := t.UnixNano()
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if x.count == 0 {
+		return next
+	}
+	i := sort.Search(x.count, func(i int) bool { return x.at(i).nano >= nano })
+	switch {
+	case i == 0:
+		return x.at(0).seq
+	case i == x.count:
+		last := x.at(x.count - 1)
+		if last.seq+1 > next {
+			return next
+		}
+		return last.seq + 1
+	}
+	return x.at(i-1).seq + 1
+}
+
+// SeekToTime returns the lowest sequence that may have been published at or
+// after t, so a late reader can start from "events since t".
+// The answer is accurate to the index granularity: a few earlier sequences
+// may be included, none later is skipped. Before the oldest sample it
+// returns the oldest sampled sequence, whose slot may already be reused.
+// Without WithTimeIndex it returns the next sequence to be published.
+// It is goroutine-safe.
+func (rb *RingBuffer) SeekToTime(t time.Time) uint64 {
+	next := rb.wCommit.Load()
+	if rb.timeIndex == nil {
+		return next
+	}
+	return rb.timeIndex.seek(t, next)
+}
@@ -0,0 +1,101 @@
+package ringbuffer
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSeekToTime(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(64, WithTimeIndex(1, 64))
+	publish := func(n int) {
+		for i := 0; i < n; i++ {
+			id, _ := rb.ReserveWrite(0)
+			rb.CommitWrite(0, id)
+		}
+	}
+	publish(10)
+	time.Sleep(time.Millisecond)
+	mid := time.Now()
+	time.Sleep(time.Millisecond)
+	publish(10)
+	if got := rb.SeekToTime(mid); got != 10 {
+		t.Fatalf("SeekToTime(mid) = %d, want 10", got)
+	}
+	if got := rb.SeekToTime(time.Time{}); got != 0 {
+		t.Fatalf("SeekToTime(zero) = %d, want 0", got)
+	}
+	if got := rb.SeekToTime(time.Now().Add(time.Hour)); got != 20 {
+		t.Fatalf("SeekToTime(future) = %d, want 20", got)
+	}
+	if got := MustNewRingBuffer(4).SeekToTime(mid); got != 0 {
+		t.Fatalf("SeekToTime without index = %d, want 0", got)
+	}
+}
+
+// TestSeekToTimeOldest pins that seeking before the retained samples
+// returns the oldest sample, and that sparse samples never skip an id
+// published after t.
+func TestSeekToTimeOldest(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(64, WithTimeIndex(4, 3))
+	var at []time.Time
+	for i := 0; i < 40; i++ {
+		at = append(at, time.Now())
+		id, _ := rb.ReserveWrite(0)
+		rb.CommitWrite(0, id)
+		time.Sleep(100 * time.Microsecond)
+	}
+	if got := rb.SeekToTime(time.Time{}); got != 28 {
+		t.Fatalf("SeekToTime(zero) = %d, want oldest sample 28", got)
+	}
+	for id := 28; id < 40; id++ {
+		if got := rb.SeekToTime(at[id]); got > uint64(id) {
+			t.Fatalf("SeekToTime before id %d = %d skips it", id, got)
+		}
+	}
+}
+
+func TestSeekToTimeConcurrent(t *testing.T) {
+	defer parallel()()
+	const writers, n = 4, 2000
+	rb := MustNewRingBuffer(16, WithTimeIndex(2, 32))
+	var wg sync.WaitGroup
+	wg.Add(writers + 1)
+	for w := 0; w < writers; w++ {
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				id, _ := rb.ReserveWrite(w)
+				rb.CommitWrite(w, id)
+			}
+		}(w)
+	}
+	go func() {
+		defer wg.Done()
+		for i := 0; i < writers*n; i++ {
+			id, _ := rb.ReserveRead(0)
+			rb.CommitRead(0, id)
+		}
+	}()
+	stop := make(chan struct{})
+	seeker := make(chan struct{})
+	go func() {
+		defer close(seeker)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if got, next := rb.SeekToTime(time.Now()), rb.Stats().WriteCommit; got > next {
+				t.Errorf("SeekToTime(now) = %d past the write commit %d", got, next)
+				return
+			}
+		}
+	}()
+	wg.Wait()
+	close(stop)
+	<-seeker
+}
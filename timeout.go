@@ -0,0 +1,70 @@
+package ringbuffer
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrTimeout reports a reserve or commit that gave up waiting.
+var ErrTimeout = errors.New("RingBuffer: timeout")
+
+// ReserveWriteTimeout is ReserveWrite giving up with ErrTimeout after d.
+// It tries once before looking at d, so a d of 0 or less reserves a free
+// slot without waiting.
+// It is goroutine-safe.
+func (rb *RingBuffer) ReserveWriteTimeout(wid int, d time.Duration) (uint64, error) {
+	if id, ok := rb.TryReserveWrite(wid); ok {
+		return id, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	id, err := rb.ReserveWriteContext(ctx, wid)
+	return id, timeoutErr(err)
+}
+
+// ReserveReadTimeout is ReserveRead giving up with ErrTimeout after d.
+// Like ReserveWriteTimeout, it tries once before looking at d.
+// It is goroutine-safe.
+func (rb *RingBuffer) ReserveReadTimeout(wid int, d time.Duration) (uint64, error) {
+	if id, ok := rb.TryReserveRead(wid); ok {
+		return id, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	id, err := rb.ReserveReadContext(ctx, wid)
+	return id, timeoutErr(err)
+}
+
+// CommitWriteTimeout is CommitWrite giving up with ErrTimeout after d,
+// leaving id reserved: the caller may try again or AbortWrite it.
+// CommitWrite never waits, so it doesn't time out.
+// It is goroutine-safe.
+func (rb *RingBuffer) CommitWriteTimeout(wid int, id uint64, d time.Duration) error {
+	if ok, err := rb.TryCommitWrite(wid, id); ok || err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	return timeoutErr(rb.CommitWriteContext(ctx, wid, id))
+}
+
+// CommitReadTimeout is CommitRead giving up with ErrTimeout after d,
+// leaving id reserved: the caller must try again. Like
+// ReserveWriteTimeout, it tries once before looking at d.
+// It is goroutine-safe.
+func (rb *RingBuffer) CommitReadTimeout(wid int, id uint64, d time.Duration) error {
+	if ok, err := rb.TryCommitRead(wid, id); ok || err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	return timeoutErr(rb.CommitReadContext(ctx, wid, id))
+}
+
+func timeoutErr(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrTimeout
+	}
+	return err
+}
@@ -0,0 +1,41 @@
+package ringbuffer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeoutZeroWithRoom(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(2)
+	id, err := rb.ReserveWriteTimeout(0, 0)
+	if err != nil {
+		t.Fatalf("ReserveWriteTimeout(0) on an empty ring: %v", err)
+	}
+	if err := rb.CommitWriteTimeout(0, id, 0); err != nil {
+		t.Fatalf("CommitWriteTimeout(0): %v", err)
+	}
+	id, err = rb.ReserveReadTimeout(1, 0)
+	if err != nil {
+		t.Fatalf("ReserveReadTimeout(0) with an item: %v", err)
+	}
+	if err := rb.CommitReadTimeout(1, id, 0); err != nil {
+		t.Fatalf("CommitReadTimeout(0): %v", err)
+	}
+}
+
+func TestTimeoutExpires(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(1)
+	if _, err := rb.ReserveReadTimeout(1, time.Millisecond); err != ErrTimeout {
+		t.Fatalf("ReserveReadTimeout on an empty ring: %v", err)
+	}
+	rb.ReserveWriteTimeout(0, 0)
+	start := time.Now()
+	if _, err := rb.ReserveWriteTimeout(0, 10*time.Millisecond); err != ErrTimeout {
+		t.Fatalf("ReserveWriteTimeout on a full ring: %v", err)
+	}
+	if waited := time.Since(start); waited < 10*time.Millisecond {
+		t.Fatalf("gave up after %v", waited)
+	}
+}
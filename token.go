@@ -0,0 +1,62 @@
+package ringbuffer
+
+import "context"
+
+// Token names a write on a ring, for causal ordering across rings: a
+// producer takes the Token of item A after committing it, and attaches it to
+// item B published on another ring. The consumer of B waits on the Token so
+// that it doesn't process B before A was consumed.
+// The zero Token depends on nothing.
+type Token struct {
+	rb *RingBuffer
+	id uint64
+}
+
+// Token returns the Token of write id.
+func (rb *RingBuffer) Token(id uint64) Token {
+	return Token{rb: rb, id: id}
+}
+
+// ID returns the write id of t.
+func (t Token) ID() uint64 {
+	return t.id
+}
+
+// Published reports whether the write of t is visible to the readers of its
+// ring. The zero Token is always published.
+// It is goroutine-safe.
+func (t Token) Published() bool {
+	return t.rb == nil || t.rb.canRead(t.id)
+}
+
+// Consumed reports whether every reader of the ring of t is past it, i.e.
+// its item and every earlier one were consumed and committed. The zero
+// Token is always consumed.
+// It is goroutine-safe.
+func (t Token) Consumed() bool {
+	return t.rb == nil || t.rb.PassedBarrier(t.id)
+}
+
+// Wait waits until t is consumed, or returns ctx.Err() once ctx is done, or
+// ErrClosed if the ring of t is closed first without drain.
+// It is goroutine-safe.
+func (t Token) Wait(ctx context.Context) error {
+	if t.Consumed() {
+		return nil
+	}
+	rb := t.rb
+	defer rb.watch(ctx, rb.waitBarrier)()
+	ready := func() bool {
+		return t.Consumed() || ctx.Err() != nil || rb.readersClosed()
+	}
+	for !t.Consumed() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if rb.readersClosed() {
+			return ErrClosed
+		}
+		rb.waitBarrier.wait(t.id+1, ready)
+	}
+	return nil
+}
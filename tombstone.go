@@ -0,0 +1,212 @@
+package ringbuffer
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+)
+
+// tombstones tracks aborted write ids and other marker ids readers skip.
+// An aborted id is committed as a tombstone as soon as every earlier id is
+// committed, so it never leaves a hole that blocks later commits; readers
+// skip it.
+type tombstones struct {
+	mu      sync.Mutex
+	aborted map[uint64]struct{} // aborted, waiting for earlier commits
+	dead    map[uint64]marker   // markers readers skip, not skipped yet
+	count   atomic.Int32        // len(aborted) + len(dead), hot path guard
+	low     atomic.Uint64       // no marker below it in dead, see sweep
+}
+
+// marker is the kind of an id that carries no payload.
+type marker uint8
+
+const (
+	markTombstone marker = iota // aborted write
+	markBarrier                 // checkpoint barrier
+)
+
+func (t *tombstones) init() {
+	if t.aborted == nil {
+		t.aborted = make(map[uint64]struct{})
+		t.dead = make(map[uint64]marker)
+	}
+}
+
+// AbortWrite gives up write reservation id without publishing anything.
+// The id is committed as a tombstone once all earlier ids are committed,
+// and readers skip it, so later writers are not blocked by it.
+// AbortWrite never waits, so it is safe to call from cancellation paths
+// while the slot of id is still in use.
+// Aborting an id that is not reserved or already committed is a misuse,
+// see WithMisusePolicy.
+// It is goroutine-safe.
+func (rb *RingBuffer) AbortWrite(wid int, id uint64) error {
+	if err := rb.checkCommit(id, &rb.wReserve, &rb.wCommit); err != nil {
+		return err
+	}
+	t := &rb.tombs
+	t.mu.Lock()
+	t.init()
+	t.aborted[id] = struct{}{}
+	t.count.Add(1)
+	t.mu.Unlock()
+	rb.advanceWrite()
+	return nil
+}
+
+// advanceTombstones commits aborted ids that are next in line, and reports
+// whether it committed any.
+func (rb *RingBuffer) advanceTombstones() (moved bool) {
+	t := &rb.tombs
+	for {
+		t.mu.Lock()
+		c := rb.wCommit.Load()
+		_, ok := t.aborted[c]
+		//only the owner of c may commit it, and it aborted
+		if !ok || !rb.wCommit.CompareAndSwap(c, c+1) {
+			t.mu.Unlock()
+			return
+		}
+		delete(t.aborted, c)
+		t.add(c, markTombstone)
+		t.mu.Unlock()
+		rb.published(c, c+1)
+		moved = true
+	}
+}
+
+// skipTombstone commits read id if it is a tombstone or another marker,
+// and reports whether it did so.
+func (rb *RingBuffer) skipTombstone(wid int, id uint64) bool {
+	m, ok := rb.tombs.take(id)
+	if !ok {
+		return false
+	}
+	if m == markBarrier && rb.onBarrier != nil {
+		rb.onBarrier(wid, id)
+	}
+	rb.CommitRead(wid, id)
+	return true
+}
+
+// take removes marker id, returning its kind, if it is one not skipped yet.
+func (t *tombstones) take(id uint64) (marker, bool) {
+	if t.count.Load() == 0 {
+		return 0, false
+	}
+	t.mu.Lock()
+	m, ok := t.dead[id]
+	if ok {
+		delete(t.dead, id)
+		t.count.Add(-1)
+	}
+	t.mu.Unlock()
+	return m, ok
+}
+
+// marker returns the kind of marker id, if it is one not skipped yet.
+func (t *tombstones) marker(id uint64) (marker, bool) {
+	t.mu.Lock()
+	m, ok := t.dead[id]
+	t.mu.Unlock()
+	return m, ok
+}
+
+// before returns the first marker id in [lo, hi), or hi if there is none.
+func (t *tombstones) before(lo, hi uint64) uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if uint64(len(t.dead)) < hi-lo {
+		for id := range t.dead {
+			if id >= lo && id < hi {
+				hi = id
+			}
+		}
+		return hi
+	}
+	for id := lo; id < hi; id++ {
+		if _, ok := t.dead[id]; ok {
+			return id
+		}
+	}
+	return hi
+}
+
+// CompactTombstones drops the bookkeeping of markers no reader will reach:
+// those below the read commit, and every marker of a ring closed without
+// drain, e.g. ids aborted by writers after Close. It also moves time index
+// samples that point at a marker to the next id, so readers starting from
+// SeekToTime don't land on tombstones. It returns the markers dropped.
+// It is goroutine-safe.
+func (rb *RingBuffer) CompactTombstones() int {
+	t := &rb.tombs
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	all := rb.readersClosed()
+	floor := rb.readCommit()
+	dropped := 0
+	for id := range t.dead {
+		if all || id < floor {
+			delete(t.dead, id)
+			dropped++
+		}
+	}
+	t.count.Add(-int32(dropped))
+	if rb.timeIndex != nil && len(t.dead) > 0 {
+		rb.timeIndex.skip(t.dead, rb.wCommit.Load())
+	}
+	return dropped
+}
+
+// Tombstones returns the markers, aborted writes and barriers, not skipped
+// by readers yet.
+// It is goroutine-safe.
+func (rb *RingBuffer) Tombstones() int {
+	t := &rb.tombs
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.dead)
+}
+
+// mark records id as a marker of kind m before it is committed.
+func (rb *RingBuffer) mark(id uint64, m marker) {
+	t := &rb.tombs
+	t.mu.Lock()
+	t.init()
+	t.add(id, m)
+	t.count.Add(1)
+	t.mu.Unlock()
+}
+
+// add records marker id of kind m. t.mu must be held.
+func (t *tombstones) add(id uint64, m marker) {
+	t.dead[id] = m
+	if id < t.low.Load() {
+		t.low.Store(id)
+	}
+}
+
+// sweep drops the markers below floor, which every reader is past, and
+// returns how many it dropped. It only locks once floor passes the lowest
+// marker.
+func (t *tombstones) sweep(floor uint64) int {
+	if floor <= t.low.Load() {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	dropped := 0
+	low := uint64(math.MaxUint64)
+	for id := range t.dead {
+		if id < floor {
+			delete(t.dead, id)
+			dropped++
+		} else if id < low {
+			low = id
+		}
+	}
+	t.low.Store(low)
+	t.count.Add(-int32(dropped))
+	return dropped
+}
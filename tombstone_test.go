@@ -0,0 +1,51 @@
+package ringbuffer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCompactTombstones(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(4, WithTimeIndex(1, 8))
+	start := time.Now()
+	id, _ := rb.ReserveWrite(0)
+	rb.AbortWrite(0, id)
+	live, _ := rb.ReserveWrite(0)
+	rb.CommitWrite(0, live)
+	if n := rb.Stats().Tombstones; n != 1 {
+		t.Fatalf("Stats.Tombstones %d, want 1", n)
+	}
+	if n := rb.CompactTombstones(); n != 0 {
+		t.Fatalf("compacted %d markers readers will still reach", n)
+	}
+	if got := rb.SeekToTime(start); got != live {
+		t.Fatalf("SeekToTime %d, want %d past the tombstone", got, live)
+	}
+	r, _ := rb.ReserveRead(1)
+	if r != live {
+		t.Fatalf("read %d, want %d", r, live)
+	}
+	rb.CommitRead(1, r)
+	if n := rb.Stats().Tombstones; n != 0 {
+		t.Fatalf("Stats.Tombstones %d after the reader skipped it", n)
+	}
+}
+
+// TestCompactTombstonesClosed checks a ring closed without drain drops the
+// markers no reader will reach.
+func TestCompactTombstonesClosed(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(4)
+	for i := 0; i < 2; i++ {
+		id, _ := rb.ReserveWrite(0)
+		rb.AbortWrite(0, id)
+	}
+	rb.Close()
+	if n := rb.CompactTombstones(); n != 2 {
+		t.Fatalf("compacted %d, want 2", n)
+	}
+	if n := rb.Tombstones(); n != 0 {
+		t.Fatalf("%d markers left", n)
+	}
+}
@@ -0,0 +1,53 @@
+package ringbuffer
+
+import "sync"
+
+// TopicMux multiplexes several low-volume streams over one ring: writers
+// set SlotMeta.Topic, and the mux hands every item to each subscriber of
+// its topic. Items of a topic nobody subscribed to are consumed and
+// dropped. The ring must be created WithSlotMeta.
+// Its dispatchers compete for items; on a ring created WithBroadcast, use
+// Subscriber.Filter instead.
+type TopicMux struct {
+	rb   *RingBuffer
+	mu   sync.RWMutex
+	subs map[uint16][]func(id uint64, meta *SlotMeta)
+}
+
+// NewTopicMux returns a TopicMux reading rb.
+func NewTopicMux(rb *RingBuffer) *TopicMux {
+	if rb.meta == nil {
+		panic("RingBuffer: TopicMux needs a ring created WithSlotMeta")
+	}
+	return &TopicMux{rb: rb, subs: make(map[uint16][]func(uint64, *SlotMeta))}
+}
+
+// Subscribe calls fn with every item of topic, while the reader holds it:
+// fn reads the slot of id and must not keep it.
+// It is goroutine-safe.
+func (m *TopicMux) Subscribe(topic uint16, fn func(id uint64, meta *SlotMeta)) {
+	m.mu.Lock()
+	m.subs[topic] = append(m.subs[topic], fn)
+	m.mu.Unlock()
+}
+
+// Dispatch waits for the next item, hands it to the subscribers of its
+// topic and commits it. It returns the number of subscribers called, and
+// ErrClosed once the ring is closed.
+// Several goroutines may dispatch, with distinct wids.
+// It is goroutine-safe.
+func (m *TopicMux) Dispatch(wid int) (int, error) {
+	id, err := m.rb.ReserveRead(wid)
+	if err != nil {
+		return 0, err
+	}
+	meta := m.rb.Meta(id)
+	m.mu.RLock()
+	subs := m.subs[meta.Topic]
+	m.mu.RUnlock()
+	for _, fn := range subs {
+		fn(id, meta)
+	}
+	m.rb.CommitRead(wid, id)
+	return len(subs), nil
+}
@@ -0,0 +1,42 @@
+package ringbuffer
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTopicMux(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(8, WithSlotMeta())
+	vals := make([]int, rb.Slots())
+	m := NewTopicMux(rb)
+	var a, b, all []int
+	m.Subscribe(1, func(id uint64, meta *SlotMeta) { a = append(a, vals[rb.BufferIndex(id)]) })
+	m.Subscribe(2, func(id uint64, meta *SlotMeta) { b = append(b, vals[rb.BufferIndex(id)]) })
+	m.Subscribe(2, func(id uint64, meta *SlotMeta) { all = append(all, vals[rb.BufferIndex(id)]) })
+	for i, topic := range []uint16{1, 2, 3, 2} {
+		id, _ := rb.ReserveWrite(0)
+		vals[rb.BufferIndex(id)] = i
+		rb.Meta(id).Topic = topic
+		rb.CommitWrite(0, id)
+	}
+	for _, want := range []int{1, 2, 0, 2} {
+		n, err := m.Dispatch(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != want {
+			t.Fatalf("Dispatch called %d subscribers, want %d", n, want)
+		}
+	}
+	if len(a) != 1 || a[0] != 0 || len(b) != 2 || b[0] != 1 || b[1] != 3 || len(all) != 2 {
+		t.Fatalf("topic 1 got %v, topic 2 got %v and %v", a, b, all)
+	}
+	if rb.Len() != 0 {
+		t.Fatalf("%d items left, the unsubscribed topic was not consumed", rb.Len())
+	}
+	rb.Close()
+	if _, err := m.Dispatch(1); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Dispatch after Close: %v, want ErrClosed", err)
+	}
+}
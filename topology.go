@@ -0,0 +1,61 @@
+package ringbuffer
+
+// NewMPSCRingBuffer returns a ring for many producers and a single consumer
+// goroutine, e.g. a logger fed by every request handler. It is a RingBuffer
+// created WithSingleReader: the consumer claims read ids with plain stores
+// instead of compare and swap loops, and is used the same way.
+func NewMPSCRingBuffer(size int, opts ...Option) (*RingBuffer, error) {
+	return NewRingBuffer(size, append(opts, WithSingleReader())...)
+}
+
+// NewSPMCRingBuffer returns a ring for a single producer goroutine and many
+// consumers, e.g. a dispatcher feeding a worker pool. It is a RingBuffer
+// created WithSingleWriter: the producer claims write ids with plain stores
+// instead of compare and swap loops, and is used the same way.
+func NewSPMCRingBuffer(size int, opts ...Option) (*RingBuffer, error) {
+	return NewRingBuffer(size, append(opts, WithSingleWriter())...)
+}
+
+// claimRead moves the read reserve from r, loaded by the caller, to r+n.
+// A single reader owns the cursor and stores it, other readers compare and
+// swap and retry if they lose.
+func (rb *RingBuffer) claimRead(r, n uint64) bool {
+	if rb.singleReader && !rb.overwrite { //overwriting writers claim reads too
+		rb.rReserve.Store(r + n)
+		return true
+	}
+	return rb.rReserve.CompareAndSwap(r, r+n)
+}
+
+// claimWrite moves the write reserve from w, loaded by the caller, to w+n.
+// A single writer owns the cursor and stores it, other writers compare and
+// swap and retry if they lose.
+func (rb *RingBuffer) claimWrite(w, n uint64) bool {
+	if rb.singleWriter {
+		rb.wReserve.Store(w + n)
+		return true
+	}
+	return rb.wReserve.CompareAndSwap(w, w+n)
+}
+
+// takeRead takes the next n read ids and returns the first. A single
+// reader stores the cursor, other readers add to it.
+func (rb *RingBuffer) takeRead(n uint64) uint64 {
+	if rb.singleReader && !rb.overwrite {
+		r := rb.rReserve.Load()
+		rb.rReserve.Store(r + n)
+		return r
+	}
+	return rb.rReserve.Add(n) - n
+}
+
+// takeWrite takes the next n write ids and returns the first. A single
+// writer stores the cursor, other writers add to it.
+func (rb *RingBuffer) takeWrite(n uint64) uint64 {
+	if rb.singleWriter {
+		w := rb.wReserve.Load()
+		rb.wReserve.Store(w + n)
+		return w
+	}
+	return rb.wReserve.Add(n) - n
+}
@@ -0,0 +1,133 @@
+package ringbuffer
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestMPSC runs many producers into one consumer, with single and batch
+// reads, and checks per producer order.
+func TestMPSC(t *testing.T) {
+	defer parallel()()
+	const writers, n = 4, 3000
+	rb, err := NewMPSCRingBuffer(8, WithContractChecks())
+	if err != nil {
+		t.Fatal(err)
+	}
+	slots := make([]int, rb.Slots())
+	var wg sync.WaitGroup
+	wg.Add(writers)
+	for w := 0; w < writers; w++ {
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				id, _ := rb.ReserveWrite(w)
+				slots[rb.BufferIndex(id)] = w*n + i
+				rb.CommitWrite(w, id)
+			}
+		}(w)
+	}
+	next := make([]int, writers)
+	for count := 0; count < writers*n; {
+		lo, hi := uint64(0), uint64(0)
+		if count%2 == 0 {
+			lo, _ = rb.ReserveRead(0)
+			hi = lo
+		} else {
+			lo, hi, _ = rb.ReserveReadN(0, 4)
+		}
+		for id := lo; id <= hi; id++ {
+			v := slots[rb.BufferIndex(id)]
+			if w := v / n; v%n != next[w] {
+				t.Fatalf("writer %d: got item %d, want %d", w, v%n, next[w])
+			}
+			next[v/n]++
+			count++
+		}
+		rb.CommitReadN(0, lo, hi)
+	}
+	wg.Wait()
+}
+
+// TestSPMC runs one producer into many consumers and checks every item is
+// read exactly once.
+func TestSPMC(t *testing.T) {
+	defer parallel()()
+	const readers, n = 4, 12000
+	rb, err := NewSPMCRingBuffer(8, WithContractChecks())
+	if err != nil {
+		t.Fatal(err)
+	}
+	slots := make([]int, rb.Slots())
+	go func() {
+		for i := 0; i < n; i++ {
+			if i%3 == 0 {
+				lo, hi, _ := rb.ReserveWriteN(0, 2)
+				slots[rb.BufferIndex(lo)], slots[rb.BufferIndex(hi)] = i, i+1
+				rb.CommitWriteN(0, lo, hi)
+				i++
+				continue
+			}
+			id, _ := rb.ReserveWrite(0)
+			slots[rb.BufferIndex(id)] = i
+			rb.CommitWrite(0, id)
+		}
+	}()
+	seen := make([]int32, n)
+	var wg sync.WaitGroup
+	wg.Add(readers)
+	for r := 0; r < readers; r++ {
+		go func(r int) {
+			defer wg.Done()
+			for i := 0; i < n/readers; i++ {
+				id, _ := rb.ReserveRead(r)
+				seen[slots[rb.BufferIndex(id)]]++
+				rb.CommitRead(r, id)
+			}
+		}(r)
+	}
+	wg.Wait()
+	for v, c := range seen {
+		if c != 1 {
+			t.Fatalf("item %d read %d times", v, c)
+		}
+	}
+}
+
+func TestSingleSideContract(t *testing.T) {
+	defer parallel()()
+	rb, _ := NewMPSCRingBuffer(4, WithContractChecks())
+	id, _ := rb.ReserveWrite(0)
+	rb.CommitWrite(0, id)
+	rb.ReserveRead(0)
+	panicked := make(chan bool)
+	go func() {
+		defer func() { panicked <- recover() != nil }()
+		rb.ReserveRead(1)
+	}()
+	if !<-panicked {
+		t.Fatal("a second reader goroutine broke the single reader contract unnoticed")
+	}
+}
+
+// BenchmarkSingleOwner compares a producer and a consumer goroutine on a
+// default ring with the same pair on a ring declaring both sides single.
+func BenchmarkSingleOwner(b *testing.B) {
+	b.Run("mpmc", func(b *testing.B) { benchSingleOwner(b) })
+	b.Run("single", func(b *testing.B) { benchSingleOwner(b, WithSingleWriter(), WithSingleReader()) })
+}
+
+func benchSingleOwner(b *testing.B, opts ...Option) {
+	defer parallel()()
+	rb := MustNewRingBuffer(1024, opts...)
+	go func() {
+		for i := 0; i < b.N; i++ {
+			id, _ := rb.ReserveWrite(0)
+			rb.CommitWrite(0, id)
+		}
+	}()
+	for i := 0; i < b.N; i++ {
+		id, _ := rb.ReserveRead(0)
+		rb.CommitRead(0, id)
+	}
+}
@@ -0,0 +1,108 @@
+package ringbuffer
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// Coordinator publishes to several rings at once.
+// A transaction reserves one slot in every ring and commits them together:
+// readers of the rings don't see any side of a transaction before every
+// side is published, so a reader that sees one side knows the others are
+// visible too.
+// All writers of the coordinated rings must go through the Coordinator,
+// otherwise transactions may deadlock on each other's reservations. A ring
+// belongs to one Coordinator at most.
+type Coordinator struct {
+	mu    sync.Mutex
+	rings []*RingBuffer
+	hold  atomic.Pointer[Txn] // transaction being committed, hidden from readers
+}
+
+// Txn is a set of reserved slots, one per coordinated ring.
+type Txn struct {
+	c   *Coordinator
+	ids []uint64
+}
+
+// NewCoordinator returns a Coordinator for rings. It panics if a ring
+// already belongs to a Coordinator.
+func NewCoordinator(rings ...*RingBuffer) *Coordinator {
+	c := &Coordinator{rings: rings}
+	for i, rb := range rings {
+		if rb.coord != nil {
+			panic("RingBuffer: ring already belongs to a Coordinator")
+		}
+		rb.coord, rb.coordIndex = c, i
+	}
+	return c
+}
+
+// Reserve reserves a slot in every ring.
+// Reservations of concurrent transactions are serialized, so their ids keep
+// the same order in every ring.
+// It will wait if any ring is full. If any ring is closed, it aborts the
+// ids reserved in the others and returns ErrClosed, along with the errors
+// of the aborts.
+// It is goroutine-safe.
+func (c *Coordinator) Reserve(wid int) (*Txn, error) {
+	t := &Txn{c: c, ids: make([]uint64, len(c.rings))}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, rb := range c.rings {
+		id, err := rb.ReserveWrite(wid)
+		if err != nil {
+			errs := []error{err}
+			for j := 0; j < i; j++ {
+				errs = append(errs, c.rings[j].AbortWrite(wid, t.ids[j]))
+			}
+			return nil, errors.Join(errs...)
+		}
+		t.ids[i] = id
+	}
+	return t, nil
+}
+
+// ID returns the id reserved in the i-th ring.
+func (t *Txn) ID(i int) uint64 {
+	return t.ids[i]
+}
+
+// Commit commits the transaction in every ring.
+// It first waits until the transaction is the next to commit in all rings,
+// then commits every side while hiding them from readers, and reveals
+// them all at once. It returns the errors of the commits of every side.
+// It is goroutine-safe.
+func (t *Txn) Commit(wid int) error {
+	c := t.c
+	for i, rb := range c.rings {
+		rb.waitWriteTurn(t.ids[i])
+	}
+	c.hold.Store(t) //only one transaction has its turn in every ring
+	var errs []error
+	for i, rb := range c.rings {
+		if err := rb.CommitWrite(wid, t.ids[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	c.hold.CompareAndSwap(t, nil) //the next transaction may hold already
+	for _, rb := range c.rings {
+		rb.waitReadR.wake(rb.wCommit.Load())
+	}
+	return errors.Join(errs...)
+}
+
+// readLimit returns the first id readers may not read: the write commit,
+// or the side of a transaction being committed.
+// The write commit is loaded first, so a side it covers is either still
+// held or revealed together with the others.
+func (rb *RingBuffer) readLimit() uint64 {
+	w := rb.wCommit.Load()
+	if rb.coord != nil {
+		if t := rb.coord.hold.Load(); t != nil && t.ids[rb.coordIndex] < w {
+			return t.ids[rb.coordIndex]
+		}
+	}
+	return w
+}
@@ -0,0 +1,63 @@
+package ringbuffer
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestTxnAtomic checks that a reader of one side of a transaction always
+// finds the other side published.
+func TestTxnAtomic(t *testing.T) {
+	defer parallel()()
+	const writers, n = 4, 2000
+	a, b := MustNewRingBuffer(4), MustNewRingBuffer(8)
+	c := NewCoordinator(a, b)
+	peerOfA := make([]uint64, a.Slots()) //id of the b side of each a slot
+	peerOfB := make([]uint64, b.Slots())
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				txn, err := c.Reserve(w)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				peerOfA[a.BufferIndex(txn.ID(0))] = txn.ID(1)
+				peerOfB[b.BufferIndex(txn.ID(1))] = txn.ID(0)
+				if err := txn.Commit(w); err != nil {
+					t.Error(err)
+				}
+			}
+		}(w)
+	}
+	read := func(rb, peer *RingBuffer, peers []uint64) {
+		defer wg.Done()
+		for i := 0; i < writers*n; i++ {
+			id, _ := rb.ReserveRead(0)
+			if p := peers[rb.BufferIndex(id)]; !peer.Token(p).Published() {
+				t.Errorf("read id %d before its peer %d is published", id, p)
+			}
+			rb.CommitRead(0, id)
+		}
+	}
+	wg.Add(2)
+	go read(a, b, peerOfA)
+	go read(b, a, peerOfB)
+	wg.Wait()
+}
+
+func TestTxnReserveClosed(t *testing.T) {
+	defer parallel()()
+	a, b := MustNewRingBuffer(4), MustNewRingBuffer(4)
+	c := NewCoordinator(a, b)
+	b.Close()
+	if _, err := c.Reserve(0); err == nil {
+		t.Fatal("Reserve on a closed ring succeeded")
+	}
+	if s := a.Stats(); s.WriteCommit != s.WriteReserve {
+		t.Fatalf("aborted side left uncommitted: %+v", s)
+	}
+}
@@ -0,0 +1,191 @@
+package ringbuffer
+
+import (
+	"errors"
+	"time"
+	"unsafe"
+)
+
+// TypedRingBuffer is a RingBuffer that owns the slots of its items, so
+// callers get a *T instead of keeping a slice indexed by BufferIndex.
+// The id based RingBuffer stays available as its low-level layer.
+type TypedRingBuffer[T any] struct {
+	rb      *RingBuffer
+	slots   []T
+	layout  slotLayout // see WithSlotAlignment
+	release func(*T)   // nil unless WithReleaser
+}
+
+// NewTypedRingBuffer returns a typed ring of size slots. It fails where
+// NewRingBuffer does, and if size slots of T don't fit in memory.
+func NewTypedRingBuffer[T any](size int, opts ...Option) (*TypedRingBuffer[T], error) {
+	rb, err := NewRingBuffer(size, opts...)
+	if err != nil {
+		return nil, err
+	}
+	t := &TypedRingBuffer[T]{rb: rb}
+	if rb.releaser != nil {
+		release, ok := rb.releaser.(func(*T))
+		if !ok {
+			return nil, errors.New("RingBuffer: WithReleaser item type doesn't match the TypedRingBuffer")
+		}
+		t.release = release
+	}
+	elem := unsafe.Sizeof(*new(T))
+	var n int
+	t.layout, n = newSlotLayout(rb.slotGroup, rb.Slots(), elem)
+	if err := checkSlots(n, elem); err != nil {
+		return nil, err
+	}
+	t.slots = make([]T, n)
+	t.layout.align(unsafe.Pointer(&t.slots[0]), elem)
+	return t, nil
+}
+
+// MustNewTypedRingBuffer is NewTypedRingBuffer panicking on error.
+func MustNewTypedRingBuffer[T any](size int, opts ...Option) *TypedRingBuffer[T] {
+	t, err := NewTypedRingBuffer[T](size, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// WithReleaser makes a TypedRingBuffer of T call release on the previous
+// item of a slot when ReserveWrite is about to reuse it, so resources owned
+// by items (pooled buffers, file handles) are returned deterministically
+// instead of leaking until GC. CommitRead then leaves the item in place.
+// release must accept the zero T, left by an aborted write that didn't
+// fill its slot.
+func WithReleaser[T any](release func(*T)) Option {
+	return func(rb *RingBuffer) {
+		rb.releaser = release
+	}
+}
+
+// RingBuffer returns the underlying id based ring.
+func (t *TypedRingBuffer[T]) RingBuffer() *RingBuffer {
+	return t.rb
+}
+
+// Slot returns the slot of id. The caller must hold a reservation of id.
+func (t *TypedRingBuffer[T]) Slot(id uint64) *T {
+	return &t.slots[t.layout.index(t.rb.BufferIndex(id))]
+}
+
+// ReserveWrite reserves the next write id like RingBuffer.ReserveWrite and
+// returns its slot to fill before CommitWrite.
+// It is goroutine-safe.
+func (t *TypedRingBuffer[T]) ReserveWrite(wid int) (uint64, *T, error) {
+	id, err := t.rb.ReserveWrite(wid)
+	if err != nil {
+		return 0, nil, err
+	}
+	return id, t.reuse(id), nil
+}
+
+// reuse returns the slot of write id, releasing its earlier item under
+// WithReleaser.
+func (t *TypedRingBuffer[T]) reuse(id uint64) *T {
+	slot := t.Slot(id)
+	if t.release != nil && id >= uint64(t.rb.Slots()) { //slot held an earlier item
+		t.release(slot)
+		var zero T
+		*slot = zero
+	}
+	return slot
+}
+
+// CommitWrite publishes the slot of id, see RingBuffer.CommitWrite.
+// It is goroutine-safe.
+func (t *TypedRingBuffer[T]) CommitWrite(wid int, id uint64) error {
+	return t.rb.CommitWrite(wid, id)
+}
+
+// ReserveRead reserves the next read id like RingBuffer.ReserveRead and
+// returns its slot, valid until CommitRead.
+// It is goroutine-safe.
+func (t *TypedRingBuffer[T]) ReserveRead(wid int) (uint64, *T, error) {
+	id, err := t.rb.ReserveRead(wid)
+	if err != nil {
+		return 0, nil, err
+	}
+	return id, t.Slot(id), nil
+}
+
+// CommitRead zeroes the slot of id, so it keeps no references alive, and
+// releases it to writers, see RingBuffer.CommitRead. Under WithReleaser the
+// item stays until its slot is reused.
+// It is goroutine-safe.
+func (t *TypedRingBuffer[T]) CommitRead(wid int, id uint64) error {
+	if err := t.rb.checkCommit(id, &t.rb.rReserve, &t.rb.rCommit); err != nil {
+		return err
+	}
+	if t.release == nil {
+		var zero T
+		*t.Slot(id) = zero
+	}
+	return t.rb.CommitRead(wid, id)
+}
+
+// Put publishes v, waiting while the ring is full, like a channel send.
+// It returns ErrClosed once the ring is closed.
+// It is goroutine-safe.
+func (t *TypedRingBuffer[T]) Put(v T) error {
+	id, slot, err := t.ReserveWrite(-1)
+	if err != nil {
+		return err
+	}
+	*slot = v
+	return t.rb.CommitWrite(-1, id)
+}
+
+// Get waits for the oldest item and returns it, like a channel receive.
+// It returns ErrClosed once the ring is closed, see WithDrainOnClose.
+// It is goroutine-safe.
+func (t *TypedRingBuffer[T]) Get() (T, error) {
+	id, slot, err := t.ReserveRead(-1)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	v := *slot
+	return v, t.CommitRead(-1, id)
+}
+
+// TryPut publishes v like Put if a slot is free, and reports whether it
+// did. It fails when the ring is full or closed.
+// It is goroutine-safe.
+func (t *TypedRingBuffer[T]) TryPut(v T) bool {
+	id, ok := t.rb.TryReserveWrite(-1)
+	if !ok {
+		return false
+	}
+	*t.reuse(id) = v
+	t.rb.CommitWrite(-1, id)
+	return true
+}
+
+// PutOr publishes v like Put, but waits for a slot d at most: then, or at
+// once if the ring refuses it with ErrFull, v goes to fallback instead, e.g.
+// to write it to disk or drop it with a metric. Fallbacks are counted in
+// Stats.Fallbacks. It returns ErrClosed once the ring is closed, without
+// calling fallback. With d of 0 or less, v is put if a slot is free and
+// goes to fallback otherwise.
+// It is goroutine-safe.
+func (t *TypedRingBuffer[T]) PutOr(v T, d time.Duration, fallback func(v T)) error {
+	if t.TryPut(v) {
+		return nil
+	}
+	id, err := t.rb.ReserveWriteTimeout(-1, d)
+	if err == ErrTimeout || err == ErrFull {
+		t.rb.fallbacks.Add(1)
+		fallback(v)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	*t.reuse(id) = v
+	return t.rb.CommitWrite(-1, id)
+}
@@ -0,0 +1,78 @@
+package ringbuffer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPutOrZeroWait(t *testing.T) {
+	defer parallel()()
+	r := MustNewTypedRingBuffer[int](2)
+	var dropped []int
+	fallback := func(v int) { dropped = append(dropped, v) }
+	for i := 0; i < 3; i++ {
+		if err := r.PutOr(i, 0, fallback); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(dropped) != 1 || dropped[0] != 2 {
+		t.Fatalf("dropped %v, want [2]", dropped)
+	}
+	if got := r.RingBuffer().Stats().Fallbacks; got != 1 {
+		t.Fatalf("%d fallbacks counted", got)
+	}
+	for want := 0; want < 2; want++ {
+		if v, _ := r.Get(); v != want {
+			t.Fatalf("got %d, want %d", v, want)
+		}
+	}
+}
+
+func TestPutOrWaits(t *testing.T) {
+	defer parallel()()
+	r := MustNewTypedRingBuffer[int](1)
+	r.Put(0)
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		r.Get()
+	}()
+	if err := r.PutOr(1, time.Second, func(int) { t.Error("fell back while a slot was freed") }); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestWithReleaser checks the item of a slot is released when a write
+// reuses the slot, and not before.
+func TestWithReleaser(t *testing.T) {
+	defer parallel()()
+	type item struct{ n int }
+	var released []int
+	r := MustNewTypedRingBuffer[item](2, WithReleaser(func(it *item) {
+		released = append(released, it.n)
+	}))
+	slots := r.RingBuffer().Slots()
+	for round := 0; round < 2; round++ {
+		for i := 0; i < slots; i++ {
+			n := round*slots + i + 1
+			if err := r.Put(item{n}); err != nil {
+				t.Fatal(err)
+			}
+			got, err := r.Get()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.n != n {
+				t.Fatalf("Get %d, want %d", got.n, n)
+			}
+		}
+		if round == 0 && len(released) != 0 {
+			t.Fatalf("released %v before any slot was reused", released)
+		}
+	}
+	if len(released) != slots || released[0] != 1 || released[slots-1] != slots {
+		t.Fatalf("released %v, want the first %d items in order", released, slots)
+	}
+	if it := r.Slot(uint64(2*slots - 1)); it.n != 2*slots {
+		t.Fatalf("slot holds %d after CommitRead, want the item kept for its release", it.n)
+	}
+}
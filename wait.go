@@ -0,0 +1,191 @@
+package ringbuffer
+
+import (
+	"context"
+	"math"
+	"sync"
+	"sync/atomic"
+)
+
+// waitList is a FIFO queue of goroutines parked until a cursor reaches the
+// sequence they need.
+// Wakeups are targeted: wake only wakes the waiters whose need the cursor
+// reached, oldest first, instead of broadcasting to all of them, so a
+// commit freeing one slot doesn't wake every parked writer to have all but
+// one park again. wake also does nothing unless somebody is parked and the
+// cursor has moved past the lowest need, so bursts of commits that satisfy
+// nobody don't take the lock.
+type waitList struct {
+	mu      sync.Mutex
+	queue   []*waiter     // parked goroutines, oldest first
+	waiters atomic.Int32  // number of parked goroutines
+	need    atomic.Uint64 // lowest cursor value a parked goroutine is waiting for
+}
+
+// waiter is a parked goroutine, woken by a send on ready.
+type waiter struct {
+	need  uint64
+	ready chan struct{}
+}
+
+var waiterPool = sync.Pool{New: func() any { return &waiter{ready: make(chan struct{}, 1)} }}
+
+func newWaitList() *waitList {
+	w := &waitList{}
+	w.need.Store(math.MaxUint64)
+	return w
+}
+
+// wait parks the caller until a wake satisfies need, unless ready already
+// reports true.
+// The waiter registers itself before checking ready, so a concurrent wake
+// either sees it registered or has already made ready true.
+func (w *waitList) wait(need uint64, ready func() bool) {
+	wt := waiterPool.Get().(*waiter)
+	wt.need = need
+	w.mu.Lock()
+	w.queue = append(w.queue, wt)
+	w.waiters.Add(1)
+	if need < w.need.Load() {
+		w.need.Store(need)
+	}
+	if ready() {
+		w.remove(wt)
+		w.mu.Unlock()
+	} else {
+		w.mu.Unlock()
+		<-wt.ready //wake removed wt from the queue
+	}
+	waiterPool.Put(wt)
+}
+
+// remove drops wt from the queue. w.mu must be held.
+func (w *waitList) remove(wt *waiter) {
+	for i, q := range w.queue {
+		if q == wt {
+			copy(w.queue[i:], w.queue[i+1:])
+			w.queue[len(w.queue)-1] = nil
+			w.queue = w.queue[:len(w.queue)-1]
+			w.waiters.Add(-1)
+			return
+		}
+	}
+}
+
+// wakeAll wakes every parked goroutine.
+func (w *waitList) wakeAll() {
+	w.mu.Lock()
+	w.wakeLocked(math.MaxUint64)
+	w.mu.Unlock()
+}
+
+// wake wakes the parked goroutines whose need cursor satisfies.
+// Woken goroutines that are still not ready register again.
+func (w *waitList) wake(cursor uint64) {
+	if w.waiters.Load() == 0 {
+		return
+	}
+	if cursor < w.need.Load() {
+		return
+	}
+	w.mu.Lock()
+	w.wakeLocked(cursor)
+	w.mu.Unlock()
+}
+
+// wakeLocked wakes the waiters cursor satisfies, keeping the others in
+// order, and lowers need to theirs. w.mu must be held.
+func (w *waitList) wakeLocked(cursor uint64) {
+	keep := w.queue[:0]
+	need := uint64(math.MaxUint64)
+	for _, wt := range w.queue {
+		if wt.need <= cursor {
+			w.waiters.Add(-1)
+			wt.ready <- struct{}{}
+			continue
+		}
+		keep = append(keep, wt)
+		if wt.need < need {
+			need = wt.need
+		}
+	}
+	for i := len(keep); i < len(w.queue); i++ {
+		w.queue[i] = nil
+	}
+	w.queue = keep
+	w.need.Store(need)
+}
+
+// WaitStrategy decides what a goroutine does while the cursor it needs
+// hasn't moved yet.
+type WaitStrategy interface {
+	// Spin is called before parking, try counts the checks done so far.
+	// It returns true if the caller should check again instead of parking.
+	// Strategies that give up the processor call yield, see WithYield.
+	// ctx is the context of the waiting call, context.Background() for
+	// calls without one. Once it is done Spin should return false without
+	// delay, so that the caller sees the cancellation: a strategy that
+	// sleeps or backs off inside Spin selects on ctx.Done().
+	Spin(ctx context.Context, try int, yield func()) bool
+}
+
+// BlockingWaitStrategy parks at once. It is the default strategy and costs
+// no CPU while waiting.
+type BlockingWaitStrategy struct{}
+
+// Spin implements WaitStrategy.
+func (BlockingWaitStrategy) Spin(ctx context.Context, try int, yield func()) bool {
+	return false
+}
+
+// YieldingWaitStrategy yields the processor up to Spins times before parking.
+type YieldingWaitStrategy struct {
+	Spins int
+}
+
+// Spin implements WaitStrategy.
+func (s YieldingWaitStrategy) Spin(ctx context.Context, try int, yield func()) bool {
+	if try > s.Spins || ctx.Err() != nil {
+		return false
+	}
+	yield()
+	return true
+}
+
+// BusySpinWaitStrategy never parks until the waiting call is cancelled. It
+// gives the lowest latency and burns a core per waiting goroutine.
+type BusySpinWaitStrategy struct{}
+
+// Spin implements WaitStrategy.
+func (BusySpinWaitStrategy) Spin(ctx context.Context, try int, yield func()) bool {
+	return ctx.Err() == nil
+}
+
+// strategyBox holds a WaitStrategy in an atomic.Value, which needs a single
+// concrete type.
+type strategyBox struct {
+	ws WaitStrategy
+}
+
+// strategy returns the current wait strategy.
+func (rb *RingBuffer) strategy() WaitStrategy {
+	return rb.waitStrategy.Load().(strategyBox).ws
+}
+
+// WaitStrategy returns the current wait strategy.
+// It is goroutine-safe.
+func (rb *RingBuffer) WaitStrategy() WaitStrategy {
+	return rb.strategy()
+}
+
+// SetWaitStrategy swaps the wait strategy of a live ring, e.g. to busy-spin
+// during a latency critical window and back to blocking after it.
+// Spinning goroutines pick the new strategy up at their next check; parked
+// goroutines are woken so that they do too.
+// It is goroutine-safe.
+func (rb *RingBuffer) SetWaitStrategy(ws WaitStrategy) {
+	rb.waitStrategy.Store(strategyBox{ws})
+	for _, w := range []*waitList{rb.waitReadR, rb.waitWriteR, rb.waitReadC, rb.waitWriteC} {
+		w.wakeAll()
+	}
+}
@@ -0,0 +1,152 @@
+package ringbuffer
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// wakeupCounter counts the waits and wakeups of a ring; wakeups in excess of
+// waits found their condition still false.
+type wakeupCounter struct {
+	NopObserver
+	waits, wakeups atomic.Int64
+}
+
+func (c *wakeupCounter) OnReserveWait(op string, wid int, waited time.Duration) {
+	c.waits.Add(1)
+}
+
+func (c *wakeupCounter) OnWakeup(op string, wid int) {
+	c.wakeups.Add(1)
+}
+
+// BenchmarkWakeups runs many parked writers and readers on a small ring and
+// reports the wakeups per item and the spurious ones, that had to park
+// again.
+func BenchmarkWakeups(b *testing.B) {
+	defer parallel()()
+	const writers, readers = 8, 8
+	c := &wakeupCounter{}
+	rb := MustNewRingBuffer(4, WithObserver(c))
+	n := b.N/writers + 1
+	var wg sync.WaitGroup
+	wg.Add(writers + readers)
+	b.ResetTimer()
+	for w := 0; w < writers; w++ {
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				id, _ := rb.ReserveWrite(w)
+				rb.CommitWrite(w, id)
+			}
+		}(w)
+	}
+	for r := 0; r < readers; r++ {
+		go func(r int) {
+			defer wg.Done()
+			for i := 0; i < n*writers/readers; i++ {
+				id, _ := rb.ReserveRead(r)
+				rb.CommitRead(r, id)
+			}
+		}(r)
+	}
+	wg.Wait()
+	b.StopTimer()
+	items := float64(n * writers)
+	wakeups, waits := c.wakeups.Load(), c.waits.Load()
+	b.ReportMetric(float64(wakeups)/items, "wakeups/op")
+	b.ReportMetric(float64(wakeups-waits)/items, "spurious/op")
+}
+
+func TestIdleWaitStrategy(t *testing.T) {
+	defer parallel()()
+	const period = 20 * time.Millisecond
+	rb := MustNewRingBuffer(4, WithWaitStrategy(BusySpinWaitStrategy{}),
+		WithIdleWaitStrategy(period, BlockingWaitStrategy{}))
+	if _, ok := rb.readStrategy().(BusySpinWaitStrategy); !ok {
+		t.Fatal("idle right after construction")
+	}
+	time.Sleep(2 * period)
+	if _, ok := rb.readStrategy().(BlockingWaitStrategy); !ok {
+		t.Fatal("not idle after a quiet period")
+	}
+	id, _ := rb.ReserveWrite(0)
+	rb.CommitWrite(0, id)
+	if _, ok := rb.readStrategy().(BusySpinWaitStrategy); !ok {
+		t.Fatal("still idle after a write")
+	}
+
+	if _, err := NewRingBuffer(4, WithIdleWaitStrategy(period, nil)); err == nil {
+		t.Fatal("nil idle strategy accepted")
+	}
+}
+
+// spinCounter is a WaitStrategy that parks at once, counting its calls.
+type spinCounter struct {
+	calls *atomic.Int64
+}
+
+func (s spinCounter) Spin(ctx context.Context, try int, yield func()) bool {
+	s.calls.Add(1)
+	return false
+}
+
+// TestSetWaitStrategy checks a parked reader is woken to pick up a new
+// strategy.
+func TestSetWaitStrategy(t *testing.T) {
+	defer parallel()()
+	rb := MustNewRingBuffer(4)
+	done := make(chan error)
+	go func() {
+		id, err := rb.ReserveRead(1)
+		if err == nil {
+			err = rb.CommitRead(1, id)
+		}
+		done <- err
+	}()
+	time.Sleep(20 * time.Millisecond) //the reader parks
+	calls := new(atomic.Int64)
+	rb.SetWaitStrategy(spinCounter{calls})
+	if _, ok := rb.WaitStrategy().(spinCounter); !ok {
+		t.Fatalf("WaitStrategy %#v after SetWaitStrategy", rb.WaitStrategy())
+	}
+	for deadline := time.Now().Add(time.Second); calls.Load() == 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("the parked reader did not pick up the new strategy")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	id, _ := rb.ReserveWrite(0)
+	rb.CommitWrite(0, id)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestWithYield checks spinning waits give up the processor through the
+// WithYield hook.
+func TestWithYield(t *testing.T) {
+	defer parallel()()
+	const spins = 5
+	var yields atomic.Int64
+	rb := MustNewRingBuffer(2, WithWaitStrategy(YieldingWaitStrategy{Spins: spins}),
+		WithYield(func() { yields.Add(1) }))
+	done := make(chan struct{})
+	go func() {
+		id, _ := rb.ReserveRead(1)
+		rb.CommitRead(1, id)
+		close(done)
+	}()
+	for deadline := time.Now().Add(time.Second); yields.Load() < spins; {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d yields, want %d before parking", yields.Load(), spins)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	id, _ := rb.ReserveWrite(0)
+	rb.CommitWrite(0, id)
+	<-done
+}
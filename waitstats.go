@@ -0,0 +1,69 @@
+package ringbuffer
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// WaitStats are the waits of a phase: calls that could not go on at once,
+// and the time they waited in total.
+type WaitStats struct {
+	Count uint64
+	Time  time.Duration
+}
+
+type waitCounter struct {
+	count atomic.Uint64
+	nanos atomic.Int64
+}
+
+func (c *waitCounter) load() WaitStats {
+	return WaitStats{Count: c.count.Load(), Time: time.Duration(c.nanos.Load())}
+}
+
+// waitCounters count the waits of the phases that may wait.
+type waitCounters struct {
+	reserveWrite waitCounter
+	reserveRead  waitCounter
+	commitRead   waitCounter
+}
+
+// phase returns the counter of op, nil if op doesn't wait.
+func (w *waitCounters) phase(op string) *waitCounter {
+	switch op {
+	case OpReserveWrite, OpReserveWriteN:
+		return &w.reserveWrite
+	case OpReserveRead, OpReserveReadN:
+		return &w.reserveRead
+	case OpCommitRead, OpCommitReadN:
+		return &w.commitRead
+	}
+	return nil
+}
+
+// waitBegin returns the start of a wait, or start if it already started.
+func (rb *RingBuffer) waitBegin(start time.Time) time.Time {
+	if start.IsZero() {
+		return time.Now()
+	}
+	return start
+}
+
+// waitEnd accounts a wait of op by wid that started at start, if any, in
+// Stats and against the WithSLO threshold.
+func (rb *RingBuffer) waitEnd(op string, wid int, start time.Time) {
+	if start.IsZero() {
+		return
+	}
+	waited := time.Since(start)
+	if c := rb.waits.phase(op); c != nil {
+		c.count.Add(1)
+		c.nanos.Add(int64(waited))
+	}
+	if op != OpCommitRead && op != OpCommitReadN {
+		rb.sloDone(op, wid, waited)
+	}
+	if rb.observer != nil {
+		rb.observer.OnReserveWait(op, wid, waited)
+	}
+}
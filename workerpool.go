@@ -0,0 +1,92 @@
+package ringbuffer
+
+import (
+	"context"
+	"sync"
+)
+
+// WorkHandler handles item id. item is valid until the handler returns.
+type WorkHandler[T any] func(id uint64, item *T)
+
+// WorkerPool hands each item of a ring to exactly one of its worker
+// goroutines, like the worker pool of the Disruptor: work queue semantics
+// with the ids coordinated by the ring, the workers being its competing
+// readers. A ring created WithRelaxedReadCommit keeps a slow item from
+// holding up the commits of the other workers.
+type WorkerPool[T any] struct {
+	ring    *TypedRingBuffer[T]
+	n       int
+	handler WorkHandler[T]
+
+	mu     sync.Mutex
+	cancel context.CancelFunc // nil until Start
+	wg     sync.WaitGroup
+	done   chan struct{} // closed once every worker returned
+}
+
+// NewWorkerPool returns a pool of n workers, at least one, calling handler
+// with the items of ring. Worker i reads as wid i. It does nothing before
+// Start.
+func NewWorkerPool[T any](ring *TypedRingBuffer[T], n int, handler WorkHandler[T]) *WorkerPool[T] {
+	if n < 1 {
+		n = 1
+	}
+	return &WorkerPool[T]{ring: ring, n: n, handler: handler, done: make(chan struct{})}
+}
+
+// Start runs the workers, until Halt or the ring is closed. A pool starts
+// once, later calls return ErrRunning.
+// It is goroutine-safe.
+func (p *WorkerPool[T]) Start() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cancel != nil {
+		return ErrRunning
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	p.wg.Add(p.n)
+	for i := 0; i < p.n; i++ {
+		wid := i
+		p.ring.rb.Go("worker", wid, func() {
+			defer p.wg.Done()
+			p.work(ctx, wid)
+		})
+	}
+	go func() {
+		p.wg.Wait()
+		close(p.done)
+	}()
+	return nil
+}
+
+// Halt stops the workers after the items in hand, and waits for them.
+// It is goroutine-safe.
+func (p *WorkerPool[T]) Halt() {
+	p.mu.Lock()
+	cancel := p.cancel
+	p.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-p.done
+}
+
+// Done returns a channel closed once every worker returned, after Halt or
+// once the ring is closed.
+func (p *WorkerPool[T]) Done() <-chan struct{} {
+	return p.done
+}
+
+func (p *WorkerPool[T]) work(ctx context.Context, wid int) {
+	rb := p.ring.rb
+	for {
+		id, err := rb.ReserveReadContext(ctx, wid)
+		if err != nil {
+			return
+		}
+		p.handler(id, p.ring.Slot(id))
+		p.ring.CommitRead(wid, id)
+	}
+}
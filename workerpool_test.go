@@ -0,0 +1,74 @@
+package ringbuffer
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestWorkerPool checks each item goes to exactly one worker, with and
+// without relaxed read commits, and that Close stops the workers once the
+// ring is drained.
+func TestWorkerPool(t *testing.T) {
+	defer parallel()()
+	for _, o := range orderings {
+		const workers, n = 4, 4000
+		ring := MustNewTypedRingBuffer[int](8, WithOrdering(o), WithDrainOnClose())
+		seen := make([]atomic.Int32, n)
+		p := NewWorkerPool(ring, workers, func(id uint64, v *int) {
+			seen[*v].Add(1)
+			if *v%500 == 0 {
+				time.Sleep(time.Millisecond) //a slow item
+			}
+		})
+		if err := p.Start(); err != nil {
+			t.Fatal(err)
+		}
+		if err := p.Start(); !errors.Is(err, ErrRunning) {
+			t.Fatalf("%v: second Start: %v", o, err)
+		}
+		for i := 0; i < n; i++ {
+			ring.Put(i)
+		}
+		ring.RingBuffer().Close()
+		<-p.Done()
+		for v := range seen {
+			if c := seen[v].Load(); c != 1 {
+				t.Fatalf("%v: item %d handled %d times", o, v, c)
+			}
+		}
+	}
+}
+
+// TestWorkerPoolHalt pins that Halt returns once the items in hand are
+// handled and committed.
+func TestWorkerPoolHalt(t *testing.T) {
+	defer parallel()()
+	ring := MustNewTypedRingBuffer[int](4)
+	started, release := make(chan struct{}), make(chan struct{})
+	var handled atomic.Int32
+	p := NewWorkerPool(ring, 2, func(id uint64, v *int) {
+		close(started)
+		<-release
+		handled.Add(1)
+	})
+	p.Start()
+	ring.Put(1)
+	<-started
+	halted := make(chan struct{})
+	go func() {
+		p.Halt()
+		close(halted)
+	}()
+	select {
+	case <-halted:
+		t.Fatal("Halt returned with an item in hand")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	<-halted
+	if handled.Load() != 1 || ring.RingBuffer().Stats().ReadCommit != 1 {
+		t.Fatalf("handled %d, read commit %d after Halt", handled.Load(), ring.RingBuffer().Stats().ReadCommit)
+	}
+}